@@ -0,0 +1,270 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"studious-waffle/server"
+	"studious-waffle/server/protodata"
+
+	"github.com/MobilityData/gtfs-realtime-bindings/golang/gtfs"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/encoding/prototext"
+	"google.golang.org/protobuf/proto"
+)
+
+// configFlag adds the -config flag, naming the JSON config file settings
+// are read from before the environment.
+func configFlag(flags *flag.FlagSet) *string {
+	return flags.String("config", os.Getenv("CONFIG_FILE"), "JSON config file (CONFIG_FILE)")
+}
+
+// ingest validates the feed at input, a directory or zip, and generates the
+// compiled data from it.
+func ingest(input string) error {
+	cleanup, err := protodata.UseInput(input)
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+	return server.Seed()
+}
+
+// feedArg overrides the configured input with a command's FEED argument,
+// when it was given one.
+func feedArg(flags *flag.FlagSet) map[string]string {
+	given := make(map[string]string)
+	if flags.NArg() > 0 {
+		given["data.input"] = flags.Arg(0)
+	}
+	return given
+}
+
+func printJSON(v any) error {
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
+
+func serveCommand(args []string) error {
+	flags := newFlags("serve", "serve [-config FILE] [-port PORT] [-seed] [-SETTING VALUE ...]\n\nEach setting is read from its default, the config file, the environment and\nthen its flag, each overriding the last.")
+	configFile := configFlag(flags)
+	given := server.ConfigFlags(flags)
+	flags.Func("port", "shorthand for -server.port", func(v string) error {
+		given["server.port"] = v
+		return nil
+	})
+	flags.BoolFunc("seed", "shorthand for -server.seed", func(v string) error {
+		given["server.seed"] = v
+		return nil
+	})
+	if err := parseFlags(flags, args, 0, 0); err != nil {
+		return err
+	}
+
+	cfg, err := server.LoadConfig(*configFile, given)
+	if err != nil {
+		return err
+	}
+	server.Configure(cfg)
+	if cfg.Server.Seed {
+		if err := ingest(cfg.Data.Input); err != nil {
+			return err
+		}
+	}
+	return server.Serve()
+}
+
+func ingestCommand(args []string) error {
+	flags := newFlags("ingest", "ingest [-config FILE] [FEED]\n\nFEED is a directory or zip of GTFS text files, the configured input by default.\nThe server has to be rebuilt to serve the generated data.")
+	configFile := configFlag(flags)
+	if err := parseFlags(flags, args, 0, 1); err != nil {
+		return err
+	}
+	cfg, err := server.LoadConfig(*configFile, feedArg(flags))
+	if err != nil {
+		return err
+	}
+	server.Configure(cfg)
+	return ingest(cfg.Data.Input)
+}
+
+var severityRanks = map[string]int{protodata.NoticeError: 0, protodata.NoticeWarning: 1, protodata.NoticeInfo: 2}
+
+func validateCommand(args []string) error {
+	flags := newFlags("validate", "validate [-config FILE] [-json] [-severity LEVEL] [FEED]\n\nFEED is a directory or zip of GTFS text files, the configured input by default.")
+	configFile := configFlag(flags)
+	asJSON := flags.Bool("json", false, "print the full report as JSON")
+	severity := flags.String("severity", protodata.NoticeWarning, "least severe notices to list: error, warning or info")
+	if err := parseFlags(flags, args, 0, 1); err != nil {
+		return err
+	}
+	rank, found := severityRanks[*severity]
+	if !found {
+		fmt.Fprintln(flags.Output(), "-severity must be error, warning or info")
+		return errUsage
+	}
+	cfg, err := server.LoadConfig(*configFile, feedArg(flags))
+	if err != nil {
+		return err
+	}
+	cleanup, err := protodata.UseInput(cfg.Data.Input)
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+
+	report := protodata.ValidateFeed()
+	if *asJSON {
+		if err := printJSON(report); err != nil {
+			return err
+		}
+	} else {
+		for _, n := range report.Notices {
+			if severityRanks[n.Severity] > rank {
+				continue
+			}
+			where := n.File
+			if n.Line > 0 {
+				where = fmt.Sprintf("%s:%d", n.File, n.Line)
+			}
+			fmt.Printf("%s %s %s: %s\n", n.Severity, where, n.Code, n.Message)
+		}
+		if len(report.Counts) > 0 {
+			fmt.Println()
+		}
+		for _, c := range report.Counts {
+			fmt.Printf("%-8s %-36s %d\n", c.Severity, c.Code, c.Count)
+		}
+		fmt.Printf("\n%d error(s), %d warning(s), %d info notice(s)\n", report.Errors, report.Warnings, report.Infos)
+	}
+	if report.Errors > 0 {
+		return fmt.Errorf("feed has %d validation error(s)", report.Errors)
+	}
+	return nil
+}
+
+// diffCommand compares two static feeds, each a directory or zip of GTFS
+// text files, and prints what changed. Both feeds are given as arguments and
+// nothing else is configurable, so it takes no -config.
+func diffCommand(args []string) error {
+	flags := newFlags("diff", "diff [-json] OLD_FEED NEW_FEED\n\nBoth feeds are given as arguments, so no config file is read.")
+	asJSON := flags.Bool("json", false, "print the diff as JSON instead of a summary")
+	if err := parseFlags(flags, args, 2, 2); err != nil {
+		return err
+	}
+
+	from, err := protodata.ReadFeed(flags.Arg(0))
+	if err != nil {
+		return fmt.Errorf("reading %s: %w", flags.Arg(0), err)
+	}
+	to, err := protodata.ReadFeed(flags.Arg(1))
+	if err != nil {
+		return fmt.Errorf("reading %s: %w", flags.Arg(1), err)
+	}
+
+	diff := protodata.DiffFeeds(from, to)
+	if *asJSON {
+		return printJSON(diff)
+	}
+	fmt.Print(diff.Summary())
+	return nil
+}
+
+func rtCommand(args []string) error {
+	if len(args) == 0 || args[0] != "dump" {
+		fmt.Fprintf(os.Stderr, "usage: %s rt dump [-json] SNAPSHOT.pb\n", programName)
+		return errUsage
+	}
+	flags := newFlags("rt dump", "rt dump [-json] SNAPSHOT.pb")
+	asJSON := flags.Bool("json", false, "print the feed as JSON instead of protobuf text format")
+	if err := parseFlags(flags, args[1:], 1, 1); err != nil {
+		return err
+	}
+
+	data, err := os.ReadFile(flags.Arg(0))
+	if err != nil {
+		return err
+	}
+	feed := &gtfs.FeedMessage{}
+	if err := proto.Unmarshal(data, feed); err != nil {
+		return fmt.Errorf("%s is not a GTFS-realtime feed: %w", flags.Arg(0), err)
+	}
+	if *asJSON {
+		out, err := protojson.MarshalOptions{Multiline: true, Indent: "  "}.Marshal(feed)
+		if err != nil {
+			return err
+		}
+		fmt.Println(string(out))
+		return nil
+	}
+	fmt.Print(prototext.Format(feed))
+	return nil
+}
+
+// queryCommand looks an ID up in the data compiled into the binary, which no
+// setting changes, so it takes no -config.
+func queryCommand(args []string) error {
+	flags := newFlags("query", "query [-json] stop|route|trip ID\n\nThe data compiled into the binary is searched, so no config file is read.")
+	asJSON := flags.Bool("json", false, "print the result as JSON")
+	if err := parseFlags(flags, args, 2, 2); err != nil {
+		return err
+	}
+	kind, id := flags.Arg(0), flags.Arg(1)
+
+	var result any
+	found := false
+	switch kind {
+	case "stop":
+		result, found = server.LookupStop(id)
+	case "route":
+		result, found = server.LookupRoute(id)
+	case "trip":
+		result, found = server.LookupTrip(id)
+	default:
+		flags.Usage()
+		return errUsage
+	}
+	if !found {
+		return fmt.Errorf("%s %s not found", kind, id)
+	}
+	if *asJSON {
+		return printJSON(result)
+	}
+
+	switch r := result.(type) {
+	case *server.StopDetail:
+		s := r.Stop
+		fmt.Printf("Stop %s %s\n", s.GetStopId(), s.GetStopName())
+		if s.GetStopDesc() != "" {
+			fmt.Printf("  %s\n", s.GetStopDesc())
+		}
+		fmt.Printf("  location  %.6f,%.6f\n", s.GetStopLat(), s.GetStopLon())
+		if r.Station != nil {
+			fmt.Printf("  station   %s %s\n", r.Station.GetStopId(), r.Station.GetStopName())
+		}
+		fmt.Printf("  routes    %s\n", strings.Join(r.Routes, ", "))
+		fmt.Printf("  calls     %d\n", r.StopTimes)
+	case *server.RouteDetail:
+		rt := r.Route
+		fmt.Printf("Route %s %s %s\n", rt.GetRouteId(), rt.GetRouteShortName(), rt.GetRouteLongName())
+		if rt.GetRouteDesc() != "" {
+			fmt.Printf("  %s\n", rt.GetRouteDesc())
+		}
+		fmt.Printf("  type      %d\n", rt.GetRouteType())
+		fmt.Printf("  trips     %d\n", r.Trips)
+		fmt.Printf("  headsigns %s\n", strings.Join(r.Headsigns, ", "))
+		fmt.Printf("  shapes    %s\n", strings.Join(r.Shapes, ", "))
+	case *server.TripDetail:
+		t := r.Trip
+		fmt.Printf("Trip %s to %s\n", t.GetTripId(), t.GetTripHeadsign())
+		fmt.Printf("  route     %s %s\n", t.GetRouteId(), r.Route.GetRouteLongName())
+		fmt.Printf("  service   %s, direction %d, shape %s\n", t.GetServiceId(), t.GetDirectionId(), t.GetShapeId())
+		for _, st := range r.StopTimes {
+			fmt.Printf("  %4d  %8s  %8s  %-8s %s\n", st.GetStopSequence(), st.GetArrivalTime(), st.GetDepartureTime(), st.GetStopId(), r.StopNames[st.GetStopId()])
+		}
+	}
+	return nil
+}
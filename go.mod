@@ -2,15 +2,15 @@ module studious-waffle
 
 go 1.25.5
 
-require github.com/gin-gonic/gin v1.11.0
-
 require (
-	github.com/gin-contrib/cors v1.7.6 // indirect
-	github.com/gin-contrib/logger v1.2.6 // indirect
-	github.com/mattn/go-colorable v0.1.14 // indirect
-	github.com/rs/zerolog v1.34.0 // indirect
+	github.com/gin-contrib/cors v1.7.6
+	github.com/gin-contrib/logger v1.2.6
+	github.com/gin-gonic/gin v1.11.0
+	github.com/rs/zerolog v1.34.0
 )
 
+require github.com/mattn/go-colorable v0.1.14 // indirect
+
 require (
 	github.com/MobilityData/gtfs-realtime-bindings/golang/gtfs v1.0.0
 	github.com/bytedance/sonic v1.14.0 // indirect
@@ -45,5 +45,5 @@ require (
 	golang.org/x/text v0.27.0 // indirect
 	golang.org/x/time v0.14.0
 	golang.org/x/tools v0.34.0 // indirect
-	google.golang.org/protobuf v1.36.11 // indirect
+	google.golang.org/protobuf v1.36.11
 )
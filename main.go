@@ -1,16 +1,117 @@
 package main
 
 import (
+	"errors"
+	"flag"
+	"fmt"
+	"io/fs"
 	"log"
-	"studious-waffle/server"
+	"os"
+	"strings"
 
 	"github.com/subosito/gotenv"
 )
 
+const programName = "studious-waffle"
+
+// Exit codes shared by every command.
+const (
+	exitOK      = 0
+	exitFailure = 1
+	exitUsage   = 2
+)
+
+// errUsage is returned by a command whose arguments were wrong, after it
+// has printed its usage.
+var errUsage = errors.New("usage")
+
+type command struct {
+	name    string
+	summary string
+	run     func(args []string) error
+}
+
+var commands = []command{
+	{"serve", "serve the API (the default without a command)", serveCommand},
+	{"ingest", "validate a static GTFS feed and generate the compiled data from it", ingestCommand},
+	{"validate", "check a static GTFS feed and print what is wrong with it", validateCommand},
+	{"diff", "compare two static GTFS feeds", diffCommand},
+	{"rt", "work with GTFS-realtime snapshots (rt dump)", rtCommand},
+	{"query", "look up a stop, route or trip in the compiled data", queryCommand},
+}
+
 func main() {
-	err := gotenv.Load(".env")
-	if err != nil {
+	// a missing .env is fine, everything can come from the environment
+	if err := gotenv.Load(".env"); err != nil && !errors.Is(err, fs.ErrNotExist) {
 		log.Println("Error loading .env file:", err)
 	}
-	server.ServeGin()
+
+	args := os.Args[1:]
+	// without a command, or with only flags, the binary serves as it always has
+	name := "serve"
+	if len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+		name, args = args[0], args[1:]
+	}
+	if name == "help" {
+		usage()
+		os.Exit(exitOK)
+	}
+
+	for _, cmd := range commands {
+		if cmd.name == name {
+			os.Exit(exitCode(cmd.run(args)))
+		}
+	}
+	fmt.Fprintf(os.Stderr, "%s: unknown command %q\n", programName, name)
+	usage()
+	os.Exit(exitUsage)
+}
+
+func usage() {
+	fmt.Fprintf(os.Stderr, "usage: %s <command> [flags] [args]\n\ncommands:\n", programName)
+	for _, cmd := range commands {
+		fmt.Fprintf(os.Stderr, "  %-9s %s\n", cmd.name, cmd.summary)
+	}
+	fmt.Fprintf(os.Stderr, "\nRun %s <command> -h for a command's flags.\n", programName)
+	fmt.Fprintf(os.Stderr, "Exit codes: %d success, %d failure, %d bad usage.\n", exitOK, exitFailure, exitUsage)
+}
+
+// exitCode reports a command's error and picks the exit code for it.
+func exitCode(err error) int {
+	switch {
+	case err == nil, errors.Is(err, flag.ErrHelp):
+		return exitOK
+	case errors.Is(err, errUsage):
+		return exitUsage
+	default:
+		fmt.Fprintf(os.Stderr, "%s: %v\n", programName, err)
+		return exitFailure
+	}
+}
+
+// newFlags makes the flag set for a command; usage is its synopsis after
+// the program name.
+func newFlags(name, usage string) *flag.FlagSet {
+	flags := flag.NewFlagSet(name, flag.ContinueOnError)
+	flags.Usage = func() {
+		fmt.Fprintf(flags.Output(), "usage: %s %s\n", programName, usage)
+		flags.PrintDefaults()
+	}
+	return flags
+}
+
+// parseFlags parses a command's flags and checks it was given between
+// minArgs and maxArgs arguments after them.
+func parseFlags(flags *flag.FlagSet, args []string, minArgs, maxArgs int) error {
+	if err := flags.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return err
+		}
+		return errUsage
+	}
+	if flags.NArg() < minArgs || flags.NArg() > maxArgs {
+		flags.Usage()
+		return errUsage
+	}
+	return nil
 }
@@ -0,0 +1,41 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestIngestExitCode(t *testing.T) {
+	// an empty directory is a feed with none of the required files
+	empty := t.TempDir()
+	// a feed whose stops.txt only has a header fails validation too
+	broken := t.TempDir()
+	if err := os.WriteFile(filepath.Join(broken, "stops.txt"), []byte("stop_id,stop_name\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name string
+		args []string
+		want int
+	}{
+		{"feed that doesn't exist", []string{filepath.Join(empty, "missing.zip")}, exitFailure},
+		{"feed missing required files", []string{empty}, exitFailure},
+		{"feed failing validation", []string{broken}, exitFailure},
+		{"more than one feed", []string{empty, broken}, exitUsage},
+		{"unknown flag", []string{"-nope", empty}, exitUsage},
+		{"help", []string{"-h"}, exitOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("CONFIG_FILE", "")
+			// keep the validation report out of the source tree
+			t.Setenv("GTFS_OUTPUT_DIR", t.TempDir())
+
+			if got := exitCode(ingestCommand(tt.args)); got != tt.want {
+				t.Errorf("got exit code %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
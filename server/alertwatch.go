@@ -0,0 +1,124 @@
+package server
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"log"
+	"sort"
+	"studious-waffle/server/protodata"
+	"sync"
+
+	"google.golang.org/protobuf/proto"
+)
+
+const (
+	AlertCreated  = "alert.created"
+	AlertUpdated  = "alert.updated"
+	AlertResolved = "alert.resolved"
+)
+
+// AlertChange is the payload of an alert event. For resolved alerts Alert
+// holds the last version that was seen in the feed.
+type AlertChange struct {
+	AlertId string                `json:"alert_id"`
+	Hash    string                `json:"hash"`
+	Alert   *protodata.AlertProto `json:"alert"`
+}
+
+// AlertWatcher remembers the alerts seen on the previous poll and reports
+// what changed, keyed by entity ID and compared by content hash.
+type AlertWatcher struct {
+	mu     sync.Mutex
+	primed bool
+	seen   map[string]AlertChange
+}
+
+func NewAlertWatcher() *AlertWatcher {
+	return &AlertWatcher{seen: make(map[string]AlertChange)}
+}
+
+// Diff compares a freshly fetched alert feed with the previous one. The
+// first call only records a baseline so a restart does not replay every
+// active alert as new.
+func (w *AlertWatcher) Diff(entities []*protodata.AlertEntityProto) []Event {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	current := make(map[string]AlertChange, len(entities))
+	for _, e := range entities {
+		id := e.GetId()
+		current[id] = AlertChange{AlertId: id, Hash: hashAlert(e.GetAlert()), Alert: e.GetAlert()}
+	}
+
+	var events []Event
+	if w.primed {
+		for id, cur := range current {
+			prev, existed := w.seen[id]
+			switch {
+			case !existed:
+				events = append(events, NewEvent(AlertCreated, cur))
+			case prev.Hash != cur.Hash:
+				events = append(events, NewEvent(AlertUpdated, cur))
+			}
+		}
+		for id, prev := range w.seen {
+			if _, still := current[id]; !still {
+				events = append(events, NewEvent(AlertResolved, prev))
+			}
+		}
+	}
+
+	w.seen = current
+	w.primed = true
+
+	// map iteration is random, keep deliveries stable
+	sort.Slice(events, func(i, j int) bool {
+		a, b := events[i].Data.(AlertChange), events[j].Data.(AlertChange)
+		if a.AlertId != b.AlertId {
+			return a.AlertId < b.AlertId
+		}
+		return events[i].Type < events[j].Type
+	})
+	return events
+}
+
+func hashAlert(a *protodata.AlertProto) string {
+	data, err := proto.MarshalOptions{Deterministic: true}.Marshal(a)
+	if err != nil {
+		return ""
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// WatchAlerts returns a realtime listener that diffs the alerts of every
+// poll and hands each change to the notifier. Polls without alerts are
+// skipped, so the watcher doesn't prime on an empty set and then announce
+// every active alert as created.
+func WatchAlerts(watcher *AlertWatcher, notifier *WebhookNotifier) func(*RealtimeSnapshot) {
+	return func(snap *RealtimeSnapshot) {
+		if snap.AlertsUnavailable {
+			return
+		}
+		if events := watcher.Diff(snap.Alerts); len(events) > 0 {
+			log.Printf("Alert feed changed: %d event(s)\n", len(events))
+			notifier.Notify(events)
+		}
+	}
+}
+
+// startAlertWebhooks watches the alerts of each realtime poll, posting to
+// the configured webhooks.
+func startAlertWebhooks() {
+	if config.Webhooks.Secret == "" {
+		log.Println("WARNING: no webhook secret is set, webhook signatures use an empty key.")
+	}
+
+	urls := config.Webhooks.URLs
+	notifier := NewWebhookNotifier(urls, config.Webhooks.Secret, config.Data.DeadLetterLog)
+	notifier.Client.Timeout = config.Webhooks.Timeout
+	notifier.MaxAttempts = config.Webhooks.MaxAttempts
+	eventNotifier = notifier
+	log.Printf("Watching alerts for %d webhook(s)\n", len(urls))
+	OnRealtimeUpdate(WatchAlerts(NewAlertWatcher(), notifier))
+}
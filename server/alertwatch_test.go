@@ -0,0 +1,72 @@
+package server
+
+import (
+	"studious-waffle/server/protodata"
+	"testing"
+
+	"google.golang.org/protobuf/proto"
+)
+
+func testAlert(id string, effect int32) *protodata.AlertEntityProto {
+	return &protodata.AlertEntityProto{
+		Id:    proto.String(id),
+		Alert: &protodata.AlertProto{Effect: proto.Int32(effect)},
+	}
+}
+
+func TestAlertWatcherDiff(t *testing.T) {
+	type change struct {
+		typ, id string
+		effect  int32
+	}
+	polls := []struct {
+		name   string
+		alerts []*protodata.AlertEntityProto
+		want   []change
+	}{
+		{
+			name:   "first poll only primes",
+			alerts: []*protodata.AlertEntityProto{testAlert("a", 1), testAlert("b", 2)},
+		},
+		{
+			name:   "nothing changed",
+			alerts: []*protodata.AlertEntityProto{testAlert("b", 2), testAlert("a", 1)},
+		},
+		{
+			name:   "created and updated",
+			alerts: []*protodata.AlertEntityProto{testAlert("a", 3), testAlert("b", 2), testAlert("c", 4)},
+			want:   []change{{AlertUpdated, "a", 3}, {AlertCreated, "c", 4}},
+		},
+		{
+			name:   "resolved keeps the last version",
+			alerts: []*protodata.AlertEntityProto{testAlert("c", 4)},
+			want:   []change{{AlertResolved, "a", 3}, {AlertResolved, "b", 2}},
+		},
+		{
+			name: "all resolved",
+			want: []change{{AlertResolved, "c", 4}},
+		},
+	}
+
+	w := NewAlertWatcher()
+	for _, poll := range polls {
+		events := w.Diff(poll.alerts)
+		if len(events) != len(poll.want) {
+			t.Fatalf("%s: got %d event(s), want %d: %+v", poll.name, len(events), len(poll.want), events)
+		}
+		for i, ev := range events {
+			data, ok := ev.Data.(AlertChange)
+			if !ok {
+				t.Fatalf("%s: event data is %T, want AlertChange", poll.name, ev.Data)
+			}
+			want := poll.want[i]
+			if ev.Type != want.typ || data.AlertId != want.id || data.Alert.GetEffect() != want.effect {
+				t.Errorf("%s: event %d = %s %s effect %d, want %s %s effect %d",
+					poll.name, i, ev.Type, data.AlertId, data.Alert.GetEffect(), want.typ, want.id, want.effect)
+			}
+			if data.Hash != hashAlert(data.Alert) {
+				t.Errorf("%s: event %d hash %q doesn't match its alert", poll.name, i, data.Hash)
+			}
+		}
+	}
+}
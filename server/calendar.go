@@ -0,0 +1,96 @@
+package server
+
+import (
+	"fmt"
+	"sort"
+	"studious-waffle/server/protodata"
+	"time"
+)
+
+// GTFS calendar_dates exception_type values.
+const (
+	ServiceAdded   = 1
+	ServiceRemoved = 2
+)
+
+// Calendar
+func findCalendar(serviceId string) (*protodata.CalendarProto, bool) {
+	data := Calendars
+	n := len(data)
+
+	idx := sort.Search(n, func(i int) bool {
+		return data[i].GetServiceId() >= serviceId
+	})
+
+	if idx < n && data[idx].GetServiceId() == serviceId {
+		return data[idx], true
+	}
+	return nil, false
+}
+
+// Calendar Dates
+func findCalendarDate(serviceId, date string) (*protodata.CalendarDateProto, bool) {
+	data := CalendarDates
+	n := len(data)
+
+	idx := sort.Search(n, func(i int) bool {
+		if data[i].GetServiceId() != serviceId {
+			return data[i].GetServiceId() >= serviceId
+		}
+		return data[i].GetDate() >= date
+	})
+
+	if idx < n && data[idx].GetServiceId() == serviceId && data[idx].GetDate() == date {
+		return data[idx], true
+	}
+	return nil, false
+}
+
+// parseServiceDate reads a date as YYYY-MM-DD or the GTFS YYYYMMDD.
+func parseServiceDate(s string) (time.Time, error) {
+	for _, layout := range []string{"2006-01-02", "20060102"} {
+		if d, err := time.ParseInLocation(layout, s, agencyLocation); err == nil {
+			return d, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("date must be YYYY-MM-DD or YYYYMMDD")
+}
+
+// serviceRunsOn reports whether a service_id runs on a date (YYYYMMDD):
+// calendar_dates exceptions first, then the weekly pattern within the
+// calendar's date range.
+func serviceRunsOn(serviceId, date string) bool {
+	if ex, found := findCalendarDate(serviceId, date); found {
+		return ex.GetExceptionType() == ServiceAdded
+	}
+
+	cal, found := findCalendar(serviceId)
+	if !found || date < cal.GetStartDate() || date > cal.GetEndDate() {
+		return false
+	}
+	d, err := time.Parse("20060102", date)
+	if err != nil {
+		return false
+	}
+	days := [7]int32{
+		cal.GetSunday(), cal.GetMonday(), cal.GetTuesday(), cal.GetWednesday(),
+		cal.GetThursday(), cal.GetFriday(), cal.GetSaturday(),
+	}
+	return days[d.Weekday()] == 1
+}
+
+// calendarCovers reports whether any service is defined on a date, to tell
+// "no trips that day" apart from a date outside the feed.
+func calendarCovers(date string) bool {
+	for _, cal := range Calendars {
+		if date >= cal.GetStartDate() && date <= cal.GetEndDate() {
+			return true
+		}
+	}
+	for _, cd := range CalendarDates {
+		if cd.GetDate() == date && cd.GetExceptionType() == ServiceAdded {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,563 @@
+package server
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"studious-waffle/server/protodata"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Config is everything the server can be configured with. Each setting is
+// read, in increasing precedence, from its default, a JSON config file, the
+// environment and command-line flags.
+type Config struct {
+	Server    ServerConfig
+	CORS      CORSConfig
+	RateLimit RateLimitConfig
+	Feeds     FeedConfig
+	Data      DataConfig
+	Cache     CacheConfig
+	Logging   LoggingConfig
+	Webhooks  WebhookConfig
+	Analytics AnalyticsConfig
+
+	// File is the config file read, if any.
+	File string
+	// sources says where each setting's value came from, by key.
+	sources map[string]string
+}
+
+type ServerConfig struct {
+	Port         int
+	Seed         bool
+	ReadTimeout  time.Duration
+	WriteTimeout time.Duration
+	// AdminToken guards the admin endpoints; without one they only answer
+	// requests from this machine.
+	AdminToken string
+}
+
+type CORSConfig struct {
+	AllowOrigins []string
+}
+
+// RateLimitConfig caps requests across all clients. A rate of zero turns
+// limiting off.
+type RateLimitConfig struct {
+	RequestsPerSecond float64
+	Burst             int
+}
+
+type FeedConfig struct {
+	AlertsURL           string
+	TripUpdatesURL      string
+	VehiclePositionsURL string
+	Timeout             time.Duration
+	PollInterval        time.Duration
+}
+
+type DataConfig struct {
+	// Input is a directory or zip of GTFS text files to ingest.
+	Input string
+	// Output is where the generated data and validation report go.
+	Output        string
+	OTPStorePath  string
+	DeadLetterLog string
+}
+
+type CacheConfig struct {
+	// RealtimeMaxAge is how old the polled snapshot may get before handlers
+	// fetch the feeds themselves. Zero means two poll intervals.
+	RealtimeMaxAge time.Duration
+	PlannerDays    int
+}
+
+type LoggingConfig struct {
+	Level     string
+	AccessLog bool
+	UTC       bool
+	SkipPaths []string
+}
+
+type WebhookConfig struct {
+	URLs        []string
+	Secret      string
+	Timeout     time.Duration
+	MaxAttempts int
+}
+
+type AnalyticsConfig struct {
+	OTPRetentionDays  int
+	GhostGraceMinutes int
+	StuckMinutes      int
+	SpacingThresholds map[string]SpacingThresholds
+}
+
+func DefaultConfig() *Config {
+	return &Config{
+		Server: ServerConfig{Port: 8080},
+		CORS:   CORSConfig{AllowOrigins: []string{"http://localhost:8080"}},
+		RateLimit: RateLimitConfig{
+			RequestsPerSecond: 5,
+			Burst:             10,
+		},
+		Feeds: FeedConfig{
+			AlertsURL:           "https://www.rtd-denver.com/files/gtfs-rt/Alerts.pb",
+			TripUpdatesURL:      "https://www.rtd-denver.com/files/gtfs-rt/TripUpdate.pb",
+			VehiclePositionsURL: "https://www.rtd-denver.com/files/gtfs-rt/VehiclePosition.pb",
+			Timeout:             10 * time.Second,
+			PollInterval:        30 * time.Second,
+		},
+		Data: DataConfig{
+			Input:         protodata.InputDir(),
+			Output:        protodata.OutputDir(),
+			DeadLetterLog: "webhook_dead_letter.log",
+		},
+		Cache:   CacheConfig{PlannerDays: 3},
+		Logging: LoggingConfig{Level: "info", AccessLog: true},
+		Webhooks: WebhookConfig{
+			Timeout:     10 * time.Second,
+			MaxAttempts: 5,
+		},
+		Analytics: AnalyticsConfig{
+			OTPRetentionDays:  defaultOTPRetentionDays,
+			GhostGraceMinutes: defaultGhostGraceMinutes,
+			StuckMinutes:      defaultStuckMinutes,
+		},
+		sources: make(map[string]string),
+	}
+}
+
+// config is the configuration the server runs with.
+var config = DefaultConfig()
+
+// Configure makes cfg the configuration the server runs with.
+func Configure(cfg *Config) {
+	config = cfg
+	protodata.UseOutput(cfg.Data.Output)
+}
+
+// setting is one configurable value: its key in the config file and as a
+// flag, the environment variable it's read from, and the field it sets.
+type setting struct {
+	key    string
+	env    string
+	usage  string
+	secret bool
+	field  func(c *Config) any
+}
+
+var settings = []setting{
+	{key: "server.port", env: "PORT", usage: "port to serve on", field: func(c *Config) any { return &c.Server.Port }},
+	{key: "server.seed", env: "SEED_DATA", usage: "ingest the static feed before serving", field: func(c *Config) any { return &c.Server.Seed }},
+	{key: "server.read_timeout", env: "READ_TIMEOUT", usage: "longest time to read a request, 0 for none", field: func(c *Config) any { return &c.Server.ReadTimeout }},
+	{key: "server.write_timeout", env: "WRITE_TIMEOUT", usage: "longest time to write a response, 0 for none", field: func(c *Config) any { return &c.Server.WriteTimeout }},
+	{key: "server.admin_token", env: "ADMIN_TOKEN", usage: "bearer token for the admin endpoints", secret: true, field: func(c *Config) any { return &c.Server.AdminToken }},
+	{key: "cors.allow_origins", env: "CORS_ALLOW_ORIGINS", usage: "comma separated origins allowed to call the API", field: func(c *Config) any { return &c.CORS.AllowOrigins }},
+	{key: "rate_limit.requests_per_second", env: "RATE_LIMIT_RPS", usage: "requests per second across all clients, 0 for no limit", field: func(c *Config) any { return &c.RateLimit.RequestsPerSecond }},
+	{key: "rate_limit.burst", env: "RATE_LIMIT_BURST", usage: "requests allowed at once above the rate", field: func(c *Config) any { return &c.RateLimit.Burst }},
+	{key: "feeds.alerts_url", env: "FEED_ALERTS_URL", usage: "GTFS-realtime service alerts feed", field: func(c *Config) any { return &c.Feeds.AlertsURL }},
+	{key: "feeds.trip_updates_url", env: "FEED_TRIP_UPDATES_URL", usage: "GTFS-realtime trip updates feed", field: func(c *Config) any { return &c.Feeds.TripUpdatesURL }},
+	{key: "feeds.vehicle_positions_url", env: "FEED_VEHICLE_POSITIONS_URL", usage: "GTFS-realtime vehicle positions feed", field: func(c *Config) any { return &c.Feeds.VehiclePositionsURL }},
+	{key: "feeds.timeout", env: "FEED_TIMEOUT", usage: "longest time to fetch a feed", field: func(c *Config) any { return &c.Feeds.Timeout }},
+	{key: "feeds.poll_interval", env: "REALTIME_POLL_INTERVAL", usage: "how often the realtime feeds are polled", field: func(c *Config) any { return &c.Feeds.PollInterval }},
+	{key: "data.input", env: "GTFS_INPUT", usage: "directory or zip of the static GTFS feed to ingest", field: func(c *Config) any { return &c.Data.Input }},
+	{key: "data.output", env: "GTFS_OUTPUT_DIR", usage: "directory the generated data is written to", field: func(c *Config) any { return &c.Data.Output }},
+	{key: "data.otp_store_path", env: "OTP_STORE_PATH", usage: "file on-time performance observations are kept in, empty to keep them in memory", field: func(c *Config) any { return &c.Data.OTPStorePath }},
+	{key: "data.dead_letter_log", env: "WEBHOOK_DEAD_LETTER_LOG", usage: "file undeliverable webhook events are written to", field: func(c *Config) any { return &c.Data.DeadLetterLog }},
+	{key: "cache.realtime_max_age", env: "REALTIME_MAX_AGE", usage: "how stale the polled realtime data may get, 0 for two poll intervals", field: func(c *Config) any { return &c.Cache.RealtimeMaxAge }},
+	{key: "cache.planner_days", env: "PLANNER_CACHE_DAYS", usage: "service days of trip planner data kept", field: func(c *Config) any { return &c.Cache.PlannerDays }},
+	{key: "logging.level", env: "LOG_LEVEL", usage: "least severe requests logged: debug, info, warn or error", field: func(c *Config) any { return &c.Logging.Level }},
+	{key: "logging.access_log", env: "ACCESS_LOG", usage: "log every request", field: func(c *Config) any { return &c.Logging.AccessLog }},
+	{key: "logging.utc", env: "LOG_UTC", usage: "log request times in UTC", field: func(c *Config) any { return &c.Logging.UTC }},
+	{key: "logging.skip_paths", env: "LOG_SKIP_PATHS", usage: "comma separated paths left out of the access log", field: func(c *Config) any { return &c.Logging.SkipPaths }},
+	{key: "webhooks.urls", env: "ALERT_WEBHOOK_URLS", usage: "comma separated URLs alert and analytics events are posted to", field: func(c *Config) any { return &c.Webhooks.URLs }},
+	{key: "webhooks.secret", env: "WEBHOOK_SECRET", usage: "key webhook payloads are signed with", secret: true, field: func(c *Config) any { return &c.Webhooks.Secret }},
+	{key: "webhooks.timeout", env: "WEBHOOK_TIMEOUT", usage: "longest time to deliver an event", field: func(c *Config) any { return &c.Webhooks.Timeout }},
+	{key: "webhooks.max_attempts", env: "WEBHOOK_MAX_ATTEMPTS", usage: "deliveries tried before an event is dead-lettered", field: func(c *Config) any { return &c.Webhooks.MaxAttempts }},
+	{key: "analytics.otp_retention_days", env: "OTP_RETENTION_DAYS", usage: "days of on-time performance observations kept", field: func(c *Config) any { return &c.Analytics.OTPRetentionDays }},
+	{key: "analytics.ghost_grace_minutes", env: "GHOST_GRACE_MINUTES", usage: "how late a trip may be before it's reported missing", field: func(c *Config) any { return &c.Analytics.GhostGraceMinutes }},
+	{key: "analytics.stuck_minutes", env: "STUCK_MINUTES", usage: "how long a vehicle may stand still before it's reported stuck", field: func(c *Config) any { return &c.Analytics.StuckMinutes }},
+	{key: "analytics.spacing_thresholds", env: "SPACING_THRESHOLDS", usage: `per-route bunching thresholds as JSON, such as {"15":{"bunching_ratio":0.3}}`, field: func(c *Config) any { return &c.Analytics.SpacingThresholds }},
+}
+
+func findSetting(key string) (setting, bool) {
+	i := slices.IndexFunc(settings, func(s setting) bool { return s.key == key })
+	if i < 0 {
+		return setting{}, false
+	}
+	return settings[i], true
+}
+
+// set parses a setting's value from its text form. The source is only
+// recorded once the value is in place.
+func (c *Config) set(s setting, value, source string) error {
+	switch p := s.field(c).(type) {
+	case *string:
+		*p = value
+	case *int:
+		n, err := strconv.Atoi(strings.TrimSpace(value))
+		if err != nil {
+			return fmt.Errorf("%q is not a whole number", value)
+		}
+		*p = n
+	case *float64:
+		f, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+		if err != nil {
+			return fmt.Errorf("%q is not a number", value)
+		}
+		*p = f
+	case *bool:
+		b, err := strconv.ParseBool(strings.TrimSpace(value))
+		if err != nil {
+			return fmt.Errorf("%q is not true or false", value)
+		}
+		*p = b
+	case *time.Duration:
+		d, err := time.ParseDuration(strings.TrimSpace(value))
+		if err != nil {
+			return fmt.Errorf("%q is not a duration such as 30s or 5m", value)
+		}
+		*p = d
+	case *[]string:
+		list := make([]string, 0)
+		for _, v := range strings.Split(value, ",") {
+			if v = strings.TrimSpace(v); v != "" {
+				list = append(list, v)
+			}
+		}
+		*p = list
+	case *map[string]SpacingThresholds:
+		thresholds := make(map[string]SpacingThresholds)
+		if strings.TrimSpace(value) != "" {
+			if err := json.Unmarshal([]byte(value), &thresholds); err != nil {
+				return fmt.Errorf("not a JSON object of route thresholds: %v", err)
+			}
+		}
+		*p = thresholds
+	}
+	c.sources[s.key] = source
+	return nil
+}
+
+// value returns a setting's value as it is shown to operators, with
+// secrets and the credentials in URLs hidden.
+func (c *Config) value(s setting) any {
+	switch p := s.field(c).(type) {
+	case *string:
+		if s.secret && *p != "" {
+			return "[redacted]"
+		}
+		return redactURL(*p)
+	case *time.Duration:
+		return p.String()
+	case *[]string:
+		list := make([]string, len(*p))
+		for i, v := range *p {
+			list[i] = redactURL(v)
+		}
+		return list
+	case *int:
+		return *p
+	case *float64:
+		return *p
+	case *bool:
+		return *p
+	case *map[string]SpacingThresholds:
+		return *p
+	}
+	return nil
+}
+
+// text returns a setting's value in the form the environment and flags
+// take it.
+func (c *Config) text(s setting) string {
+	switch v := c.value(s).(type) {
+	case []string:
+		return strings.Join(v, ",")
+	case map[string]SpacingThresholds:
+		if len(v) == 0 {
+			return ""
+		}
+		data, _ := json.Marshal(v)
+		return string(data)
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+// redactURL hides the password and query of a URL, where webhook receivers
+// tend to keep their tokens. Anything else is returned as it is.
+func redactURL(s string) string {
+	u, err := url.Parse(s)
+	if err != nil || u.Scheme == "" || u.Host == "" {
+		return s
+	}
+	if u.RawQuery != "" {
+		u.RawQuery = "[redacted]"
+	}
+	return u.Redacted()
+}
+
+// readConfigFile applies a JSON config file, an object of sections keyed
+// like the settings, such as {"server": {"port": 8080}}.
+func (c *Config) readConfigFile(path string) []error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return []error{fmt.Errorf("config file: %w", err)}
+	}
+	var sections map[string]map[string]json.RawMessage
+	if err := json.Unmarshal(data, &sections); err != nil {
+		return []error{fmt.Errorf("config file %s: %w", path, err)}
+	}
+	c.File = path
+
+	var errs []error
+	for section, values := range sections {
+		for name, raw := range values {
+			key := section + "." + name
+			s, found := findSetting(key)
+			if !found {
+				errs = append(errs, fmt.Errorf("%s (file %s): unknown setting", key, path))
+				continue
+			}
+			if err := c.set(s, rawConfigValue(raw), "file "+path); err != nil {
+				errs = append(errs, fmt.Errorf("%s (file %s): %w", key, path, err))
+			}
+		}
+	}
+	return errs
+}
+
+// rawConfigValue turns a value from the config file into the text form the
+// environment and flags use: strings unquoted, lists comma separated and
+// everything else, numbers and objects alike, as written.
+func rawConfigValue(raw json.RawMessage) string {
+	var s string
+	if json.Unmarshal(raw, &s) == nil {
+		return s
+	}
+	var list []string
+	if json.Unmarshal(raw, &list) == nil {
+		return strings.Join(list, ",")
+	}
+	return string(raw)
+}
+
+// ConfigFlags registers a flag for every setting, named by its key such as
+// -feeds.poll_interval, and returns the values given once flags are
+// parsed, for LoadConfig.
+func ConfigFlags(flags *flag.FlagSet) map[string]string {
+	given := make(map[string]string)
+	defaults := DefaultConfig()
+	for _, s := range settings {
+		usage := s.usage + " (" + s.env + ")"
+		f := &configFlag{key: s.key, given: given}
+		_, f.isBool = s.field(defaults).(*bool)
+		if !s.secret {
+			f.def = defaults.text(s)
+		}
+		flags.Var(f, s.key, usage)
+	}
+	return given
+}
+
+type configFlag struct {
+	key    string
+	def    string
+	isBool bool
+	given  map[string]string
+}
+
+func (f *configFlag) String() string {
+	if f == nil {
+		return ""
+	}
+	if v, found := f.given[f.key]; found {
+		return v
+	}
+	return f.def
+}
+
+func (f *configFlag) Set(v string) error {
+	// parse now so a bad value is reported against the flag
+	s, _ := findSetting(f.key)
+	if err := DefaultConfig().set(s, v, ""); err != nil {
+		return err
+	}
+	f.given[f.key] = v
+	return nil
+}
+
+func (f *configFlag) IsBoolFlag() bool {
+	return f.isBool
+}
+
+// LoadConfig reads the configuration from the defaults, the config file at
+// path if there is one, the environment and then flagValues, keyed by
+// setting, and checks it. The error lists every problem found.
+func LoadConfig(path string, flagValues map[string]string) (*Config, error) {
+	c := DefaultConfig()
+	for _, s := range settings {
+		c.sources[s.key] = "default"
+	}
+
+	var errs []error
+	if path != "" {
+		errs = append(errs, c.readConfigFile(path)...)
+	}
+	for _, s := range settings {
+		if v, found := os.LookupEnv(s.env); found && v != "" {
+			if err := c.set(s, v, "env "+s.env); err != nil {
+				errs = append(errs, fmt.Errorf("%s (env %s): %w", s.key, s.env, err))
+			}
+		}
+	}
+	for _, s := range settings {
+		if v, found := flagValues[s.key]; found {
+			if err := c.set(s, v, "flag -"+s.key); err != nil {
+				errs = append(errs, fmt.Errorf("%s (flag -%s): %w", s.key, s.key, err))
+			}
+		}
+	}
+	// a value that didn't parse leaves the one before it, so validating
+	// still only reports real problems
+	errs = append(errs, c.validate()...)
+	if len(errs) > 0 {
+		slices.SortFunc(errs, func(a, b error) int { return strings.Compare(a.Error(), b.Error()) })
+		return nil, &ConfigError{Problems: errs}
+	}
+	return c, nil
+}
+
+// ConfigError lists everything wrong with a configuration.
+type ConfigError struct {
+	Problems []error
+}
+
+func (e *ConfigError) Error() string {
+	var b strings.Builder
+	b.WriteString("invalid configuration:")
+	for _, p := range e.Problems {
+		b.WriteString("\n  " + p.Error())
+	}
+	return b.String()
+}
+
+// validate checks the values make sense together, naming where each bad
+// one came from.
+func (c *Config) validate() []error {
+	var errs []error
+	check := func(key string, ok bool, problem string, args ...any) {
+		if !ok {
+			errs = append(errs, fmt.Errorf("%s (%s): %s", key, c.sources[key], fmt.Sprintf(problem, args...)))
+		}
+	}
+	checkURL := func(key, value string) {
+		u, err := url.Parse(value)
+		check(key, err == nil && (u.Scheme == "http" || u.Scheme == "https") && u.Host != "", "%q is not an http or https URL", redactURL(value))
+	}
+
+	check("server.port", c.Server.Port > 0 && c.Server.Port <= 65535, "must be between 1 and 65535")
+	check("server.read_timeout", c.Server.ReadTimeout >= 0, "must not be negative")
+	check("server.write_timeout", c.Server.WriteTimeout >= 0, "must not be negative")
+	check("cors.allow_origins", len(c.CORS.AllowOrigins) > 0, `must list at least one origin, or "*" for any`)
+	for _, origin := range c.CORS.AllowOrigins {
+		if origin != "*" {
+			checkURL("cors.allow_origins", origin)
+		}
+	}
+	check("rate_limit.requests_per_second", c.RateLimit.RequestsPerSecond >= 0, "must not be negative")
+	check("rate_limit.burst", c.RateLimit.RequestsPerSecond == 0 || c.RateLimit.Burst >= 1, "must be at least 1 while requests are limited")
+	checkURL("feeds.alerts_url", c.Feeds.AlertsURL)
+	checkURL("feeds.trip_updates_url", c.Feeds.TripUpdatesURL)
+	checkURL("feeds.vehicle_positions_url", c.Feeds.VehiclePositionsURL)
+	check("feeds.timeout", c.Feeds.Timeout > 0, "must be positive")
+	check("feeds.poll_interval", c.Feeds.PollInterval > 0, "must be positive")
+	check("data.input", c.Data.Input != "", "must be set")
+	check("data.output", c.Data.Output != "", "must be set")
+	check("cache.realtime_max_age", c.Cache.RealtimeMaxAge >= 0, "must not be negative")
+	check("cache.planner_days", c.Cache.PlannerDays >= 1, "must be at least 1")
+	check("logging.level", slices.Contains([]string{"debug", "info", "warn", "error"}, c.Logging.Level), "must be debug, info, warn or error, not %q", c.Logging.Level)
+	for _, u := range c.Webhooks.URLs {
+		checkURL("webhooks.urls", u)
+	}
+	check("webhooks.timeout", c.Webhooks.Timeout > 0, "must be positive")
+	check("webhooks.max_attempts", c.Webhooks.MaxAttempts >= 1, "must be at least 1")
+	check("analytics.otp_retention_days", c.Analytics.OTPRetentionDays >= 1, "must be at least 1")
+	check("analytics.ghost_grace_minutes", c.Analytics.GhostGraceMinutes >= 1, "must be at least 1")
+	check("analytics.stuck_minutes", c.Analytics.StuckMinutes >= 1, "must be at least 1")
+	for routeId, t := range c.Analytics.SpacingThresholds {
+		check("analytics.spacing_thresholds", t.BunchingRatio >= 0 && t.GapRatio >= 0, "route %s has a negative ratio", routeId)
+	}
+	return errs
+}
+
+// realtimeMaxAge is how long a polled snapshot is served before handlers
+// fetch the feeds themselves.
+func (c *Config) realtimeMaxAge() time.Duration {
+	if c.Cache.RealtimeMaxAge > 0 {
+		return c.Cache.RealtimeMaxAge
+	}
+	return 2 * c.Feeds.PollInterval
+}
+
+// ConfigSetting is a setting as the admin endpoint shows it.
+type ConfigSetting struct {
+	Key    string `json:"key"`
+	Env    string `json:"env"`
+	Value  any    `json:"value"`
+	Source string `json:"source"`
+}
+
+type ConfigView struct {
+	File     string          `json:"file,omitempty"`
+	Settings []ConfigSetting `json:"settings"`
+}
+
+// View lists every setting with where it came from, secrets redacted.
+func (c *Config) View() ConfigView {
+	view := ConfigView{File: c.File, Settings: make([]ConfigSetting, 0, len(settings))}
+	for _, s := range settings {
+		source := c.sources[s.key]
+		if source == "" {
+			source = "default"
+		}
+		view.Settings = append(view.Settings, ConfigSetting{Key: s.key, Env: s.env, Value: c.value(s), Source: source})
+	}
+	return view
+}
+
+// AdminOnly lets requests through with the admin token or, when none is
+// configured, only from this machine.
+func AdminOnly() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if token := config.Server.AdminToken; token != "" {
+			given, found := strings.CutPrefix(c.GetHeader("Authorization"), "Bearer ")
+			if !found || subtle.ConstantTimeCompare([]byte(given), []byte(token)) != 1 {
+				c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "admin token required"})
+				return
+			}
+		} else if ip := net.ParseIP(c.ClientIP()); ip == nil || !ip.IsLoopback() {
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "set an admin token to use admin endpoints remotely"})
+			return
+		}
+		c.Next()
+	}
+}
+
+// GET /admin/config
+func HandleAdminConfig(c *gin.Context) {
+	c.JSON(http.StatusOK, config.View())
+}
@@ -0,0 +1,104 @@
+package server
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// writeConfigFile writes a config file setting the port and log level.
+func writeConfigFile(t *testing.T) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "config.json")
+	if err := os.WriteFile(path, []byte(`{"server": {"port": 8100}, "logging": {"level": "warn"}}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestLoadConfigPrecedence(t *testing.T) {
+	path := writeConfigFile(t)
+
+	tests := []struct {
+		name  string
+		file  string
+		env   map[string]string
+		flags map[string]string
+
+		wantPort      int
+		wantPortFrom  string
+		wantLevel     string
+		wantLevelFrom string
+		wantErr       string
+	}{
+		{
+			name:     "defaults",
+			wantPort: DefaultConfig().Server.Port, wantPortFrom: "default",
+			wantLevel: DefaultConfig().Logging.Level, wantLevelFrom: "default",
+		},
+		{
+			name:     "file over defaults",
+			file:     path,
+			wantPort: 8100, wantPortFrom: "file " + path,
+			wantLevel: "warn", wantLevelFrom: "file " + path,
+		},
+		{
+			name:     "env over file",
+			file:     path,
+			env:      map[string]string{"PORT": "8200"},
+			wantPort: 8200, wantPortFrom: "env PORT",
+			wantLevel: "warn", wantLevelFrom: "file " + path,
+		},
+		{
+			name:     "flag over env",
+			file:     path,
+			env:      map[string]string{"PORT": "8200", "LOG_LEVEL": "error"},
+			flags:    map[string]string{"server.port": "8300"},
+			wantPort: 8300, wantPortFrom: "flag -server.port",
+			wantLevel: "error", wantLevelFrom: "env LOG_LEVEL",
+		},
+		{
+			name:    "a bad value names where it came from",
+			file:    path,
+			env:     map[string]string{"PORT": "eighty"},
+			wantErr: `server.port (env PORT): "eighty" is not a whole number`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// empty variables are ignored, so this hides the real environment
+			for _, s := range settings {
+				t.Setenv(s.env, tt.env[s.env])
+			}
+
+			c, err := LoadConfig(tt.file, tt.flags)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("got error %v, want one containing %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if c.Server.Port != tt.wantPort {
+				t.Errorf("got port %d, want %d", c.Server.Port, tt.wantPort)
+			}
+			if c.Logging.Level != tt.wantLevel {
+				t.Errorf("got log level %q, want %q", c.Logging.Level, tt.wantLevel)
+			}
+			sources := make(map[string]string)
+			for _, s := range c.View().Settings {
+				sources[s.Key] = s.Source
+			}
+			if got := sources["server.port"]; got != tt.wantPortFrom {
+				t.Errorf("got port from %q, want %q", got, tt.wantPortFrom)
+			}
+			if got := sources["logging.level"]; got != tt.wantLevelFrom {
+				t.Errorf("got log level from %q, want %q", got, tt.wantLevelFrom)
+			}
+		})
+	}
+}
@@ -0,0 +1,398 @@
+package server
+
+import (
+	"fmt"
+	"math"
+	"net/http"
+	"sort"
+	"studious-waffle/server/protodata"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// maxFareLegs bounds the search over leg groups and transfer rules, which
+// grows with every leg.
+const maxFareLegs = 8
+
+// Stop Areas
+func findAreasForStop(stopId string) []string {
+	data := StopAreas
+	n := len(data)
+
+	idx := sort.Search(n, func(i int) bool {
+		return data[i].GetStopId() >= stopId
+	})
+
+	var areas []string
+	for ; idx < n && data[idx].GetStopId() == stopId; idx++ {
+		areas = append(areas, data[idx].GetAreaId())
+	}
+	return areas
+}
+
+// Fare Products (one row per fare media the product is sold on)
+func findFareProduct(productId, mediaId string) (*protodata.FareProductProto, bool) {
+	data := FareProducts
+	n := len(data)
+
+	idx := sort.Search(n, func(i int) bool {
+		return data[i].GetFareProductId() >= productId
+	})
+
+	// products without a fare media need none, e.g. free rides
+	var anyMedia *protodata.FareProductProto
+	for ; idx < n && data[idx].GetFareProductId() == productId; idx++ {
+		switch data[idx].GetFareMediaId() {
+		case mediaId:
+			return data[idx], true
+		case "":
+			anyMedia = data[idx]
+		}
+	}
+	return anyMedia, anyMedia != nil
+}
+
+// FareLeg is one ride in a journey to be priced.
+type FareLeg struct {
+	RouteId       string    `json:"route_id" binding:"required"`
+	FromStopId    string    `json:"from_stop_id"`
+	ToStopId      string    `json:"to_stop_id"`
+	DepartureTime time.Time `json:"departure_time" binding:"required"`
+	ArrivalTime   time.Time `json:"arrival_time"`
+}
+
+type FareQuoteRequest struct {
+	Legs        []FareLeg `json:"legs" binding:"required,min=1,dive"`
+	FareMediaId string    `json:"fare_media_id"`
+}
+
+// ResolvedLeg is how the fare rules see a leg.
+type ResolvedLeg struct {
+	RouteId     string   `json:"route_id"`
+	NetworkId   string   `json:"network_id,omitempty"`
+	FromAreaIds []string `json:"from_area_ids,omitempty"`
+	ToAreaIds   []string `json:"to_area_ids,omitempty"`
+	LegGroupIds []string `json:"leg_group_ids"`
+
+	choices []fareChoice
+}
+
+type fareChoice struct {
+	legGroupId string
+	productId  string
+}
+
+// FareLegQuote is what a rider pays for one leg of a quote.
+type FareLegQuote struct {
+	Leg              int     `json:"leg"`
+	LegGroupId       string  `json:"leg_group_id"`
+	FareProductId    string  `json:"fare_product_id,omitempty"`
+	FareProductName  string  `json:"fare_product_name,omitempty"`
+	Transfer         bool    `json:"transfer"`
+	FareTransferType int32   `json:"fare_transfer_type,omitempty"`
+	Amount           float64 `json:"amount"`
+}
+
+type FareQuote struct {
+	FareMediaId   string         `json:"fare_media_id"`
+	FareMediaName string         `json:"fare_media_name"`
+	Total         float64        `json:"total"`
+	Currency      string         `json:"currency"`
+	Legs          []FareLegQuote `json:"legs"`
+}
+
+type FareQuoteResponse struct {
+	Legs   []ResolvedLeg `json:"legs"`
+	Quotes []FareQuote   `json:"quotes"`
+	// false when the feed assigns no stops to areas and area conditions on
+	// leg rules were ignored
+	AreasResolved bool `json:"areas_resolved"`
+}
+
+// matchByDefault applies the Fares v2 matching rule used for networks, areas
+// and leg groups: rules naming the value win, and only when none do are the
+// rules that leave the field empty used.
+func matchByDefault[T any](rules []T, field func(T) string, values []string) []T {
+	var exact, empty []T
+	for _, r := range rules {
+		v := field(r)
+		if v == "" {
+			empty = append(empty, r)
+			continue
+		}
+		for _, want := range values {
+			if v == want {
+				exact = append(exact, r)
+				break
+			}
+		}
+	}
+	if len(exact) > 0 {
+		return exact
+	}
+	return empty
+}
+
+// resolveLeg finds the leg groups a leg can be priced under. Rules that
+// depend on timeframes are skipped since the feed ships no timeframes.txt.
+// When the feed assigns no stops to any area, area conditions can't be
+// evaluated and are ignored rather than ruling out every leg.
+func resolveLeg(leg FareLeg) ResolvedLeg {
+	r := ResolvedLeg{RouteId: leg.RouteId}
+	r.NetworkId, _ = findNetworkForRoute(leg.RouteId)
+	r.FromAreaIds = findAreasForStop(leg.FromStopId)
+	r.ToAreaIds = findAreasForStop(leg.ToStopId)
+
+	var rules []*protodata.FareLegRuleProto
+	for _, rule := range FareLegRules {
+		if rule.GetFromTimeframeGroupId() == "" && rule.GetToTimeframeGroupId() == "" {
+			rules = append(rules, rule)
+		}
+	}
+
+	rules = matchByDefault(rules, (*protodata.FareLegRuleProto).GetNetworkId, []string{r.NetworkId})
+	if len(StopAreas) > 0 {
+		rules = matchByDefault(rules, (*protodata.FareLegRuleProto).GetFromAreaId, r.FromAreaIds)
+		rules = matchByDefault(rules, (*protodata.FareLegRuleProto).GetToAreaId, r.ToAreaIds)
+	}
+
+	seen := make(map[fareChoice]bool)
+	groups := make(map[string]bool)
+	for _, rule := range rules {
+		c := fareChoice{legGroupId: rule.GetLegGroupId(), productId: rule.GetFareProductId()}
+		if seen[c] {
+			continue
+		}
+		seen[c] = true
+		r.choices = append(r.choices, c)
+		if !groups[c.legGroupId] {
+			groups[c.legGroupId] = true
+			r.LegGroupIds = append(r.LegGroupIds, c.legGroupId)
+		}
+	}
+	return r
+}
+
+// fareSearch finds the cheapest way to pay for a journey with one fare
+// media, trying every leg group for every leg and, between consecutive legs,
+// either a fresh fare or any transfer rule that applies.
+type fareSearch struct {
+	legs     []FareLeg
+	resolved []ResolvedLeg
+	mediaId  string
+	currency string
+
+	best      []FareLegQuote
+	bestTotal float64
+}
+
+type fareState struct {
+	prev fareChoice
+	// chainTotal is what the sub-journey since chainStart has cost so far
+	chainTotal float64
+	chainStart int
+	transfers  int
+	total      float64
+	steps      []FareLegQuote
+}
+
+func (s *fareSearch) price(productId string) (float64, string, bool) {
+	if productId == "" {
+		return 0, "", true
+	}
+	p, found := findFareProduct(productId, s.mediaId)
+	if !found {
+		return 0, "", false
+	}
+	if s.currency == "" {
+		s.currency = p.GetCurrency()
+	}
+	return p.GetAmount(), p.GetFareProductName(), true
+}
+
+func (s *fareSearch) walk(i int, st fareState) {
+	if i == len(s.legs) {
+		if s.best == nil || st.total < s.bestTotal-1e-9 {
+			s.best = append([]FareLegQuote(nil), st.steps...)
+			s.bestTotal = st.total
+		}
+		return
+	}
+
+	for _, c := range s.resolved[i].choices {
+		if amount, name, ok := s.price(c.productId); ok {
+			s.walk(i+1, fareState{
+				prev:       c,
+				chainTotal: amount,
+				chainStart: i,
+				total:      st.total + amount,
+				steps: append(st.steps[:i:i], FareLegQuote{
+					Leg: i, LegGroupId: c.legGroupId, FareProductId: c.productId, FareProductName: name, Amount: amount,
+				}),
+			})
+		}
+		if i == 0 {
+			continue
+		}
+
+		for _, rule := range s.transferRules(st.prev.legGroupId, c.legGroupId) {
+			if rule.GetFromLegGroupId() == rule.GetToLegGroupId() && rule.TransferCount != nil {
+				if limit := rule.GetTransferCount(); limit != -1 && st.transfers+1 > int(limit) {
+					continue
+				}
+			}
+			if !s.withinDuration(rule, st.chainStart, i) {
+				continue
+			}
+			ab, name, ok := s.price(rule.GetFareProductId())
+			if !ok {
+				continue
+			}
+
+			// 0: A + AB, 1: A + AB + B, 2: AB in place of the whole
+			// sub-journey so far
+			add := ab
+			switch rule.GetFareTransferType() {
+			case 1:
+				b, _, ok := s.price(c.productId)
+				if !ok {
+					continue
+				}
+				add = ab + b
+			case 2:
+				add = ab - st.chainTotal
+			}
+
+			s.walk(i+1, fareState{
+				prev:       c,
+				chainTotal: st.chainTotal + add,
+				chainStart: st.chainStart,
+				transfers:  st.transfers + 1,
+				total:      st.total + add,
+				steps: append(st.steps[:i:i], FareLegQuote{
+					Leg: i, LegGroupId: c.legGroupId, FareProductId: rule.GetFareProductId(), FareProductName: name,
+					Transfer: true, FareTransferType: rule.GetFareTransferType(), Amount: add,
+				}),
+			})
+		}
+	}
+}
+
+func (s *fareSearch) transferRules(from, to string) []*protodata.FareTransferRuleProto {
+	rules := matchByDefault(FareTransferRules, (*protodata.FareTransferRuleProto).GetFromLegGroupId, []string{from})
+	return matchByDefault(rules, (*protodata.FareTransferRuleProto).GetToLegGroupId, []string{to})
+}
+
+// withinDuration checks a transfer's duration limit. Limits starting at a
+// departure run from the first leg of the sub-journey, so a 3-hour pass
+// can't be stretched by chaining transfers; those starting at an arrival run
+// from the leg just ridden, as the spec measures them.
+func (s *fareSearch) withinDuration(rule *protodata.FareTransferRuleProto, start, next int) bool {
+	if rule.DurationLimit == nil {
+		return true
+	}
+	first, prev, leg := s.legs[start], s.legs[next-1], s.legs[next]
+
+	var from, to time.Time
+	switch rule.GetDurationLimitType() {
+	case 0:
+		from, to = first.DepartureTime, leg.ArrivalTime
+	case 1:
+		from, to = first.DepartureTime, leg.DepartureTime
+	case 2:
+		from, to = prev.ArrivalTime, leg.DepartureTime
+	default:
+		from, to = prev.ArrivalTime, leg.ArrivalTime
+	}
+	return to.Sub(from) <= time.Duration(rule.GetDurationLimit())*time.Second
+}
+
+func roundCents(v float64) float64 {
+	return math.Round(v*100) / 100
+}
+
+// QuoteFare prices a journey with every fare media, or only mediaId when
+// given. Media the journey can't be paid with are left out.
+func QuoteFare(legs []FareLeg, mediaId string) FareQuoteResponse {
+	resp := FareQuoteResponse{AreasResolved: len(StopAreas) > 0, Quotes: make([]FareQuote, 0)}
+	for _, leg := range legs {
+		resp.Legs = append(resp.Legs, resolveLeg(leg))
+	}
+
+	for _, media := range FareMedia {
+		if mediaId != "" && media.GetFareMediaId() != mediaId {
+			continue
+		}
+
+		s := &fareSearch{legs: legs, resolved: resp.Legs, mediaId: media.GetFareMediaId()}
+		s.walk(0, fareState{})
+		if s.best == nil {
+			continue
+		}
+
+		quote := FareQuote{
+			FareMediaId:   media.GetFareMediaId(),
+			FareMediaName: media.GetFareMediaName(),
+			Total:         roundCents(s.bestTotal),
+			Currency:      s.currency,
+			Legs:          s.best,
+		}
+		for i := range quote.Legs {
+			quote.Legs[i].Amount = roundCents(quote.Legs[i].Amount)
+		}
+		resp.Quotes = append(resp.Quotes, quote)
+	}
+
+	sort.SliceStable(resp.Quotes, func(i, j int) bool {
+		return resp.Quotes[i].Total < resp.Quotes[j].Total
+	})
+	return resp
+}
+
+func validateFareLegs(legs []FareLeg) error {
+	if len(legs) > maxFareLegs {
+		return fmt.Errorf("at most %d legs can be quoted", maxFareLegs)
+	}
+	for i := range legs {
+		leg := &legs[i]
+		if leg.ArrivalTime.IsZero() {
+			leg.ArrivalTime = leg.DepartureTime
+		}
+		if leg.ArrivalTime.Before(leg.DepartureTime) {
+			return fmt.Errorf("leg %d arrives before it departs", i)
+		}
+		if i > 0 && leg.DepartureTime.Before(legs[i-1].ArrivalTime) {
+			return fmt.Errorf("leg %d departs before leg %d arrives", i, i-1)
+		}
+	}
+	return nil
+}
+
+// POST /fares/quote
+func HandleFareQuote(c *gin.Context) {
+	var req FareQuoteRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "legs with route_id and departure_time are required"})
+		return
+	}
+	if err := validateFareLegs(req.Legs); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if req.FareMediaId != "" && !fareMediaExists(req.FareMediaId) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Unknown fare_media_id"})
+		return
+	}
+
+	c.JSON(http.StatusOK, QuoteFare(req.Legs, req.FareMediaId))
+}
+
+func fareMediaExists(id string) bool {
+	for _, m := range FareMedia {
+		if m.GetFareMediaId() == id {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,110 @@
+package server
+
+import (
+	"studious-waffle/server/protodata"
+	"testing"
+	"time"
+
+	"google.golang.org/protobuf/proto"
+)
+
+// useFareData swaps in a fare feed with one media, a $3.00 single ride in
+// leg group "g", a $5.00 "ab" pass and a $1.00 "xfer" add-on, for the
+// length of a test.
+func useFareData(t *testing.T, transfers ...*protodata.FareTransferRuleProto) {
+	t.Helper()
+	media, products, legRules, transferRules, stopAreas, routeNetworks := FareMedia, FareProducts, FareLegRules, FareTransferRules, StopAreas, RouteNetworks
+	t.Cleanup(func() {
+		FareMedia, FareProducts, FareLegRules, FareTransferRules, StopAreas, RouteNetworks = media, products, legRules, transferRules, stopAreas, routeNetworks
+	})
+
+	product := func(id string, amount float64) *protodata.FareProductProto {
+		return &protodata.FareProductProto{FareProductId: proto.String(id), Amount: proto.Float64(amount), Currency: proto.String("USD")}
+	}
+	FareMedia = []*protodata.FareMediaProto{{FareMediaId: proto.String("card")}}
+	FareProducts = []*protodata.FareProductProto{product("ab", 5), product("single", 3), product("xfer", 1)}
+	FareLegRules = []*protodata.FareLegRuleProto{{LegGroupId: proto.String("g"), FareProductId: proto.String("single")}}
+	FareTransferRules = transfers
+	StopAreas, RouteNetworks = nil, nil
+}
+
+// fareTransferRule is a g to g transfer; limit and limitType are left unset
+// when limit is 0.
+func fareTransferRule(fareType int32, productId string, count, limit, limitType int32) *protodata.FareTransferRuleProto {
+	r := &protodata.FareTransferRuleProto{
+		FromLegGroupId:   proto.String("g"),
+		ToLegGroupId:     proto.String("g"),
+		FareTransferType: proto.Int32(fareType),
+		FareProductId:    proto.String(productId),
+		TransferCount:    proto.Int32(count),
+	}
+	if limit > 0 {
+		r.DurationLimit = proto.Int32(limit)
+		r.DurationLimitType = proto.Int32(limitType)
+	}
+	return r
+}
+
+func TestQuoteFareTransferChains(t *testing.T) {
+	at := func(clock string) time.Time {
+		v, err := time.Parse("15:04", clock)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return v
+	}
+	// three rides, each leaving ten minutes after the last one arrives
+	legs := []FareLeg{
+		{RouteId: "r", DepartureTime: at("08:00"), ArrivalTime: at("08:50")},
+		{RouteId: "r", DepartureTime: at("09:00"), ArrivalTime: at("09:50")},
+		{RouteId: "r", DepartureTime: at("10:00"), ArrivalTime: at("10:30")},
+	}
+
+	tests := []struct {
+		name     string
+		rule     *protodata.FareTransferRuleProto
+		want     float64
+		transfer []bool
+	}{
+		{"no transfer rule", nil, 9, []bool{false, false, false}},
+		{"A plus AB", fareTransferRule(0, "xfer", -1, 0, 0), 5, []bool{false, true, true}},
+		{"A plus AB limited to one transfer", fareTransferRule(0, "xfer", 1, 0, 0), 7, nil},
+		{"A plus AB plus B costs more than paying again", fareTransferRule(1, "xfer", -1, 0, 0), 9, []bool{false, false, false}},
+		{"AB covers the whole chain", fareTransferRule(2, "ab", -1, 0, 0), 5, []bool{false, true, true}},
+		{"departure to arrival limit", fareTransferRule(2, "ab", -1, 20*60, 0), 9, []bool{false, false, false}},
+		{"departure to departure limit", fareTransferRule(2, "ab", -1, 90*60, 1), 8, nil},
+		{"arrival to departure limit runs from the last leg", fareTransferRule(2, "ab", -1, 20*60, 2), 5, []bool{false, true, true}},
+		{"arrival to arrival limit runs from the last leg", fareTransferRule(2, "ab", -1, 60*60, 3), 5, []bool{false, true, true}},
+		{"arrival to arrival limit too short", fareTransferRule(2, "ab", -1, 30*60, 3), 9, []bool{false, false, false}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var rules []*protodata.FareTransferRuleProto
+			if tt.rule != nil {
+				rules = append(rules, tt.rule)
+			}
+			useFareData(t, rules...)
+
+			resp := QuoteFare(legs, "")
+			if len(resp.Quotes) != 1 {
+				t.Fatalf("got %d quotes, want 1", len(resp.Quotes))
+			}
+			q := resp.Quotes[0]
+			if q.Total != tt.want {
+				t.Errorf("got total %.2f, want %.2f", q.Total, tt.want)
+			}
+			if tt.transfer == nil {
+				// more than one way to pay costs the same
+				return
+			}
+			if len(q.Legs) != len(tt.transfer) {
+				t.Fatalf("got %d priced legs, want %d", len(q.Legs), len(tt.transfer))
+			}
+			for i, l := range q.Legs {
+				if l.Transfer != tt.transfer[i] {
+					t.Errorf("leg %d: got transfer %v, want %v", i, l.Transfer, tt.transfer[i])
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,80 @@
+package server
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"studious-waffle/server/protodata"
+
+	"github.com/gin-gonic/gin"
+)
+
+// maxFeedDiffBytes caps a diff request, room for two large agencies' zips.
+const maxFeedDiffBytes = 256 << 20
+
+// servedFeed is the static data the server was built with, as a feed to
+// diff against.
+func servedFeed() *protodata.Feed {
+	return &protodata.Feed{
+		Routes:        Routes,
+		Stops:         Stops,
+		Trips:         Trips,
+		Shapes:        Shapes,
+		Calendars:     Calendars,
+		CalendarDates: CalendarDates,
+	}
+}
+
+// uploadedFeed reads a GTFS zip sent in a multipart form field.
+func uploadedFeed(c *gin.Context, field string) (*protodata.Feed, error) {
+	header, err := c.FormFile(field)
+	if err != nil {
+		return nil, err
+	}
+	f, err := header.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	feed, err := protodata.ReadFeedZip(f, header.Size)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", field, err)
+	}
+	return feed, nil
+}
+
+// uploadError answers a request whose zip in field couldn't be read.
+func uploadError(c *gin.Context, field string, err error) {
+	var tooLarge *http.MaxBytesError
+	if errors.As(err, &tooLarge) {
+		c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": fmt.Sprintf("the feeds can be at most %d MB together", maxFeedDiffBytes>>20)})
+		return
+	}
+	c.JSON(http.StatusBadRequest, gin.H{"error": field + " must be a GTFS zip file: " + err.Error()})
+}
+
+// POST /admin/feeds/diff?format=text with a GTFS zip in the "feed" form
+// field, compared with the zip in "base" or, without one, the data being
+// served.
+func HandleFeedDiff(c *gin.Context) {
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxFeedDiffBytes)
+	to, err := uploadedFeed(c, "feed")
+	if err != nil {
+		uploadError(c, "feed", err)
+		return
+	}
+	from := servedFeed()
+	if _, err := c.FormFile("base"); err == nil {
+		if from, err = uploadedFeed(c, "base"); err != nil {
+			uploadError(c, "base", err)
+			return
+		}
+	}
+
+	diff := protodata.DiffFeeds(from, to)
+	if c.Query("format") == "text" {
+		c.String(http.StatusOK, diff.Summary())
+		return
+	}
+	c.JSON(http.StatusOK, diff)
+}
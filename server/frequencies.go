@@ -0,0 +1,132 @@
+package server
+
+import (
+	"cmp"
+	"net/http"
+	"slices"
+	"sort"
+	"studious-waffle/server/protodata"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Frequencies (Search by TripID)
+func findFrequenciesByTripID(tripId string) []*protodata.FrequencyProto {
+	data := Frequencies
+	n := len(data)
+
+	idx := sort.Search(n, func(i int) bool {
+		return data[i].GetTripId() >= tripId
+	})
+
+	end := idx
+	for end < n && data[end].GetTripId() == tripId {
+		end++
+	}
+	return data[idx:end]
+}
+
+// tripInstance is one run of a trip. Frequency-based trips run many times a
+// day from one set of template stop times, each run shifted by Shift
+// seconds. HeadwaySecs is set for runs of exact_times=0 trips, whose times
+// are only an estimate of a service running every HeadwaySecs.
+type tripInstance struct {
+	Shift       int
+	HeadwaySecs int
+}
+
+type frequencyWindow struct {
+	start, end, headway int
+	exact               bool
+}
+
+func frequencyWindows(tripId string) []frequencyWindow {
+	var windows []frequencyWindow
+	for _, f := range findFrequenciesByTripID(tripId) {
+		start, ok1 := parseGTFSTime(f.GetStartTime())
+		end, ok2 := parseGTFSTime(f.GetEndTime())
+		if !ok1 || !ok2 || f.GetHeadwaySecs() <= 0 {
+			continue
+		}
+		windows = append(windows, frequencyWindow{
+			start:   start,
+			end:     end,
+			headway: int(f.GetHeadwaySecs()),
+			exact:   f.GetExactTimes() == 1,
+		})
+	}
+	slices.SortFunc(windows, func(a, b frequencyWindow) int {
+		return cmp.Compare(a.start, b.start)
+	})
+	return windows
+}
+
+// tripInstances expands a trip into its runs: a single unshifted run for an
+// ordinary trip, or one run per headway inside each frequencies.txt window,
+// starting at start_time and while before end_time.
+func tripInstances(tripId string, stopTimes []*protodata.StopTimeProto) []tripInstance {
+	windows := frequencyWindows(tripId)
+	if len(windows) == 0 {
+		return []tripInstance{{}}
+	}
+	if len(stopTimes) == 0 {
+		return nil
+	}
+	_, base, ok := stopTimeSeconds(stopTimes[0])
+	if !ok {
+		return nil
+	}
+
+	var instances []tripInstance
+	for _, w := range windows {
+		headway := 0
+		if !w.exact {
+			headway = w.headway
+		}
+		for t := w.start; t < w.end; t += w.headway {
+			instances = append(instances, tripInstance{Shift: t - base, HeadwaySecs: headway})
+		}
+	}
+	return instances
+}
+
+// HeadwayDescriptor is a window of an exact_times=0 trip: service every
+// HeadwaySecs with no published times.
+type HeadwayDescriptor struct {
+	StartTime   string `json:"start_time"`
+	EndTime     string `json:"end_time"`
+	HeadwaySecs int    `json:"headway_secs"`
+}
+
+type FrequencyTrip struct {
+	TripId string `json:"trip_id"`
+	// start times of exact_times=1 runs
+	Departures []string            `json:"departures"`
+	Headways   []HeadwayDescriptor `json:"headways"`
+}
+
+// GET /frequencies/trip/:trip_id
+func HandleFrequenciesByTripId(c *gin.Context) {
+	tripId := c.Param("trip_id")
+	windows := frequencyWindows(tripId)
+	if len(windows) == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Trip is not frequency-based"})
+		return
+	}
+
+	result := FrequencyTrip{TripId: tripId, Departures: make([]string, 0), Headways: make([]HeadwayDescriptor, 0)}
+	for _, w := range windows {
+		if !w.exact {
+			result.Headways = append(result.Headways, HeadwayDescriptor{
+				StartTime:   formatGTFSTime(w.start),
+				EndTime:     formatGTFSTime(w.end),
+				HeadwaySecs: w.headway,
+			})
+			continue
+		}
+		for t := w.start; t < w.end; t += w.headway {
+			result.Departures = append(result.Departures, formatGTFSTime(t))
+		}
+	}
+	c.JSON(http.StatusOK, result)
+}
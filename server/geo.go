@@ -0,0 +1,60 @@
+package server
+
+import "math"
+
+const earthRadiusMeters = 6371008.8
+
+// walkingSpeed turns walking distances into times, a little under the usual
+// 1.4 m/s to allow for crossings and riders with luggage.
+const walkingSpeed = 1.2 // m/s
+
+// walkSeconds is how long walking a distance takes.
+func walkSeconds(meters float64) int {
+	return int(math.Ceil(meters / walkingSpeed))
+}
+
+// haversineMeters is the great-circle distance between two coordinates.
+func haversineMeters(lat1, lon1, lat2, lon2 float64) float64 {
+	p1 := lat1 * math.Pi / 180
+	p2 := lat2 * math.Pi / 180
+	dp := (lat2 - lat1) * math.Pi / 180
+	dl := (lon2 - lon1) * math.Pi / 180
+
+	a := math.Sin(dp/2)*math.Sin(dp/2) + math.Cos(p1)*math.Cos(p2)*math.Sin(dl/2)*math.Sin(dl/2)
+	return 2 * earthRadiusMeters * math.Asin(math.Min(1, math.Sqrt(a)))
+}
+
+// localPlane projects coordinates onto a flat plane in meters around an
+// origin. Over the few kilometres of a shape segment the error is far below
+// GPS noise, and it keeps point-to-segment math simple.
+type localPlane struct {
+	lat0, lon0 float64
+	kx, ky     float64
+}
+
+func newLocalPlane(lat, lon float64) localPlane {
+	ky := earthRadiusMeters * math.Pi / 180
+	return localPlane{lat0: lat, lon0: lon, kx: ky * math.Cos(lat*math.Pi/180), ky: ky}
+}
+
+func (p localPlane) xy(lat, lon float64) (float64, float64) {
+	return (lon - p.lon0) * p.kx, (lat - p.lat0) * p.ky
+}
+
+func (p localPlane) latLon(x, y float64) (float64, float64) {
+	return p.lat0 + y/p.ky, p.lon0 + x/p.kx
+}
+
+// projectOnSegment returns the fraction t in [0,1] along a->b closest to p
+// and the distance from p to that point.
+func projectOnSegment(px, py, ax, ay, bx, by float64) (float64, float64) {
+	dx, dy := bx-ax, by-ay
+	lenSq := dx*dx + dy*dy
+	t := 0.0
+	if lenSq > 0 {
+		t = ((px-ax)*dx + (py-ay)*dy) / lenSq
+		t = math.Max(0, math.Min(1, t))
+	}
+	cx, cy := ax+t*dx, ay+t*dy
+	return t, math.Hypot(px-cx, py-cy)
+}
@@ -0,0 +1,326 @@
+package server
+
+import (
+	"cmp"
+	"net/http"
+	"slices"
+	"studious-waffle/server/protodata"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	defaultGhostGraceMinutes = 5
+	defaultStuckMinutes      = 10
+	// a vehicle that stays within stuckRadiusMeters of where it was first
+	// seen has not moved
+	stuckRadiusMeters = 50.0
+)
+
+// MissingTrip is a scheduled run that should be under way but that no trip
+// update or vehicle position mentions.
+type MissingTrip struct {
+	TripId            string `json:"trip_id"`
+	RouteId           string `json:"route_id"`
+	DirectionId       int32  `json:"direction_id"`
+	TripHeadsign      string `json:"trip_headsign,omitempty"`
+	StartDate         string `json:"start_date"`
+	ScheduledStart    string `json:"scheduled_start"`
+	ScheduledEnd      string `json:"scheduled_end"`
+	MinutesSinceStart int    `json:"minutes_since_start"`
+}
+
+// StuckVehicle is a vehicle on a trip that has not moved for a while.
+type StuckVehicle struct {
+	VehicleId    string    `json:"vehicle_id"`
+	TripId       string    `json:"trip_id"`
+	RouteId      string    `json:"route_id"`
+	Lat          float64   `json:"lat"`
+	Lon          float64   `json:"lon"`
+	StuckSince   time.Time `json:"stuck_since"`
+	StuckMinutes int       `json:"stuck_minutes"`
+}
+
+// UnknownTripVehicle is a vehicle reporting a trip_id the static feed does
+// not have.
+type UnknownTripVehicle struct {
+	VehicleId string  `json:"vehicle_id"`
+	TripId    string  `json:"trip_id"`
+	RouteId   string  `json:"route_id,omitempty"`
+	Lat       float64 `json:"lat"`
+	Lon       float64 `json:"lon"`
+	Timestamp int64   `json:"timestamp"`
+}
+
+type GhostReport struct {
+	CheckedAt     time.Time            `json:"checked_at"`
+	MissingTrips  []MissingTrip        `json:"missing_trips"`
+	StuckVehicles []StuckVehicle       `json:"stuck_vehicles"`
+	UnknownTrips  []UnknownTripVehicle `json:"unknown_trips"`
+}
+
+// scheduledRun is one run of a trip on a service date, in seconds of that
+// service day.
+type scheduledRun struct {
+	trip       *protodata.TripProto
+	start, end int
+	frequency  bool
+}
+
+// vehicleStill is where a vehicle was first seen since it last moved.
+type vehicleStill struct {
+	tripId   string
+	lat, lon float64
+	since    time.Time
+}
+
+// GhostDetector compares each realtime poll with the schedule, looking for
+// trips that should be running but aren't, vehicles that stopped moving,
+// and vehicles on trips the schedule doesn't know.
+type GhostDetector struct {
+	mu         sync.Mutex
+	grace      time.Duration
+	stuckAfter time.Duration
+	// runs caches the scheduled runs of the service dates last checked
+	runs   map[string][]scheduledRun
+	still  map[string]vehicleStill
+	report GhostReport
+}
+
+func NewGhostDetector(grace, stuckAfter time.Duration) *GhostDetector {
+	return &GhostDetector{
+		grace:      grace,
+		stuckAfter: stuckAfter,
+		runs:       make(map[string][]scheduledRun),
+		still:      make(map[string]vehicleStill),
+		report: GhostReport{
+			MissingTrips:  make([]MissingTrip, 0),
+			StuckVehicles: make([]StuckVehicle, 0),
+			UnknownTrips:  make([]UnknownTripVehicle, 0),
+		},
+	}
+}
+
+var ghostDetector = NewGhostDetector(defaultGhostGraceMinutes*time.Minute, defaultStuckMinutes*time.Minute)
+
+// startGhosts has the detector check every realtime poll, with the
+// configured grace before a trip is missing and time before a vehicle is
+// stuck.
+func startGhosts() {
+	grace := time.Duration(config.Analytics.GhostGraceMinutes) * time.Minute
+	stuckAfter := time.Duration(config.Analytics.StuckMinutes) * time.Minute
+	ghostDetector = NewGhostDetector(grace, stuckAfter)
+	OnRealtimeUpdate(ghostDetector.Observe)
+}
+
+// scheduledRuns returns the runs of every trip operating on a service date,
+// by start time.
+func (d *GhostDetector) scheduledRuns(date string) []scheduledRun {
+	if runs, found := d.runs[date]; found {
+		return runs
+	}
+	runs := make([]scheduledRun, 0)
+	for _, trip := range Trips {
+		if !serviceRunsOn(trip.GetServiceId(), date) {
+			continue
+		}
+		stopTimes, found := findStopTimesByTripID(trip.GetTripId())
+		if !found {
+			continue
+		}
+		_, start, ok := stopTimeSeconds(stopTimes[0])
+		if !ok {
+			continue
+		}
+		end, _, ok := stopTimeSeconds(stopTimes[len(stopTimes)-1])
+		if !ok {
+			continue
+		}
+		for _, run := range tripInstances(trip.GetTripId(), stopTimes) {
+			runs = append(runs, scheduledRun{trip: trip, start: start + run.Shift, end: end + run.Shift, frequency: run.HeadwaySecs > 0})
+		}
+	}
+	slices.SortFunc(runs, func(a, b scheduledRun) int {
+		if c := cmp.Compare(a.start, b.start); c != 0 {
+			return c
+		}
+		return naturalCompare(a.trip.GetTripId(), b.trip.GetTripId())
+	})
+	d.runs[date] = runs
+	return runs
+}
+
+// missingTrips lists the runs under way at a time, past the grace period,
+// that realtime says nothing about. Yesterday's service day is checked too,
+// for trips running past midnight. A frequency-based trip is only missing
+// when none of its runs appear, since updates don't say which run they mean.
+func (d *GhostDetector) missingTrips(now time.Time, seen map[tripRun]bool, seenTrips map[string]bool) []MissingTrip {
+	today := serviceDayStart(now)
+	days := []time.Time{serviceDayStart(today.Add(-12 * time.Hour)), today}
+	dates := make(map[string]bool)
+	missing := make([]MissingTrip, 0)
+	for _, dayStart := range days {
+		date := serviceDate(dayStart)
+		dates[date] = true
+		elapsed := int(now.Sub(dayStart).Seconds())
+		for _, run := range d.scheduledRuns(date) {
+			if run.start > elapsed-int(d.grace.Seconds()) {
+				break
+			}
+			tripId := run.trip.GetTripId()
+			if run.end <= elapsed || seen[tripRun{tripId: tripId, date: date}] || run.frequency && seenTrips[tripId] {
+				continue
+			}
+			missing = append(missing, MissingTrip{
+				TripId:            tripId,
+				RouteId:           run.trip.GetRouteId(),
+				DirectionId:       run.trip.GetDirectionId(),
+				TripHeadsign:      run.trip.GetTripHeadsign(),
+				StartDate:         date,
+				ScheduledStart:    formatGTFSTime(run.start),
+				ScheduledEnd:      formatGTFSTime(run.end),
+				MinutesSinceStart: (elapsed - run.start) / 60,
+			})
+		}
+	}
+	for date := range d.runs {
+		if !dates[date] {
+			delete(d.runs, date)
+		}
+	}
+	return missing
+}
+
+// Observe checks a poll. Vehicles at either end of their trip are laying
+// over, not stuck, and are left out of the stuck vehicle check.
+func (d *GhostDetector) Observe(snap *RealtimeSnapshot) {
+	now := snap.FetchedAt
+	seen := make(map[tripRun]bool)
+	seenTrips := make(map[string]bool)
+	for run := range realtimeTripRuns(snap) {
+		seen[run] = true
+		seenTrips[run.tripId] = true
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	report := GhostReport{
+		CheckedAt:     now,
+		StuckVehicles: make([]StuckVehicle, 0),
+		UnknownTrips:  make([]UnknownTripVehicle, 0),
+	}
+	still := make(map[string]vehicleStill)
+	for _, e := range snap.Vehicles {
+		v := e.GetVehicle()
+		tripId, vehicleId := v.GetTrip().GetTripId(), v.GetVehicle().GetId()
+		if tripId == "" {
+			continue
+		}
+		lat, lon := v.GetPosition().GetLatitude(), v.GetPosition().GetLongitude()
+
+		trip, found := findTripByID(tripId)
+		if !found {
+			switch v.GetTrip().GetScheduleRelationship() {
+			case TripAdded, TripUnscheduled:
+			default:
+				report.UnknownTrips = append(report.UnknownTrips, UnknownTripVehicle{
+					VehicleId: vehicleId,
+					TripId:    tripId,
+					RouteId:   v.GetTrip().GetRouteId(),
+					Lat:       lat,
+					Lon:       lon,
+					Timestamp: v.GetTimestamp(),
+				})
+			}
+			continue
+		}
+
+		stopTimes, _ := findStopTimesByTripID(tripId)
+		seen[tripRun{tripId: tripId, date: runDate(v.GetTrip().GetStartDate(), stopTimes, now)}] = true
+		seenTrips[tripId] = true
+
+		if vehicleId == "" {
+			continue
+		}
+		if progress, err := SnapVehicle(v, offRouteThresholdMeters); err == nil && (progress.PercentComplete <= 0 || progress.PercentComplete >= 100) {
+			continue
+		}
+		at := now
+		if ts := v.GetTimestamp(); ts > 0 {
+			at = time.Unix(ts, 0).In(agencyLocation)
+		}
+		s, found := d.still[vehicleId]
+		if !found || s.tripId != tripId || haversineMeters(s.lat, s.lon, lat, lon) > stuckRadiusMeters {
+			s = vehicleStill{tripId: tripId, lat: lat, lon: lon, since: at}
+		}
+		still[vehicleId] = s
+		if stuck := at.Sub(s.since); stuck >= d.stuckAfter {
+			report.StuckVehicles = append(report.StuckVehicles, StuckVehicle{
+				VehicleId:    vehicleId,
+				TripId:       tripId,
+				RouteId:      trip.GetRouteId(),
+				Lat:          lat,
+				Lon:          lon,
+				StuckSince:   s.since,
+				StuckMinutes: int(stuck.Minutes()),
+			})
+		}
+	}
+	d.still = still
+
+	report.MissingTrips = d.missingTrips(now, seen, seenTrips)
+	slices.SortFunc(report.StuckVehicles, func(a, b StuckVehicle) int {
+		return naturalCompare(a.VehicleId, b.VehicleId)
+	})
+	slices.SortFunc(report.UnknownTrips, func(a, b UnknownTripVehicle) int {
+		return naturalCompare(a.VehicleId, b.VehicleId)
+	})
+	d.report = report
+}
+
+// GET /ghosts?type=missing_trips|stuck_vehicles|unknown_trips&route_id=
+func HandleGhosts(c *gin.Context) {
+	kind, routeId := c.Query("type"), c.Query("route_id")
+	switch kind {
+	case "", "missing_trips", "stuck_vehicles", "unknown_trips":
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "type must be missing_trips, stuck_vehicles or unknown_trips"})
+		return
+	}
+
+	ghostDetector.mu.Lock()
+	report := ghostDetector.report
+	ghostDetector.mu.Unlock()
+
+	filtered := GhostReport{
+		CheckedAt:     report.CheckedAt,
+		MissingTrips:  make([]MissingTrip, 0),
+		StuckVehicles: make([]StuckVehicle, 0),
+		UnknownTrips:  make([]UnknownTripVehicle, 0),
+	}
+	if kind == "" || kind == "missing_trips" {
+		for _, m := range report.MissingTrips {
+			if routeId == "" || m.RouteId == routeId {
+				filtered.MissingTrips = append(filtered.MissingTrips, m)
+			}
+		}
+	}
+	if kind == "" || kind == "stuck_vehicles" {
+		for _, s := range report.StuckVehicles {
+			if routeId == "" || s.RouteId == routeId {
+				filtered.StuckVehicles = append(filtered.StuckVehicles, s)
+			}
+		}
+	}
+	if kind == "" || kind == "unknown_trips" {
+		for _, u := range report.UnknownTrips {
+			if routeId == "" || u.RouteId == routeId {
+				filtered.UnknownTrips = append(filtered.UnknownTrips, u)
+			}
+		}
+	}
+	c.JSON(http.StatusOK, filtered)
+}
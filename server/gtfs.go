@@ -12,12 +12,8 @@ import (
 	"google.golang.org/protobuf/proto"
 )
 
-const rtdAlerts = "https://www.rtd-denver.com/files/gtfs-rt/Alerts.pb"
-const rtdTripUpdates = "https://www.rtd-denver.com/files/gtfs-rt/TripUpdate.pb"
-const rtdVehiclePosition = "https://www.rtd-denver.com/files/gtfs-rt/VehiclePosition.pb"
-
 func fetchRawGTFS(url string) (*gtfs.FeedMessage, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	ctx, cancel := context.WithTimeout(context.Background(), config.Feeds.Timeout)
 	defer cancel()
 
 	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
@@ -40,11 +36,12 @@ func fetchRawGTFS(url string) (*gtfs.FeedMessage, error) {
 	if err := proto.Unmarshal(data, feed); err != nil {
 		return nil, err
 	}
+	realtimeValidator.Validate(url, feed, time.Now())
 	return feed, nil
 }
 
 func FetchAlerts() ([]*protodata.AlertEntityProto, error) {
-    rawFeed, err := fetchRawGTFS(rtdAlerts)
+    rawFeed, err := fetchRawGTFS(config.Feeds.AlertsURL)
     if err != nil {
         return nil, err
     }
@@ -108,7 +105,7 @@ func mapTranslations(rawText *gtfs.TranslatedString) []*protodata.TranslationPro
 }
 
 func FetchTripUpdates() ([]*protodata.TripUpdateEntityProto, error) {
-    rawFeed, err := fetchRawGTFS(rtdTripUpdates)
+    rawFeed, err := fetchRawGTFS(config.Feeds.TripUpdatesURL)
     if err != nil {
         return nil, err
     }
@@ -127,10 +124,14 @@ func FetchTripUpdates() ([]*protodata.TripUpdateEntityProto, error) {
                 StopSequence: proto.Int32(int32(stu.GetStopSequence())),
                 StopId:       proto.String(stu.GetStopId()),
                 Arrival: &protodata.StopTimeEventProto{
-                    Time: proto.Int64(int64(stu.GetArrival().GetTime())),
+                    Time:        proto.Int64(int64(stu.GetArrival().GetTime())),
+                    Delay:       proto.Int32(stu.GetArrival().GetDelay()),
+                    Uncertainty: proto.Int32(stu.GetArrival().GetUncertainty()),
                 },
                 Departure: &protodata.StopTimeEventProto{
-                    Time: proto.Int64(int64(stu.GetDeparture().GetTime())),
+                    Time:        proto.Int64(int64(stu.GetDeparture().GetTime())),
+                    Delay:       proto.Int32(stu.GetDeparture().GetDelay()),
+                    Uncertainty: proto.Int32(stu.GetDeparture().GetUncertainty()),
                 },
                 ScheduleRelationship: proto.Int32(int32(stu.GetScheduleRelationship())),
             })
@@ -139,18 +140,14 @@ func FetchTripUpdates() ([]*protodata.TripUpdateEntityProto, error) {
         results = append(results, &protodata.TripUpdateEntityProto{
             Id: proto.String(entity.GetId()),
             TripUpdate: &protodata.TripUpdateProto{
-                Trip: &protodata.TripDescriptorProto{
-                    TripId:               proto.String(tu.GetTrip().GetTripId()),
-                    RouteId:              proto.String(tu.GetTrip().GetRouteId()),
-                    DirectionId:          proto.Int32(int32(tu.GetTrip().GetDirectionId())),
-                    ScheduleRelationship: proto.Int32(int32(tu.GetTrip().GetScheduleRelationship())),
-                },
+                Trip: tripDescriptorFromRT(tu.GetTrip()),
                 Vehicle: &protodata.VehicleDescriptorProto{
                     Id:    proto.String(tu.GetVehicle().GetId()),
                     Label: proto.String(tu.GetVehicle().GetLabel()),
                 },
                 StopTimeUpdate: stopUpdates,
                 Timestamp:      proto.Int64(int64(tu.GetTimestamp())),
+                Source:         proto.Int32(PredictionSourceFeed),
             },
         })
     }
@@ -158,7 +155,7 @@ func FetchTripUpdates() ([]*protodata.TripUpdateEntityProto, error) {
 }
 
 func FetchVehiclePositions() ([]*protodata.VehiclePositionEntityProto, error) {
-	rawFeed, err := fetchRawGTFS(rtdVehiclePosition)
+	rawFeed, err := fetchRawGTFS(config.Feeds.VehiclePositionsURL)
 	if err != nil {
 		return nil, err
 	}
@@ -171,34 +168,52 @@ func FetchVehiclePositions() ([]*protodata.VehiclePositionEntityProto, error) {
 			continue
 		}
 
-		results = append(results, &protodata.VehiclePositionEntityProto{
-			Id: proto.String(entity.GetId()),
-			Vehicle: &protodata.VehiclePositionProto{
-				Trip: &protodata.TripDescriptorProto{
-					TripId:               proto.String(v.GetTrip().GetTripId()),
-					RouteId:              proto.String(v.GetTrip().GetRouteId()),
-					DirectionId:          proto.Int32(int32(v.GetTrip().GetDirectionId())),
-					ScheduleRelationship: proto.Int32(int32(v.GetTrip().GetScheduleRelationship())),
-				},
-				Vehicle: &protodata.VehicleDescriptorProto{
-					Id:    proto.String(v.GetVehicle().GetId()),
-					Label: proto.String(v.GetVehicle().GetLabel()),
-				},
-				Position: &protodata.GeoPositionProto{
-					Latitude:  proto.Float64(float64(v.GetPosition().GetLatitude())),
-					Longitude: proto.Float64(float64(v.GetPosition().GetLongitude())),
-					Bearing:   proto.Float64(float64(v.GetPosition().GetBearing())),
-				},
-				StopId:          proto.String(v.GetStopId()),
-				CurrentStatus:   proto.Int32(int32(v.GetCurrentStatus())),
-				Timestamp:       proto.Int64(int64(v.GetTimestamp())),
-				OccupancyStatus: proto.Int32(int32(v.GetOccupancyStatus())),
+		vehicle := &protodata.VehiclePositionProto{
+			Trip: tripDescriptorFromRT(v.GetTrip()),
+			Vehicle: &protodata.VehicleDescriptorProto{
+				Id:    proto.String(v.GetVehicle().GetId()),
+				Label: proto.String(v.GetVehicle().GetLabel()),
+			},
+			Position: &protodata.GeoPositionProto{
+				Latitude:  proto.Float64(float64(v.GetPosition().GetLatitude())),
+				Longitude: proto.Float64(float64(v.GetPosition().GetLongitude())),
 			},
+			StopId:        proto.String(v.GetStopId()),
+			CurrentStatus: proto.Int32(int32(v.GetCurrentStatus())),
+			Timestamp:     proto.Int64(int64(v.GetTimestamp())),
+		}
+		// bearing and occupancy stay unset when the feed leaves them out,
+		// rather than reading as north and empty
+		if v.GetPosition().Bearing != nil {
+			vehicle.Position.Bearing = proto.Float64(float64(v.GetPosition().GetBearing()))
+		}
+		if v.OccupancyStatus != nil {
+			vehicle.OccupancyStatus = proto.Int32(int32(v.GetOccupancyStatus()))
+		}
+
+		results = append(results, &protodata.VehiclePositionEntityProto{
+			Id:      proto.String(entity.GetId()),
+			Vehicle: vehicle,
 		})
 	}
 	return results, nil
 }
 
+// tripDescriptorFromRT keeps direction_id unset when the feed leaves it out,
+// so enrichTrip can take it from trips.txt.
+func tripDescriptorFromRT(td *gtfs.TripDescriptor) *protodata.TripDescriptorProto {
+	out := &protodata.TripDescriptorProto{
+		TripId:               proto.String(td.GetTripId()),
+		RouteId:              proto.String(td.GetRouteId()),
+		ScheduleRelationship: proto.Int32(int32(td.GetScheduleRelationship())),
+		StartDate:            proto.String(td.GetStartDate()),
+	}
+	if td.DirectionId != nil {
+		out.DirectionId = proto.Int32(int32(td.GetDirectionId()))
+	}
+	return out
+}
+
 // Routes
 func findRouteByID(routeId string) (*protodata.RouteProto, bool) {
     data := Routes
@@ -209,7 +224,7 @@ func findRouteByID(routeId string) (*protodata.RouteProto, bool) {
         return data[i].GetRouteId() >= routeId
     })
 
-    if idx < n && data[i].GetRouteId() == routeId {
+    if idx < n && data[idx].GetRouteId() == routeId {
         return data[idx], true
     }
     return nil, false
@@ -0,0 +1,298 @@
+package server
+
+import (
+	"net/http"
+	"studious-waffle/server/protodata"
+	"time"
+
+	"github.com/MobilityData/gtfs-realtime-bindings/golang/gtfs"
+	"github.com/gin-gonic/gin"
+	"google.golang.org/protobuf/encoding/prototext"
+	"google.golang.org/protobuf/proto"
+)
+
+const gtfsRealtimeVersion = "2.0"
+
+// enrichTrip returns a copy of td with the fields RTD leaves out filled in
+// from static data: the route and direction from trips.txt and the service
+// date the trip runs on.
+func enrichTrip(td *protodata.TripDescriptorProto, observed int64) *protodata.TripDescriptorProto {
+	out := proto.Clone(td).(*protodata.TripDescriptorProto)
+	if out == nil {
+		out = &protodata.TripDescriptorProto{}
+	}
+
+	tripId := out.GetTripId()
+	if trip, found := findTripByID(tripId); found {
+		if out.GetRouteId() == "" {
+			out.RouteId = proto.String(trip.GetRouteId())
+		}
+		if out.DirectionId == nil {
+			out.DirectionId = proto.Int32(trip.GetDirectionId())
+		}
+	}
+	if out.GetStartDate() == "" && tripId != "" {
+		if stopTimes, found := findStopTimesByTripID(tripId); found {
+			at := time.Now()
+			if observed > 0 {
+				at = time.Unix(observed, 0)
+			}
+			out.StartDate = proto.String(serviceDate(resolveServiceDay(stopTimes, at)))
+		}
+	}
+	return out
+}
+
+func newFeedMessage(fetchedAt time.Time, entities []*gtfs.FeedEntity) *gtfs.FeedMessage {
+	return &gtfs.FeedMessage{
+		Header: &gtfs.FeedHeader{
+			GtfsRealtimeVersion: proto.String(gtfsRealtimeVersion),
+			Incrementality:      gtfs.FeedHeader_FULL_DATASET.Enum(),
+			Timestamp:           proto.Uint64(uint64(fetchedAt.Unix())),
+		},
+		Entity: entities,
+	}
+}
+
+func tripDescriptorToRT(td *protodata.TripDescriptorProto) *gtfs.TripDescriptor {
+	rt := &gtfs.TripDescriptor{
+		ScheduleRelationship: gtfs.TripDescriptor_ScheduleRelationship(td.GetScheduleRelationship()).Enum(),
+	}
+	if td.GetTripId() != "" {
+		rt.TripId = proto.String(td.GetTripId())
+	}
+	if td.GetRouteId() != "" {
+		rt.RouteId = proto.String(td.GetRouteId())
+		if td.DirectionId != nil {
+			rt.DirectionId = proto.Uint32(uint32(td.GetDirectionId()))
+		}
+	}
+	if td.GetStartDate() != "" {
+		rt.StartDate = proto.String(td.GetStartDate())
+	}
+	return rt
+}
+
+func vehicleDescriptorToRT(vd *protodata.VehicleDescriptorProto) *gtfs.VehicleDescriptor {
+	if vd.GetId() == "" && vd.GetLabel() == "" {
+		return nil
+	}
+	rt := &gtfs.VehicleDescriptor{}
+	if vd.GetId() != "" {
+		rt.Id = proto.String(vd.GetId())
+	}
+	if vd.GetLabel() != "" {
+		rt.Label = proto.String(vd.GetLabel())
+	}
+	return rt
+}
+
+// stopTimeEventToRT leaves out unset values: our protos store 0 for
+// "missing", which GTFS-rt consumers would read as the epoch.
+func stopTimeEventToRT(ev *protodata.StopTimeEventProto) *gtfs.TripUpdate_StopTimeEvent {
+	if ev.GetTime() == 0 {
+		return nil
+	}
+	rt := &gtfs.TripUpdate_StopTimeEvent{Time: proto.Int64(ev.GetTime())}
+	if ev.GetDelay() != 0 {
+		rt.Delay = proto.Int32(ev.GetDelay())
+	}
+	if ev.GetUncertainty() > 0 {
+		rt.Uncertainty = proto.Int32(ev.GetUncertainty())
+	}
+	return rt
+}
+
+func tripUpdateToRT(e *protodata.TripUpdateEntityProto) *gtfs.FeedEntity {
+	tu := e.GetTripUpdate()
+
+	var updates []*gtfs.TripUpdate_StopTimeUpdate
+	for _, stu := range tu.GetStopTimeUpdate() {
+		rt := &gtfs.TripUpdate_StopTimeUpdate{
+			Arrival:              stopTimeEventToRT(stu.GetArrival()),
+			Departure:            stopTimeEventToRT(stu.GetDeparture()),
+			ScheduleRelationship: gtfs.TripUpdate_StopTimeUpdate_ScheduleRelationship(stu.GetScheduleRelationship()).Enum(),
+		}
+		if stu.GetStopSequence() > 0 {
+			rt.StopSequence = proto.Uint32(uint32(stu.GetStopSequence()))
+		}
+		if stu.GetStopId() != "" {
+			rt.StopId = proto.String(stu.GetStopId())
+		}
+		updates = append(updates, rt)
+	}
+
+	rt := &gtfs.TripUpdate{
+		Trip:           tripDescriptorToRT(enrichTrip(tu.GetTrip(), tu.GetTimestamp())),
+		Vehicle:        vehicleDescriptorToRT(tu.GetVehicle()),
+		StopTimeUpdate: updates,
+	}
+	if tu.GetTimestamp() > 0 {
+		rt.Timestamp = proto.Uint64(uint64(tu.GetTimestamp()))
+	}
+	return &gtfs.FeedEntity{Id: proto.String(e.GetId()), TripUpdate: rt}
+}
+
+func vehiclePositionToRT(e *protodata.VehiclePositionEntityProto) *gtfs.FeedEntity {
+	v := e.GetVehicle()
+	rt := &gtfs.VehiclePosition{
+		Vehicle: vehicleDescriptorToRT(v.GetVehicle()),
+		Position: &gtfs.Position{
+			Latitude:  proto.Float32(float32(v.GetPosition().GetLatitude())),
+			Longitude: proto.Float32(float32(v.GetPosition().GetLongitude())),
+		},
+		CurrentStatus: gtfs.VehiclePosition_VehicleStopStatus(v.GetCurrentStatus()).Enum(),
+	}
+	if v.GetPosition().Bearing != nil {
+		rt.Position.Bearing = proto.Float32(float32(v.GetPosition().GetBearing()))
+	}
+	if v.OccupancyStatus != nil {
+		rt.OccupancyStatus = gtfs.VehiclePosition_OccupancyStatus(v.GetOccupancyStatus()).Enum()
+	}
+	if v.GetTrip().GetTripId() != "" || v.GetTrip().GetRouteId() != "" {
+		rt.Trip = tripDescriptorToRT(enrichTrip(v.GetTrip(), v.GetTimestamp()))
+	}
+	if v.GetStopId() != "" {
+		rt.StopId = proto.String(v.GetStopId())
+	}
+	if v.GetTimestamp() > 0 {
+		rt.Timestamp = proto.Uint64(uint64(v.GetTimestamp()))
+	}
+	return &gtfs.FeedEntity{Id: proto.String(e.GetId()), Vehicle: rt}
+}
+
+func translatedStringToRT(ts *protodata.TranslatedStringProto) *gtfs.TranslatedString {
+	if len(ts.GetTranslation()) == 0 {
+		return nil
+	}
+	rt := &gtfs.TranslatedString{}
+	for _, t := range ts.GetTranslation() {
+		tr := &gtfs.TranslatedString_Translation{Text: proto.String(t.GetText())}
+		if t.GetLanguage() != "" {
+			tr.Language = proto.String(t.GetLanguage())
+		}
+		rt.Translation = append(rt.Translation, tr)
+	}
+	return rt
+}
+
+func alertToRT(e *protodata.AlertEntityProto) *gtfs.FeedEntity {
+	a := e.GetAlert()
+	rt := &gtfs.Alert{
+		Cause:           gtfs.Alert_Cause(a.GetCause()).Enum(),
+		Effect:          gtfs.Alert_Effect(a.GetEffect()).Enum(),
+		HeaderText:      translatedStringToRT(a.GetHeaderText()),
+		DescriptionText: translatedStringToRT(a.GetDescriptionText()),
+	}
+	for _, p := range a.GetActivePeriod() {
+		tr := &gtfs.TimeRange{}
+		if p.GetStart() > 0 {
+			tr.Start = proto.Uint64(uint64(p.GetStart()))
+		}
+		if p.GetEnd() > 0 {
+			tr.End = proto.Uint64(uint64(p.GetEnd()))
+		}
+		rt.ActivePeriod = append(rt.ActivePeriod, tr)
+	}
+	for _, ie := range a.GetInformedEntity() {
+		sel := &gtfs.EntitySelector{}
+		if ie.GetAgencyId() != "" {
+			sel.AgencyId = proto.String(ie.GetAgencyId())
+		}
+		if ie.GetRouteId() != "" {
+			sel.RouteId = proto.String(ie.GetRouteId())
+		}
+		if ie.GetRouteType() != 0 {
+			sel.RouteType = proto.Int32(ie.GetRouteType())
+		}
+		if ie.GetStopId() != "" {
+			sel.StopId = proto.String(ie.GetStopId())
+		}
+		rt.InformedEntity = append(rt.InformedEntity, sel)
+	}
+	return &gtfs.FeedEntity{Id: proto.String(e.GetId()), Alert: rt}
+}
+
+func alertMentionsRoute(a *protodata.AlertProto, routeId string) bool {
+	for _, ie := range a.GetInformedEntity() {
+		if ie.GetRouteId() == routeId {
+			return true
+		}
+	}
+	return false
+}
+
+// writeFeed sends a feed as protobuf, or as text format with ?format=text
+// for eyeballing it in a browser.
+func writeFeed(c *gin.Context, feed *gtfs.FeedMessage) {
+	if c.Query("format") == "text" {
+		c.String(http.StatusOK, prototext.Format(feed))
+		return
+	}
+
+	data, err := proto.Marshal(feed)
+	if err != nil {
+		c.Status(http.StatusInternalServerError)
+		return
+	}
+	c.Data(http.StatusOK, "application/x-protobuf", data)
+}
+
+// GET /rt/tripupdates?route_id=
+func HandleRTTripUpdates(c *gin.Context) {
+	snap, err := currentRealtime()
+	if err != nil {
+		c.Status(http.StatusInternalServerError)
+		return
+	}
+
+	routeId := c.Query("route_id")
+	var entities []*gtfs.FeedEntity
+	for _, e := range predictor.PredictTripUpdates(snap) {
+		entity := tripUpdateToRT(e)
+		if routeId != "" && entity.GetTripUpdate().GetTrip().GetRouteId() != routeId {
+			continue
+		}
+		entities = append(entities, entity)
+	}
+	writeFeed(c, newFeedMessage(snap.FetchedAt, entities))
+}
+
+// GET /rt/vehiclepositions?route_id=
+func HandleRTVehiclePositions(c *gin.Context) {
+	snap, err := currentRealtime()
+	if err != nil {
+		c.Status(http.StatusInternalServerError)
+		return
+	}
+
+	routeId := c.Query("route_id")
+	var entities []*gtfs.FeedEntity
+	for _, e := range snap.Vehicles {
+		entity := vehiclePositionToRT(e)
+		if routeId != "" && entity.GetVehicle().GetTrip().GetRouteId() != routeId {
+			continue
+		}
+		entities = append(entities, entity)
+	}
+	writeFeed(c, newFeedMessage(snap.FetchedAt, entities))
+}
+
+// GET /rt/alerts?route_id=
+func HandleRTAlerts(c *gin.Context) {
+	snap, err := currentRealtime()
+	if err != nil {
+		c.Status(http.StatusInternalServerError)
+		return
+	}
+
+	routeId := c.Query("route_id")
+	var entities []*gtfs.FeedEntity
+	for _, e := range snap.Alerts {
+		if routeId != "" && !alertMentionsRoute(e.GetAlert(), routeId) {
+			continue
+		}
+		entities = append(entities, alertToRT(e))
+	}
+	writeFeed(c, newFeedMessage(snap.FetchedAt, entities))
+}
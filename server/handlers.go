@@ -31,13 +31,23 @@ func HandleVehiclePosition(c *gin.Context) {
 	c.Data(http.StatusOK, "application/x-protobuf", data)
 }
 
-// GET /alerts
+// GET /alerts?network_id=
 func HandleAlert(c *gin.Context) {
     results, err := FetchAlerts()
     if err != nil {
         c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch alerts"})
         return
     }
+
+    if networkId := c.Query("network_id"); networkId != "" {
+        filtered := make([]*protodata.AlertEntityProto, 0)
+        for _, a := range results {
+            if alertInNetwork(a.GetAlert(), networkId) {
+                filtered = append(filtered, a)
+            }
+        }
+        results = filtered
+    }
     c.JSON(http.StatusOK, results)
 }
 
@@ -55,16 +65,20 @@ func HandleTripUpdate(c *gin.Context) {
 func HandleRoutesById(c *gin.Context) {
     id := c.Param("id")
     if route, found := findRouteByID(id); found {
-        c.JSON(http.StatusOK, route)
+        c.JSON(http.StatusOK, routeWithNetwork(route))
     } else {
         c.JSON(http.StatusNotFound, gin.H{"error": "Route not found"})
     }
 }
 
-// GET /stops/:id
+// GET /stops/:id?resolve=station
 func HandleStopsById(c *gin.Context) {
     id := c.Param("id")
     if stop, found := findStopById(id); found {
+        // a platform or entrance can be resolved up to the station it belongs to
+        if c.Query("resolve") == "station" {
+            stop = stationForStop(stop)
+        }
         c.JSON(http.StatusOK, stop)
     } else {
         c.JSON(http.StatusNotFound, gin.H{"error": "Stop not found"})
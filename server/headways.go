@@ -0,0 +1,316 @@
+package server
+
+import (
+	"cmp"
+	"encoding/csv"
+	"fmt"
+	"math"
+	"net/http"
+	"slices"
+	"strconv"
+	"studious-waffle/server/protodata"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// servicePeriods are the times of day headways are reported by, in hours of
+// the service day. Night runs on past midnight to the end of service.
+var servicePeriods = []struct {
+	name       string
+	start, end int
+}{
+	{"early", 0, 6},
+	{"am_peak", 6, 9},
+	{"midday", 9, 15},
+	{"pm_peak", 15, 19},
+	{"evening", 19, 22},
+	{"night", 22, 48},
+}
+
+// frequencyClass buckets an average headway the way service standards
+// usually do. Without any headway measured there is no service to class.
+func frequencyClass(avgHeadwayMinutes float64, headways int) string {
+	switch {
+	case headways == 0:
+		return "none"
+	case avgHeadwayMinutes <= 15:
+		return "frequent"
+	case avgHeadwayMinutes <= 30:
+		return "standard"
+	case avgHeadwayMinutes <= 60:
+		return "basic"
+	}
+	return "infrequent"
+}
+
+// PeriodHeadways is the service in one time-of-day period. Headways are the
+// gaps before each departure in the period.
+type PeriodHeadways struct {
+	Period            string  `json:"period"`
+	Start             string  `json:"start"`
+	End               string  `json:"end"`
+	Departures        int     `json:"departures"`
+	AvgHeadwayMinutes float64 `json:"avg_headway_minutes"`
+	MinHeadwayMinutes float64 `json:"min_headway_minutes"`
+	MaxHeadwayMinutes float64 `json:"max_headway_minutes"`
+	FrequencyClass    string  `json:"frequency_class"`
+}
+
+// ServiceLevel describes the departures from one place over a day.
+type ServiceLevel struct {
+	FirstDeparture string `json:"first_departure"`
+	LastDeparture  string `json:"last_departure"`
+	SpanMinutes    int    `json:"span_minutes"`
+	Departures     int    `json:"departures"`
+	// TripsPerHour[h] counts departures in hour h of the service day, which
+	// runs past 23 for trips after midnight
+	TripsPerHour []int            `json:"trips_per_hour"`
+	Periods      []PeriodHeadways `json:"periods"`
+}
+
+type StopHeadways struct {
+	StopId   string `json:"stop_id"`
+	StopName string `json:"stop_name"`
+	ServiceLevel
+}
+
+type DirectionHeadways struct {
+	DirectionId int32 `json:"direction_id"`
+	// Summary is the service level of trip starts, standing for the route
+	// as a whole
+	Summary ServiceLevel   `json:"summary"`
+	Stops   []StopHeadways `json:"stops"`
+}
+
+type RouteHeadways struct {
+	RouteId        string              `json:"route_id"`
+	RouteShortName string              `json:"route_short_name"`
+	Date           string              `json:"date"`
+	Warning        string              `json:"warning,omitempty"`
+	Directions     []DirectionHeadways `json:"directions"`
+}
+
+// serviceLevel summarizes a set of departure times, in seconds of the
+// service day.
+func serviceLevel(departures []int) ServiceLevel {
+	slices.Sort(departures)
+	level := ServiceLevel{
+		Departures:   len(departures),
+		TripsPerHour: make([]int, 24),
+		Periods:      make([]PeriodHeadways, 0, len(servicePeriods)),
+	}
+	if len(departures) > 0 {
+		first, last := departures[0], departures[len(departures)-1]
+		level.FirstDeparture = formatGTFSTime(first)
+		level.LastDeparture = formatGTFSTime(last)
+		level.SpanMinutes = (last - first) / 60
+		for len(level.TripsPerHour) <= last/3600 {
+			level.TripsPerHour = append(level.TripsPerHour, 0)
+		}
+	}
+	for _, d := range departures {
+		level.TripsPerHour[d/3600]++
+	}
+
+	for _, p := range servicePeriods {
+		period := PeriodHeadways{
+			Period: p.name,
+			Start:  formatGTFSTime(p.start * 3600),
+			End:    formatGTFSTime(p.end * 3600),
+		}
+		total, minGap, maxGap, gaps := 0, math.MaxInt, 0, 0
+		for i, d := range departures {
+			if d < p.start*3600 || d >= p.end*3600 {
+				continue
+			}
+			period.Departures++
+			if i == 0 {
+				continue
+			}
+			gap := d - departures[i-1]
+			total += gap
+			minGap, maxGap = min(minGap, gap), max(maxGap, gap)
+			gaps++
+		}
+		if gaps > 0 {
+			period.AvgHeadwayMinutes = math.Round(float64(total)/float64(gaps)/60*10) / 10
+			period.MinHeadwayMinutes = math.Round(float64(minGap)/60*10) / 10
+			period.MaxHeadwayMinutes = math.Round(float64(maxGap)/60*10) / 10
+		}
+		period.FrequencyClass = frequencyClass(period.AvgHeadwayMinutes, gaps)
+		level.Periods = append(level.Periods, period)
+	}
+	return level
+}
+
+// routeTripsOn returns the route's trips that run on a service date, by id.
+func routeTripsOn(routeId, date string) map[string]*protodata.TripProto {
+	trips := make(map[string]*protodata.TripProto)
+	for _, trip := range Trips {
+		if trip.GetRouteId() == routeId && serviceRunsOn(trip.GetServiceId(), date) {
+			trips[trip.GetTripId()] = trip
+		}
+	}
+	return trips
+}
+
+// buildRouteHeadways computes scheduled headways for every stop of a route
+// on a service date. Stops are listed in the order trips reach them, by
+// their average stop_sequence.
+func buildRouteHeadways(route *protodata.RouteProto, date string, direction int32, allDirections bool) RouteHeadways {
+	rh := RouteHeadways{
+		RouteId:        route.GetRouteId(),
+		RouteShortName: route.GetRouteShortName(),
+		Date:           date,
+		Directions:     make([]DirectionHeadways, 0),
+	}
+	if !calendarCovers(date) {
+		rh.Warning = "date is outside the feed's service calendar"
+	}
+
+	trips := routeTripsOn(route.GetRouteId(), date)
+	runs := make(map[string][]tripInstance)
+	starts := make(map[int32][]int)
+	stopsByDirection := make(map[int32]map[string]bool)
+	for id, trip := range trips {
+		stopTimes, found := findStopTimesByTripID(id)
+		if !found {
+			continue
+		}
+		runs[id] = tripInstances(id, stopTimes)
+		dir := trip.GetDirectionId()
+		if stopsByDirection[dir] == nil {
+			stopsByDirection[dir] = make(map[string]bool)
+		}
+		for _, st := range stopTimes {
+			stopsByDirection[dir][st.GetStopId()] = true
+		}
+		if _, dep, ok := stopTimeSeconds(stopTimes[0]); ok {
+			for _, run := range runs[id] {
+				starts[dir] = append(starts[dir], dep+run.Shift)
+			}
+		}
+	}
+
+	directions := make([]int32, 0, len(stopsByDirection))
+	for dir := range stopsByDirection {
+		if allDirections || dir == direction {
+			directions = append(directions, dir)
+		}
+	}
+	slices.Sort(directions)
+
+	for _, dir := range directions {
+		dh := DirectionHeadways{DirectionId: dir, Summary: serviceLevel(starts[dir]), Stops: make([]StopHeadways, 0)}
+		order := make(map[string]float64)
+		for stopId := range stopsByDirection[dir] {
+			stopTimes, _ := findStopTimesByStopID(stopId)
+			var departures []int
+			seqTotal, seqCount := 0, 0
+			for _, st := range stopTimes {
+				trip, found := trips[st.GetTripId()]
+				if !found || trip.GetDirectionId() != dir {
+					continue
+				}
+				seqTotal += int(st.GetStopSequence())
+				seqCount++
+				_, dep, ok := stopTimeSeconds(st)
+				if !ok {
+					continue
+				}
+				for _, run := range runs[st.GetTripId()] {
+					departures = append(departures, dep+run.Shift)
+				}
+			}
+			if seqCount > 0 {
+				order[stopId] = float64(seqTotal) / float64(seqCount)
+			}
+
+			sh := StopHeadways{StopId: stopId, ServiceLevel: serviceLevel(departures)}
+			if stop, found := findStopById(stopId); found {
+				sh.StopName = stop.GetStopName()
+			}
+			dh.Stops = append(dh.Stops, sh)
+		}
+		slices.SortFunc(dh.Stops, func(a, b StopHeadways) int {
+			if c := cmp.Compare(order[a.StopId], order[b.StopId]); c != 0 {
+				return c
+			}
+			return cmp.Compare(a.StopId, b.StopId)
+		})
+		rh.Directions = append(rh.Directions, dh)
+	}
+	return rh
+}
+
+// writeHeadwaysCSV writes a row per direction, stop and period. Rows with an
+// empty stop_id are the direction's summary over trip starts.
+func writeHeadwaysCSV(c *gin.Context, rh RouteHeadways) {
+	c.Header("Content-Type", "text/csv")
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", fmt.Sprintf("headways_%s_%s.csv", rh.RouteId, rh.Date)))
+	c.Status(http.StatusOK)
+
+	w := csv.NewWriter(c.Writer)
+	w.Write([]string{
+		"route_id", "date", "direction_id", "stop_id", "stop_name",
+		"first_departure", "last_departure", "span_minutes", "period", "period_start", "period_end",
+		"departures", "avg_headway_minutes", "min_headway_minutes", "max_headway_minutes", "frequency_class",
+	})
+	write := func(dir int32, stopId, stopName string, level ServiceLevel) {
+		for _, p := range level.Periods {
+			w.Write([]string{
+				rh.RouteId, rh.Date, strconv.Itoa(int(dir)), stopId, stopName,
+				level.FirstDeparture, level.LastDeparture, strconv.Itoa(level.SpanMinutes), p.Period, p.Start, p.End,
+				strconv.Itoa(p.Departures),
+				strconv.FormatFloat(p.AvgHeadwayMinutes, 'f', 1, 64),
+				strconv.FormatFloat(p.MinHeadwayMinutes, 'f', 1, 64),
+				strconv.FormatFloat(p.MaxHeadwayMinutes, 'f', 1, 64),
+				p.FrequencyClass,
+			})
+		}
+	}
+	for _, d := range rh.Directions {
+		write(d.DirectionId, "", "", d.Summary)
+		for _, s := range d.Stops {
+			write(d.DirectionId, s.StopId, s.StopName, s.ServiceLevel)
+		}
+	}
+	w.Flush()
+}
+
+// GET /analytics/routes/:id/headways?date=2026-10-19&direction=0&format=csv
+func HandleRouteHeadways(c *gin.Context) {
+	route, found := findRouteByID(c.Param("id"))
+	if !found {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Route not found"})
+		return
+	}
+
+	direction, allDirections := 0, true
+	if c.Query("direction") != "" {
+		var err error
+		if direction, err = queryInt(c, "direction", 0, 0, 1); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		allDirections = false
+	}
+
+	day := time.Now()
+	if s := c.Query("date"); s != "" {
+		d, err := parseServiceDate(s)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		day = d
+	}
+
+	rh := buildRouteHeadways(route, serviceDate(serviceDayStart(day)), int32(direction), allDirections)
+	if c.Query("format") == "csv" {
+		writeHeadwaysCSV(c, rh)
+		return
+	}
+	c.JSON(http.StatusOK, rh)
+}
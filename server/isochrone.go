@@ -0,0 +1,406 @@
+package server
+
+import (
+	"cmp"
+	"fmt"
+	"math"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	defaultIsochroneMinutes = 30
+	maxIsochroneMinutes     = 120
+	// defaultBandMinutes is the step between bands when none are asked for
+	defaultBandMinutes = 10
+	maxIsochroneBands  = 12
+	// isochroneCellMeters is the resolution of the band polygons
+	isochroneCellMeters = 100
+)
+
+// IsochroneStop is a stop reachable within the time budget.
+type IsochroneStop struct {
+	StopId        string    `json:"stop_id"`
+	StopName      string    `json:"stop_name"`
+	Lat           float64   `json:"lat"`
+	Lon           float64   `json:"lon"`
+	TravelSeconds int       `json:"travel_seconds"`
+	Arrival       time.Time `json:"arrival"`
+	// Vehicles is how many vehicles the fastest way there uses, 0 for stops
+	// reached on foot
+	Vehicles int `json:"vehicles"`
+
+	stop int
+}
+
+type GeoJSONGeometry struct {
+	Type        string `json:"type"`
+	Coordinates any    `json:"coordinates"`
+}
+
+type GeoJSONFeature struct {
+	Type       string          `json:"type"`
+	Properties map[string]any  `json:"properties"`
+	Geometry   GeoJSONGeometry `json:"geometry"`
+}
+
+type GeoJSONFeatureCollection struct {
+	Type     string           `json:"type"`
+	Features []GeoJSONFeature `json:"features"`
+}
+
+type Isochrone struct {
+	Origin  PlanPlace       `json:"origin"`
+	Depart  time.Time       `json:"depart"`
+	Date    string          `json:"date"`
+	Minutes int             `json:"minutes"`
+	Stops   []IsochroneStop `json:"stops"`
+	// Bands has a MultiPolygon per time band, each covering everywhere
+	// reachable within its minutes, so larger bands contain smaller ones
+	Bands GeoJSONFeatureCollection `json:"bands"`
+}
+
+// reachableStops is a one-to-all earliest-arrival search: every stop that
+// can be reached from a point within budget seconds of depart.
+func reachableStops(data *raptorData, lat, lon float64, depart time.Time, budget, maxTransfers int) []IsochroneStop {
+	departSecs := int(depart.Sub(data.dayStart).Seconds())
+	rounds := maxTransfers + 1
+	search := newRaptorSearch(data, rounds)
+	search.run(stopWalks(lat, lon), nil, departSecs, rounds, departSecs+budget+1)
+
+	stops := make([]IsochroneStop, 0)
+	for p := range Stops {
+		best, vehicles := unreached, 0
+		for k := 0; k <= rounds; k++ {
+			if t := search.arr[k][p]; t < best {
+				best, vehicles = t, k
+			}
+		}
+		if best > departSecs+budget {
+			continue
+		}
+		s := Stops[p]
+		stops = append(stops, IsochroneStop{
+			StopId:        s.GetStopId(),
+			StopName:      s.GetStopName(),
+			Lat:           s.GetStopLat(),
+			Lon:           s.GetStopLon(),
+			TravelSeconds: best - departSecs,
+			Arrival:       search.at(best),
+			Vehicles:      vehicles,
+			stop:          p,
+		})
+	}
+	slices.SortFunc(stops, func(a, b IsochroneStop) int {
+		if c := cmp.Compare(a.TravelSeconds, b.TravelSeconds); c != 0 {
+			return c
+		}
+		return cmp.Compare(a.StopId, b.StopId)
+	})
+	return stops
+}
+
+// travelGrid is a raster of travel times around the origin, with cells
+// isochroneCellMeters on a side and (x0, y0) the cell at the south-west
+// corner.
+type travelGrid struct {
+	plane  localPlane
+	x0, y0 int
+	w, h   int
+	secs   []int
+}
+
+// newTravelGrid spreads travel times from the origin and every reachable
+// stop on foot: a cell's time is the fastest of walking there from the
+// origin or from a stop, within the usual walking limits.
+func newTravelGrid(lat, lon float64, stops []IsochroneStop, budget int) *travelGrid {
+	type source struct {
+		x, y, radius float64
+		secs         int
+	}
+	plane := newLocalPlane(lat, lon)
+	sources := []source{{radius: min(float64(budget)*walkingSpeed, maxDirectWalkMeters)}}
+	for _, s := range stops {
+		x, y := plane.xy(s.Lat, s.Lon)
+		sources = append(sources, source{x: x, y: y, secs: s.TravelSeconds, radius: min(float64(budget-s.TravelSeconds)*walkingSpeed, accessRadiusMeters)})
+	}
+
+	minX, minY, maxX, maxY := math.Inf(1), math.Inf(1), math.Inf(-1), math.Inf(-1)
+	for _, s := range sources {
+		minX, minY = min(minX, s.x-s.radius), min(minY, s.y-s.radius)
+		maxX, maxY = max(maxX, s.x+s.radius), max(maxY, s.y+s.radius)
+	}
+	g := &travelGrid{
+		plane: plane,
+		x0:    int(math.Floor(minX / isochroneCellMeters)),
+		y0:    int(math.Floor(minY / isochroneCellMeters)),
+	}
+	g.w = int(math.Floor(maxX/isochroneCellMeters)) - g.x0 + 1
+	g.h = int(math.Floor(maxY/isochroneCellMeters)) - g.y0 + 1
+	g.secs = filled(g.w*g.h, unreached)
+
+	for _, s := range sources {
+		cx0 := int(math.Floor((s.x-s.radius)/isochroneCellMeters)) - g.x0
+		cx1 := int(math.Floor((s.x+s.radius)/isochroneCellMeters)) - g.x0
+		cy0 := int(math.Floor((s.y-s.radius)/isochroneCellMeters)) - g.y0
+		cy1 := int(math.Floor((s.y+s.radius)/isochroneCellMeters)) - g.y0
+		for cy := max(cy0, 0); cy <= min(cy1, g.h-1); cy++ {
+			for cx := max(cx0, 0); cx <= min(cx1, g.w-1); cx++ {
+				// distance to the cell's center
+				px := (float64(cx+g.x0) + 0.5) * isochroneCellMeters
+				py := (float64(cy+g.y0) + 0.5) * isochroneCellMeters
+				d := math.Hypot(px-s.x, py-s.y)
+				if d > s.radius {
+					continue
+				}
+				if t := s.secs + walkSeconds(d); t < g.secs[cy*g.w+cx] {
+					g.secs[cy*g.w+cx] = t
+				}
+			}
+		}
+	}
+	return g
+}
+
+func (g *travelGrid) within(cx, cy, secs int) bool {
+	return cx >= 0 && cy >= 0 && cx < g.w && cy < g.h && g.secs[cy*g.w+cx] <= secs
+}
+
+type gridVertex struct{ x, y int }
+
+type gridEdge struct{ from, to gridVertex }
+
+// polygons traces the outline of the cells reachable within secs into
+// GeoJSON MultiPolygon coordinates. Edges run counter-clockwise around the
+// reachable cells, so outer rings come out counter-clockwise and holes
+// clockwise, as RFC 7946 asks.
+func (g *travelGrid) polygons(secs int) [][][][2]float64 {
+	outgoing := make(map[gridVertex][]gridEdge)
+	var edges []gridEdge
+	add := func(x0, y0, x1, y1 int) {
+		e := gridEdge{gridVertex{x0, y0}, gridVertex{x1, y1}}
+		outgoing[e.from] = append(outgoing[e.from], e)
+		edges = append(edges, e)
+	}
+	for cy := 0; cy < g.h; cy++ {
+		for cx := 0; cx < g.w; cx++ {
+			if !g.within(cx, cy, secs) {
+				continue
+			}
+			if !g.within(cx, cy-1, secs) {
+				add(cx, cy, cx+1, cy)
+			}
+			if !g.within(cx+1, cy, secs) {
+				add(cx+1, cy, cx+1, cy+1)
+			}
+			if !g.within(cx, cy+1, secs) {
+				add(cx+1, cy+1, cx, cy+1)
+			}
+			if !g.within(cx-1, cy, secs) {
+				add(cx, cy+1, cx, cy)
+			}
+		}
+	}
+
+	used := make(map[gridEdge]bool, len(edges))
+	var outers, holes [][]gridVertex
+	for _, start := range edges {
+		if used[start] {
+			continue
+		}
+		var ring []gridVertex
+		for e := start; !used[e]; e = nextGridEdge(e, outgoing[e.to]) {
+			used[e] = true
+			ring = append(ring, e.from)
+		}
+		ring = dropCollinear(ring)
+		if ringArea(ring) > 0 {
+			outers = append(outers, ring)
+		} else {
+			holes = append(holes, ring)
+		}
+	}
+
+	// each hole belongs to the smallest outer ring around it
+	owned := make([][][]gridVertex, len(outers))
+	for _, hole := range holes {
+		// the center of the unreachable cell to the right of the first edge
+		d := gridVertex{hole[1].x - hole[0].x, hole[1].y - hole[0].y}
+		d.x, d.y = cmp.Compare(d.x, 0), cmp.Compare(d.y, 0)
+		px := float64(hole[0].x) + 0.5*float64(d.x) + 0.5*float64(d.y)
+		py := float64(hole[0].y) + 0.5*float64(d.y) - 0.5*float64(d.x)
+		owner := -1
+		for i, outer := range outers {
+			if ringContains(outer, px, py) && (owner < 0 || ringArea(outer) < ringArea(outers[owner])) {
+				owner = i
+			}
+		}
+		if owner >= 0 {
+			owned[owner] = append(owned[owner], hole)
+		}
+	}
+
+	coords := make([][][][2]float64, 0, len(outers))
+	for i, outer := range outers {
+		polygon := [][][2]float64{g.ringCoordinates(outer)}
+		for _, hole := range owned[i] {
+			polygon = append(polygon, g.ringCoordinates(hole))
+		}
+		coords = append(coords, polygon)
+	}
+	return coords
+}
+
+// nextGridEdge picks the edge to follow from the end of e. Where two
+// reachable cells touch only at a corner the boundary turns left, hugging
+// the cell it came along, so each edge has exactly one successor and rings
+// close on the edge they started from.
+func nextGridEdge(e gridEdge, candidates []gridEdge) gridEdge {
+	dx, dy := e.to.x-e.from.x, e.to.y-e.from.y
+	var next gridEdge
+	bestTurn := 2
+	for _, c := range candidates {
+		cx, cy := c.to.x-c.from.x, c.to.y-c.from.y
+		// -1 left, 0 straight, 1 right
+		turn := -(dx*cy - dy*cx)
+		if turn < bestTurn {
+			next, bestTurn = c, turn
+		}
+	}
+	return next
+}
+
+func dropCollinear(ring []gridVertex) []gridVertex {
+	n := len(ring)
+	out := make([]gridVertex, 0, n)
+	for i, v := range ring {
+		prev, next := ring[(i+n-1)%n], ring[(i+1)%n]
+		if (v.x-prev.x)*(next.y-v.y)-(v.y-prev.y)*(next.x-v.x) != 0 {
+			out = append(out, v)
+		}
+	}
+	return out
+}
+
+// ringArea is the signed area of a ring, positive when counter-clockwise.
+func ringArea(ring []gridVertex) float64 {
+	area := 0
+	for i, v := range ring {
+		w := ring[(i+1)%len(ring)]
+		area += v.x*w.y - w.x*v.y
+	}
+	return float64(area) / 2
+}
+
+func ringContains(ring []gridVertex, px, py float64) bool {
+	inside := false
+	for i, v := range ring {
+		w := ring[(i+1)%len(ring)]
+		if (float64(v.y) > py) != (float64(w.y) > py) {
+			x := float64(v.x) + (py-float64(v.y))/float64(w.y-v.y)*float64(w.x-v.x)
+			if px < x {
+				inside = !inside
+			}
+		}
+	}
+	return inside
+}
+
+// ringCoordinates turns grid vertices into a closed ring of [lon, lat].
+func (g *travelGrid) ringCoordinates(ring []gridVertex) [][2]float64 {
+	coords := make([][2]float64, 0, len(ring)+1)
+	for _, v := range append(ring, ring[0]) {
+		lat, lon := g.plane.latLon(float64(v.x+g.x0)*isochroneCellMeters, float64(v.y+g.y0)*isochroneCellMeters)
+		coords = append(coords, [2]float64{lon, lat})
+	}
+	return coords
+}
+
+// parseBands reads a comma-separated list of band limits in minutes. Without
+// one, bands are every defaultBandMinutes up to the budget.
+func parseBands(s string, minutes int) ([]int, error) {
+	var bands []int
+	if s == "" {
+		for b := defaultBandMinutes; b < minutes; b += defaultBandMinutes {
+			bands = append(bands, b)
+		}
+		return append(bands, minutes), nil
+	}
+	for _, part := range strings.Split(s, ",") {
+		b, err := strconv.Atoi(strings.TrimSpace(part))
+		if err != nil || b < 1 || b > minutes {
+			return nil, fmt.Errorf("bands must be minutes between 1 and %d", minutes)
+		}
+		bands = append(bands, b)
+	}
+	slices.Sort(bands)
+	bands = slices.Compact(bands)
+	if len(bands) > maxIsochroneBands {
+		return nil, fmt.Errorf("at most %d bands", maxIsochroneBands)
+	}
+	return bands, nil
+}
+
+func buildIsochrone(data *raptorData, origin PlanPlace, depart time.Time, minutes int, bands []int, maxTransfers int) Isochrone {
+	budget := minutes * 60
+	stops := reachableStops(data, origin.Lat, origin.Lon, depart, budget, maxTransfers)
+	grid := newTravelGrid(origin.Lat, origin.Lon, stops, budget)
+
+	iso := Isochrone{
+		Origin:  origin,
+		Depart:  depart,
+		Date:    data.date,
+		Minutes: minutes,
+		Stops:   stops,
+		Bands:   GeoJSONFeatureCollection{Type: "FeatureCollection", Features: make([]GeoJSONFeature, 0, len(bands))},
+	}
+	for _, b := range bands {
+		iso.Bands.Features = append(iso.Bands.Features, GeoJSONFeature{
+			Type:       "Feature",
+			Properties: map[string]any{"minutes": b},
+			Geometry:   GeoJSONGeometry{Type: "MultiPolygon", Coordinates: grid.polygons(b * 60)},
+		})
+	}
+	return iso
+}
+
+// GET /isochrone?lat=&lon=&minutes=30&depart=&bands=10,20,30&max_transfers=
+func HandleIsochrone(c *gin.Context) {
+	lat, err1 := strconv.ParseFloat(c.Query("lat"), 64)
+	lon, err2 := strconv.ParseFloat(c.Query("lon"), 64)
+	if err1 != nil || err2 != nil || lat < -90 || lat > 90 || lon < -180 || lon > 180 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "valid lat and lon query params required"})
+		return
+	}
+	minutes, err := queryInt(c, "minutes", defaultIsochroneMinutes, 1, maxIsochroneMinutes)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	bands, err := parseBands(c.Query("bands"), minutes)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	maxTransfers, err := queryInt(c, "max_transfers", defaultPlanTransfers, 0, maxPlanTransfers)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	depart := time.Now()
+	if s := c.Query("depart"); s != "" {
+		if depart, err = parseDepart(s, time.Now()); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+
+	data := plannerCache.get(serviceDayStart(depart), false)
+	origin := PlanPlace{Name: "Origin", Lat: lat, Lon: lon}
+	c.JSON(http.StatusOK, buildIsochrone(data, origin, depart, minutes, bands, maxTransfers))
+}
@@ -0,0 +1,185 @@
+package server
+
+import (
+	"math"
+	"testing"
+)
+
+// testGrid builds a travel grid from rows drawn north to south, '#' for a
+// cell reached at once and anything else for an unreached one.
+func testGrid(rows ...string) *travelGrid {
+	g := &travelGrid{plane: newLocalPlane(39.74, -104.99), w: len(rows[0]), h: len(rows)}
+	g.secs = filled(g.w*g.h, unreached)
+	for r, row := range rows {
+		cy := g.h - 1 - r
+		for cx, c := range row {
+			if c == '#' {
+				g.secs[cy*g.w+cx] = 0
+			}
+		}
+	}
+	return g
+}
+
+// gridRing turns a closed ring of [lon, lat] back into grid vertices, less
+// the closing one.
+func gridRing(t *testing.T, g *travelGrid, coords [][2]float64) []gridVertex {
+	t.Helper()
+	if len(coords) < 4 {
+		t.Fatalf("ring has %d positions, want at least 4", len(coords))
+	}
+	if coords[0] != coords[len(coords)-1] {
+		t.Fatalf("ring starts at %v and ends at %v, want it closed", coords[0], coords[len(coords)-1])
+	}
+	ring := make([]gridVertex, 0, len(coords)-1)
+	for _, c := range coords[:len(coords)-1] {
+		x, y := g.plane.xy(c[1], c[0])
+		ring = append(ring, gridVertex{
+			int(math.Round(x/isochroneCellMeters)) - g.x0,
+			int(math.Round(y/isochroneCellMeters)) - g.y0,
+		})
+	}
+	return ring
+}
+
+// southWest is the lowest, then leftmost, vertex of a ring.
+func southWest(ring []gridVertex) gridVertex {
+	sw := ring[0]
+	for _, v := range ring[1:] {
+		if v.y < sw.y || v.y == sw.y && v.x < sw.x {
+			sw = v
+		}
+	}
+	return sw
+}
+
+func TestTravelGridPolygons(t *testing.T) {
+	// polygons are keyed by the south-west corner of their outer ring, with
+	// the area of the outer ring and of each hole in cells
+	type polygon struct {
+		outer, corners int
+		holes          []int
+	}
+	tests := []struct {
+		name string
+		rows []string
+		want map[gridVertex]polygon
+	}{
+		{
+			name: "single cell",
+			rows: []string{"#"},
+			want: map[gridVertex]polygon{{0, 0}: {outer: 1, corners: 4}},
+		},
+		{
+			name: "diagonally touching cells stay apart",
+			rows: []string{
+				".#",
+				"#.",
+			},
+			want: map[gridVertex]polygon{
+				{0, 0}: {outer: 1, corners: 4},
+				{1, 1}: {outer: 1, corners: 4},
+			},
+		},
+		{
+			name: "ring with a hole",
+			rows: []string{
+				"###",
+				"#.#",
+				"###",
+			},
+			want: map[gridVertex]polygon{{0, 0}: {outer: 9, corners: 4, holes: []int{1}}},
+		},
+		{
+			name: "L shape",
+			rows: []string{
+				"#.",
+				"##",
+			},
+			want: map[gridVertex]polygon{{0, 0}: {outer: 3, corners: 6}},
+		},
+		{
+			name: "hole goes to the ring around it, not the island in it or a neighbour",
+			rows: []string{
+				"#####.#",
+				"#...#..",
+				"#.#.#..",
+				"#...#..",
+				"#####..",
+			},
+			want: map[gridVertex]polygon{
+				{0, 0}: {outer: 25, corners: 4, holes: []int{9}},
+				{2, 2}: {outer: 1, corners: 4},
+				{6, 4}: {outer: 1, corners: 4},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := testGrid(tt.rows...)
+			got := g.polygons(0)
+			if len(got) != len(tt.want) {
+				t.Fatalf("got %d polygon(s), want %d", len(got), len(tt.want))
+			}
+			seen := make(map[gridVertex]bool)
+			for _, p := range got {
+				outer := gridRing(t, g, p[0])
+				sw := southWest(outer)
+				want, found := tt.want[sw]
+				if !found || seen[sw] {
+					t.Fatalf("unexpected polygon with its outer ring at %v", outer)
+				}
+				seen[sw] = true
+
+				if area := ringArea(outer); area != float64(want.outer) {
+					t.Errorf("outer ring %v has signed area %v, want %d counter-clockwise", outer, area, want.outer)
+				}
+				if len(outer) != want.corners {
+					t.Errorf("outer ring %v has %d corners, want %d", outer, len(outer), want.corners)
+				}
+				holes := p[1:]
+				if len(holes) != len(want.holes) {
+					t.Fatalf("polygon at %v has %d hole(s), want %d", sw, len(holes), len(want.holes))
+				}
+				for i, h := range holes {
+					hole := gridRing(t, g, h)
+					if area := ringArea(hole); area != -float64(want.holes[i]) {
+						t.Errorf("hole %v has signed area %v, want %d clockwise", hole, area, -want.holes[i])
+					}
+					for _, v := range hole {
+						if !ringContains(outer, float64(v.x), float64(v.y)) {
+							t.Errorf("hole vertex %v is outside its outer ring %v", v, outer)
+						}
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestNextGridEdge(t *testing.T) {
+	// every candidate leaves (1, 0), the end of an edge heading east
+	in := gridEdge{gridVertex{0, 0}, gridVertex{1, 0}}
+	left := gridEdge{gridVertex{1, 0}, gridVertex{1, 1}}
+	straight := gridEdge{gridVertex{1, 0}, gridVertex{2, 0}}
+	right := gridEdge{gridVertex{1, 0}, gridVertex{1, -1}}
+	tests := []struct {
+		name       string
+		candidates []gridEdge
+		want       gridEdge
+	}{
+		{"only left", []gridEdge{left}, left},
+		{"only straight", []gridEdge{straight}, straight},
+		{"only right", []gridEdge{right}, right},
+		{"corner turns left", []gridEdge{right, left}, left},
+		{"corner turns left in any order", []gridEdge{left, right}, left},
+		{"straight before right", []gridEdge{right, straight}, straight},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := nextGridEdge(in, tt.candidates); got != tt.want {
+				t.Errorf("nextGridEdge = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
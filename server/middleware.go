@@ -2,13 +2,20 @@ package server
 
 import (
 	"net/http"
+	"slices"
 
+	"github.com/gin-contrib/logger"
 	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog"
 	"golang.org/x/time/rate"
 )
 
+// RateLimiter caps requests across all clients at the configured rate.
 func RateLimiter() gin.HandlerFunc {
-	limiter := rate.NewLimiter(5, 10)
+	if config.RateLimit.RequestsPerSecond == 0 {
+		return func(c *gin.Context) { c.Next() }
+	}
+	limiter := rate.NewLimiter(rate.Limit(config.RateLimit.RequestsPerSecond), config.RateLimit.Burst)
 
 	return func(c *gin.Context) {
 		if !limiter.Allow() {
@@ -18,3 +25,22 @@ func RateLimiter() gin.HandlerFunc {
 		c.Next()
 	}
 }
+
+// AccessLogger logs every request at or above the configured level, leaving
+// out the configured paths.
+func AccessLogger() gin.HandlerFunc {
+	level, err := zerolog.ParseLevel(config.Logging.Level)
+	if err != nil {
+		level = zerolog.InfoLevel
+	}
+	skipPaths := config.Logging.SkipPaths
+	return logger.SetLogger(
+		logger.WithUTC(config.Logging.UTC),
+		logger.WithSkipper(func(c *gin.Context) bool {
+			return slices.Contains(skipPaths, c.Request.URL.Path)
+		}),
+		logger.WithLogger(func(_ *gin.Context, l zerolog.Logger) zerolog.Logger {
+			return l.Level(level)
+		}),
+	)
+}
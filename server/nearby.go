@@ -0,0 +1,61 @@
+package server
+
+import (
+	"cmp"
+	"math"
+	"slices"
+	"sync"
+)
+
+// stopGridDegrees is the cell size of the stop grid, about 550m north-south.
+const stopGridDegrees = 0.005
+
+type gridCell struct{ x, y int }
+
+// stopGrid buckets the indexes of Stops by grid cell so nearby stops are
+// found without scanning every stop.
+var stopGrid = sync.OnceValue(func() map[gridCell][]int {
+	grid := make(map[gridCell][]int)
+	for i, s := range Stops {
+		cell := gridCellFor(s.GetStopLat(), s.GetStopLon())
+		grid[cell] = append(grid[cell], i)
+	}
+	return grid
+})
+
+func gridCellFor(lat, lon float64) gridCell {
+	return gridCell{x: int(math.Floor(lon / stopGridDegrees)), y: int(math.Floor(lat / stopGridDegrees))}
+}
+
+type nearStop struct {
+	stop   int
+	meters float64
+}
+
+// nearbyStops returns the indexes of Stops within radius meters of a point,
+// closest first.
+func nearbyStops(lat, lon, radius float64) []nearStop {
+	dy := int(math.Ceil(radius / (earthRadiusMeters * math.Pi / 180) / stopGridDegrees))
+	dx := int(math.Ceil(float64(dy) / math.Max(math.Cos(lat*math.Pi/180), 0.01)))
+	center := gridCellFor(lat, lon)
+
+	var found []nearStop
+	grid := stopGrid()
+	for x := center.x - dx; x <= center.x+dx; x++ {
+		for y := center.y - dy; y <= center.y+dy; y++ {
+			for _, i := range grid[gridCell{x, y}] {
+				s := Stops[i]
+				if d := haversineMeters(lat, lon, s.GetStopLat(), s.GetStopLon()); d <= radius {
+					found = append(found, nearStop{stop: i, meters: d})
+				}
+			}
+		}
+	}
+	slices.SortFunc(found, func(a, b nearStop) int {
+		if c := cmp.Compare(a.meters, b.meters); c != 0 {
+			return c
+		}
+		return cmp.Compare(Stops[a.stop].GetStopId(), Stops[b.stop].GetStopId())
+	})
+	return found
+}
@@ -0,0 +1,113 @@
+package server
+
+import (
+	"net/http"
+	"sort"
+	"studious-waffle/server/protodata"
+
+	"github.com/gin-gonic/gin"
+	"google.golang.org/protobuf/proto"
+)
+
+// Networks
+func findNetworkById(networkId string) (*protodata.NetworkProto, bool) {
+	data := Networks
+	n := len(data)
+
+	idx := sort.Search(n, func(i int) bool {
+		return data[i].GetNetworkId() >= networkId
+	})
+
+	if idx < n && data[idx].GetNetworkId() == networkId {
+		return data[idx], true
+	}
+	return nil, false
+}
+
+// Route Networks
+func findNetworkForRoute(routeId string) (string, bool) {
+	data := RouteNetworks
+	n := len(data)
+
+	idx := sort.Search(n, func(i int) bool {
+		return data[i].GetRouteId() >= routeId
+	})
+
+	if idx < n && data[idx].GetRouteId() == routeId {
+		return data[idx].GetNetworkId(), true
+	}
+	return "", false
+}
+
+// routeWithNetwork returns a copy of the route with network_id filled in
+// from route_networks.txt, leaving the shared static data untouched.
+func routeWithNetwork(r *protodata.RouteProto) *protodata.RouteProto {
+	out := proto.Clone(r).(*protodata.RouteProto)
+	if out.GetNetworkId() == "" {
+		if networkId, found := findNetworkForRoute(r.GetRouteId()); found {
+			out.NetworkId = proto.String(networkId)
+		}
+	}
+	return out
+}
+
+func routeInNetwork(routeId, networkId string) bool {
+	id, found := findNetworkForRoute(routeId)
+	return found && id == networkId
+}
+
+// alertInNetwork reports whether an alert names any route of the network.
+// Agency-wide alerts name no route and are left out, so a network-scoped
+// listing only shows what is specific to it.
+func alertInNetwork(a *protodata.AlertProto, networkId string) bool {
+	for _, ie := range a.GetInformedEntity() {
+		if ie.GetRouteId() != "" && routeInNetwork(ie.GetRouteId(), networkId) {
+			return true
+		}
+	}
+	return false
+}
+
+func routesInNetwork(networkId string) []*protodata.RouteProto {
+	results := make([]*protodata.RouteProto, 0)
+	for _, r := range Routes {
+		if routeInNetwork(r.GetRouteId(), networkId) {
+			results = append(results, routeWithNetwork(r))
+		}
+	}
+	return results
+}
+
+type NetworkSummary struct {
+	NetworkId   string `json:"network_id"`
+	NetworkName string `json:"network_name"`
+	RouteCount  int    `json:"route_count"`
+}
+
+// GET /networks
+func HandleNetworks(c *gin.Context) {
+	counts := make(map[string]int)
+	for _, rn := range RouteNetworks {
+		counts[rn.GetNetworkId()]++
+	}
+
+	results := make([]NetworkSummary, 0, len(Networks))
+	for _, n := range Networks {
+		results = append(results, NetworkSummary{
+			NetworkId:   n.GetNetworkId(),
+			NetworkName: n.GetNetworkName(),
+			RouteCount:  counts[n.GetNetworkId()],
+		})
+	}
+	c.JSON(http.StatusOK, results)
+}
+
+// GET /networks/:id/routes
+func HandleNetworkRoutes(c *gin.Context) {
+	id := c.Param("id")
+	if _, found := findNetworkById(id); !found {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Network not found"})
+		return
+	}
+	c.JSON(http.StatusOK, routesInNetwork(id))
+}
@@ -0,0 +1,496 @@
+package server
+
+import (
+	"bufio"
+	"encoding/json"
+	"log"
+	"math"
+	"net/http"
+	"os"
+	"slices"
+	"studious-waffle/server/protodata"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	// a trip is on time from one minute early to five minutes late, the
+	// usual industry window
+	defaultEarlySeconds = 60
+	defaultLateSeconds  = 300
+
+	defaultOTPRetentionDays = 7
+	// pendingArrivalTTL drops predictions for stops that never resolve, as
+	// when a trip vanishes from the feed without its times passing
+	pendingArrivalTTL = 2 * time.Hour
+	otpPruneInterval  = time.Hour
+)
+
+const (
+	OTPSourceVehicle    = "vehicle"
+	OTPSourcePrediction = "prediction"
+)
+
+// OTPObservation is when a trip was observed at a stop, against when it
+// was scheduled there. The schedule is the departure at a trip's first stop
+// and the arrival elsewhere.
+type OTPObservation struct {
+	TripId       string    `json:"trip_id"`
+	ServiceDate  string    `json:"service_date"`
+	RouteId      string    `json:"route_id"`
+	StopId       string    `json:"stop_id"`
+	StopSequence int32     `json:"stop_sequence"`
+	Scheduled    time.Time `json:"scheduled"`
+	Observed     time.Time `json:"observed"`
+	DelaySeconds int       `json:"delay_seconds"`
+	Source       string    `json:"source"`
+}
+
+type otpKey struct {
+	tripId, date string
+	seq          int32
+}
+
+// pendingArrival is the latest prediction for a stop not yet passed.
+type pendingArrival struct {
+	predicted int64
+	updatedAt time.Time
+}
+
+// OTPStore collects observed arrivals from realtime polls and answers on-time
+// performance queries over them. Vehicles seen passing a stop are taken over
+// trip update predictions for the same stop.
+type OTPStore struct {
+	mu           sync.Mutex
+	observations map[otpKey]*OTPObservation
+	pending      map[otpKey]pendingArrival
+	retention    time.Duration
+	lastPrune    time.Time
+	// path is an append-only log of observations, reloaded on start; empty
+	// keeps them in memory only
+	path    string
+	unsaved []*OTPObservation
+}
+
+func NewOTPStore(retention time.Duration, path string) *OTPStore {
+	return &OTPStore{
+		observations: make(map[otpKey]*OTPObservation),
+		pending:      make(map[otpKey]pendingArrival),
+		retention:    retention,
+		path:         path,
+	}
+}
+
+var otpStore = NewOTPStore(defaultOTPRetentionDays*24*time.Hour, "")
+
+// startOTP sets up the store with the configured retention and path and has
+// it learn from every realtime poll.
+func startOTP() {
+	retention := time.Duration(config.Analytics.OTPRetentionDays) * 24 * time.Hour
+	otpStore = NewOTPStore(retention, config.Data.OTPStorePath)
+	if err := otpStore.load(time.Now()); err != nil {
+		log.Println("Error loading OTP store:", err)
+	}
+	predictor.OnStopPassed(otpStore.RecordPassing)
+	OnRealtimeUpdate(otpStore.Observe)
+}
+
+// load reads back the log, then rewrites it without the observations that
+// fell out of the retention window or were superseded.
+func (s *OTPStore) load(now time.Time) error {
+	if s.path == "" {
+		return nil
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	f, err := os.Open(s.path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		var obs OTPObservation
+		if json.Unmarshal(scanner.Bytes(), &obs) != nil {
+			continue
+		}
+		if now.Sub(obs.Scheduled) <= s.retention {
+			s.keep(&obs)
+		}
+	}
+	f.Close()
+	if err := scanner.Err(); err != nil {
+		return err
+	}
+
+	out, err := os.Create(s.path)
+	if err != nil {
+		return err
+	}
+	defer out.Close()
+	w := bufio.NewWriter(out)
+	enc := json.NewEncoder(w)
+	for _, obs := range s.observations {
+		enc.Encode(obs)
+	}
+	return w.Flush()
+}
+
+// save appends the observations recorded since the last save to the log.
+func (s *OTPStore) save() {
+	if s.path == "" || len(s.unsaved) == 0 {
+		s.unsaved = nil
+		return
+	}
+	f, err := os.OpenFile(s.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		log.Println("Error opening OTP store:", err)
+		return
+	}
+	defer f.Close()
+	enc := json.NewEncoder(f)
+	for _, obs := range s.unsaved {
+		enc.Encode(obs)
+	}
+	s.unsaved = nil
+}
+
+// keep stores an observation unless one as good is already held: the first
+// of each source wins, and vehicles beat predictions.
+func (s *OTPStore) keep(obs *OTPObservation) bool {
+	key := otpKey{tripId: obs.TripId, date: obs.ServiceDate, seq: obs.StopSequence}
+	if prev, found := s.observations[key]; found && (prev.Source == obs.Source || prev.Source == OTPSourceVehicle) {
+		return false
+	}
+	s.observations[key] = obs
+	return true
+}
+
+func (s *OTPStore) record(obs *OTPObservation) {
+	if s.keep(obs) {
+		s.unsaved = append(s.unsaved, obs)
+	}
+}
+
+// newOTPObservation compares an observed time with a trip's schedule at a
+// stop on a service date.
+func newOTPObservation(tripId, date string, stopTimes []*protodata.StopTimeProto, idx int, at time.Time, source string) (*OTPObservation, bool) {
+	day, err := parseServiceDate(date)
+	if err != nil {
+		return nil, false
+	}
+	st := stopTimes[idx]
+	arr, dep, ok := stopTimeSeconds(st)
+	if !ok {
+		return nil, false
+	}
+	sched := arr
+	if idx == 0 {
+		sched = dep
+	}
+	scheduled := serviceDayStart(day).Add(time.Duration(sched) * time.Second)
+
+	obs := &OTPObservation{
+		TripId:       tripId,
+		ServiceDate:  date,
+		StopId:       st.GetStopId(),
+		StopSequence: st.GetStopSequence(),
+		Scheduled:    scheduled,
+		Observed:     at.Truncate(time.Second),
+		DelaySeconds: int(at.Sub(scheduled).Round(time.Second).Seconds()),
+		Source:       source,
+	}
+	if trip, found := findTripByID(tripId); found {
+		obs.RouteId = trip.GetRouteId()
+	}
+	return obs, true
+}
+
+// runDate is the service date a trip update or vehicle refers to.
+func runDate(startDate string, stopTimes []*protodata.StopTimeProto, at time.Time) string {
+	if startDate != "" {
+		return startDate
+	}
+	return serviceDate(resolveServiceDay(stopTimes, at))
+}
+
+// RecordPassing stores a vehicle seen passing a stop.
+func (s *OTPStore) RecordPassing(p StopPassing) {
+	stopTimes, found := findStopTimesByTripID(p.TripId)
+	if !found {
+		return
+	}
+	idx := slices.Index(stopTimes, p.StopTime)
+	if idx < 0 {
+		return
+	}
+	obs, ok := newOTPObservation(p.TripId, runDate(p.StartDate, stopTimes, p.At), stopTimes, idx, p.At, OTPSourceVehicle)
+	if !ok {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.record(obs)
+}
+
+// matchStopTime finds the stop time a stop time update refers to, by
+// stop_sequence or else by stop_id.
+func matchStopTime(stopTimes []*protodata.StopTimeProto, stu *protodata.StopTimeUpdateProto) int {
+	return slices.IndexFunc(stopTimes, func(st *protodata.StopTimeProto) bool {
+		if stu.GetStopSequence() != 0 {
+			return st.GetStopSequence() == stu.GetStopSequence()
+		}
+		return st.GetStopId() == stu.GetStopId()
+	})
+}
+
+// predictedArrival is a stop time update's arrival as a unix time, falling
+// back to its departure and then to the schedule plus delay.
+func predictedArrival(stu *protodata.StopTimeUpdateProto, st *protodata.StopTimeProto, dayStart time.Time) int64 {
+	if t := stu.GetArrival().GetTime(); t != 0 {
+		return t
+	}
+	if t := stu.GetDeparture().GetTime(); t != 0 {
+		return t
+	}
+	arr, _, _ := stopTimeSeconds(st)
+	delay := stu.GetArrival().GetDelay()
+	if delay == 0 {
+		delay = stu.GetDeparture().GetDelay()
+	}
+	return dayStart.Unix() + int64(arr) + int64(delay)
+}
+
+// Observe takes arrivals from a poll's trip updates. A stop's arrival is the
+// last prediction made for it: once its predicted time has passed, or once
+// it drops out of a trip update that is still in the feed.
+func (s *OTPStore) Observe(snap *RealtimeSnapshot) {
+	now := snap.FetchedAt
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	defer s.save()
+
+	listed := make(map[otpKey]bool)
+	listedTrips := make(map[tripRun]bool)
+	for _, e := range snap.TripUpdates {
+		tu := e.GetTripUpdate()
+		switch tu.GetTrip().GetScheduleRelationship() {
+		case TripCanceled, TripDeleted:
+			continue
+		}
+		tripId := tu.GetTrip().GetTripId()
+		stopTimes, found := findStopTimesByTripID(tripId)
+		if !found {
+			continue
+		}
+		date := runDate(tu.GetTrip().GetStartDate(), stopTimes, now)
+		day, err := parseServiceDate(date)
+		if err != nil {
+			continue
+		}
+		dayStart := serviceDayStart(day)
+		listedTrips[tripRun{tripId: tripId, date: date}] = true
+
+		for _, stu := range tu.GetStopTimeUpdate() {
+			switch stu.GetScheduleRelationship() {
+			case StopTimeSkipped, StopTimeNoData:
+				continue
+			}
+			idx := matchStopTime(stopTimes, stu)
+			if idx < 0 {
+				continue
+			}
+			key := otpKey{tripId: tripId, date: date, seq: stopTimes[idx].GetStopSequence()}
+			listed[key] = true
+
+			predicted := predictedArrival(stu, stopTimes[idx], dayStart)
+			if predicted > now.Unix() {
+				s.pending[key] = pendingArrival{predicted: predicted, updatedAt: now}
+				continue
+			}
+			delete(s.pending, key)
+			if obs, ok := newOTPObservation(tripId, date, stopTimes, idx, time.Unix(predicted, 0), OTPSourcePrediction); ok {
+				s.record(obs)
+			}
+		}
+	}
+
+	for key, p := range s.pending {
+		if listed[key] {
+			continue
+		}
+		passed := listedTrips[tripRun{tripId: key.tripId, date: key.date}] || p.predicted <= now.Unix()
+		if !passed {
+			if now.Sub(p.updatedAt) > pendingArrivalTTL {
+				delete(s.pending, key)
+			}
+			continue
+		}
+		delete(s.pending, key)
+		stopTimes, _ := findStopTimesByTripID(key.tripId)
+		idx := slices.IndexFunc(stopTimes, func(st *protodata.StopTimeProto) bool { return st.GetStopSequence() == key.seq })
+		if idx < 0 {
+			continue
+		}
+		if obs, ok := newOTPObservation(key.tripId, key.date, stopTimes, idx, time.Unix(p.predicted, 0), OTPSourcePrediction); ok {
+			s.record(obs)
+		}
+	}
+
+	if now.Sub(s.lastPrune) > otpPruneInterval {
+		for key, obs := range s.observations {
+			if now.Sub(obs.Scheduled) > s.retention {
+				delete(s.observations, key)
+			}
+		}
+		s.lastPrune = now
+	}
+}
+
+// OTPQuery selects and groups observations. Dates are service dates,
+// YYYYMMDD, inclusive; empty means unbounded.
+type OTPQuery struct {
+	GroupBy      string
+	RouteId      string
+	StopId       string
+	From, To     string
+	EarlySeconds int
+	LateSeconds  int
+}
+
+// OTPGroup is on-time performance over a set of observations.
+type OTPGroup struct {
+	Key             string  `json:"key"`
+	Name            string  `json:"name,omitempty"`
+	Observations    int     `json:"observations"`
+	Early           int     `json:"early"`
+	OnTime          int     `json:"on_time"`
+	Late            int     `json:"late"`
+	OnTimePercent   float64 `json:"on_time_percent"`
+	AvgDelaySeconds float64 `json:"avg_delay_seconds"`
+
+	totalDelay int
+}
+
+func (g *OTPGroup) add(obs *OTPObservation, q OTPQuery) {
+	g.Observations++
+	g.totalDelay += obs.DelaySeconds
+	switch {
+	case obs.DelaySeconds < -q.EarlySeconds:
+		g.Early++
+	case obs.DelaySeconds > q.LateSeconds:
+		g.Late++
+	default:
+		g.OnTime++
+	}
+}
+
+func (g *OTPGroup) finish() {
+	if g.Observations > 0 {
+		g.OnTimePercent = math.Round(float64(g.OnTime)/float64(g.Observations)*1000) / 10
+		g.AvgDelaySeconds = math.Round(float64(g.totalDelay)/float64(g.Observations)*10) / 10
+	}
+}
+
+type OTPReport struct {
+	GroupBy      string     `json:"group_by"`
+	EarlySeconds int        `json:"early_seconds"`
+	LateSeconds  int        `json:"late_seconds"`
+	Total        OTPGroup   `json:"total"`
+	Groups       []OTPGroup `json:"groups"`
+}
+
+var otpGroupings = map[string]func(*OTPObservation) string{
+	"route": func(o *OTPObservation) string { return o.RouteId },
+	"stop":  func(o *OTPObservation) string { return o.StopId },
+	"hour":  func(o *OTPObservation) string { return o.Scheduled.In(agencyLocation).Format("15") },
+	"day":   func(o *OTPObservation) string { return o.ServiceDate },
+}
+
+// Query classifies the matching observations as early, on time or late and
+// totals them by group.
+func (s *OTPStore) Query(q OTPQuery) OTPReport {
+	keyOf := otpGroupings[q.GroupBy]
+	report := OTPReport{GroupBy: q.GroupBy, EarlySeconds: q.EarlySeconds, LateSeconds: q.LateSeconds, Total: OTPGroup{Key: "all"}}
+	groups := make(map[string]*OTPGroup)
+
+	s.mu.Lock()
+	for _, obs := range s.observations {
+		if q.RouteId != "" && obs.RouteId != q.RouteId || q.StopId != "" && obs.StopId != q.StopId {
+			continue
+		}
+		if q.From != "" && obs.ServiceDate < q.From || q.To != "" && obs.ServiceDate > q.To {
+			continue
+		}
+		key := keyOf(obs)
+		g, found := groups[key]
+		if !found {
+			g = &OTPGroup{Key: key}
+			groups[key] = g
+		}
+		g.add(obs, q)
+		report.Total.add(obs, q)
+	}
+	s.mu.Unlock()
+
+	report.Groups = make([]OTPGroup, 0, len(groups))
+	for _, g := range groups {
+		switch q.GroupBy {
+		case "route":
+			if route, found := findRouteByID(g.Key); found {
+				g.Name = route.GetRouteShortName()
+			}
+		case "stop":
+			if stop, found := findStopById(g.Key); found {
+				g.Name = stop.GetStopName()
+			}
+		}
+		g.finish()
+		report.Groups = append(report.Groups, *g)
+	}
+	report.Total.finish()
+	slices.SortFunc(report.Groups, func(a, b OTPGroup) int {
+		return naturalCompare(a.Key, b.Key)
+	})
+	return report
+}
+
+// GET /analytics/otp?group_by=route|stop|hour|day&route_id=&stop_id=&from=&to=&early_seconds=60&late_seconds=300
+func HandleOTP(c *gin.Context) {
+	q := OTPQuery{GroupBy: c.DefaultQuery("group_by", "route"), RouteId: c.Query("route_id"), StopId: c.Query("stop_id")}
+	if _, found := otpGroupings[q.GroupBy]; !found {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "group_by must be route, stop, hour or day"})
+		return
+	}
+
+	var err error
+	if q.EarlySeconds, err = queryInt(c, "early_seconds", defaultEarlySeconds, 0, 3600); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if q.LateSeconds, err = queryInt(c, "late_seconds", defaultLateSeconds, 0, 7200); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	for _, p := range []struct {
+		name string
+		dst  *string
+	}{{"from", &q.From}, {"to", &q.To}} {
+		if s := c.Query(p.name); s != "" {
+			d, err := parseServiceDate(s)
+			if err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": p.name + ": " + err.Error()})
+				return
+			}
+			*p.dst = serviceDate(d)
+		}
+	}
+
+	c.JSON(http.StatusOK, otpStore.Query(q))
+}
@@ -0,0 +1,309 @@
+package server
+
+import (
+	"container/heap"
+	"net/http"
+	"sort"
+	"strconv"
+	"studious-waffle/server/protodata"
+	"sync"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GTFS pathway_mode values.
+const (
+	PathwayWalkway        = 1
+	PathwayStairs         = 2
+	PathwayMovingSidewalk = 3
+	PathwayEscalator      = 4
+	PathwayElevator       = 5
+	PathwayFareGate       = 6
+	PathwayExitGate       = 7
+)
+
+var pathwayModeNames = map[int32]string{
+	PathwayWalkway:        "walkway",
+	PathwayStairs:         "stairs",
+	PathwayMovingSidewalk: "moving_sidewalk",
+	PathwayEscalator:      "escalator",
+	PathwayElevator:       "elevator",
+	PathwayFareGate:       "fare_gate",
+	PathwayExitGate:       "exit_gate",
+}
+
+const (
+	// elevatorSeconds covers waiting for and riding an elevator
+	elevatorSeconds = 60
+	// secondsPerStair is used for stairs with a stair_count but no length
+	secondsPerStair = 1
+	// defaultPathwaySeconds is used when nothing about a pathway's size is known
+	defaultPathwaySeconds = 30
+	// maxAccessibleSlope is the steepest ramp an accessible path may use (1:12)
+	maxAccessibleSlope = 1.0 / 12
+)
+
+// Levels
+func findLevelById(levelId string) (*protodata.LevelProto, bool) {
+	data := Levels
+	n := len(data)
+
+	idx := sort.Search(n, func(i int) bool {
+		return data[i].GetLevelId() >= levelId
+	})
+
+	if idx < n && data[idx].GetLevelId() == levelId {
+		return data[idx], true
+	}
+	return nil, false
+}
+
+// pathwaysAvailable reports whether the feed ships pathways.txt. The RTD
+// feed doesn't, in which case in-station paths can't be computed.
+func pathwaysAvailable() bool {
+	return len(Pathways) > 0
+}
+
+// pathwayEdge is a pathway as walked in one direction.
+type pathwayEdge struct {
+	pathway  *protodata.PathwayProto
+	to       string
+	reversed bool
+}
+
+// pathwayGraph maps each node to the pathways leaving it. Bidirectional
+// pathways appear under both of their ends.
+var pathwayGraph = sync.OnceValue(func() map[string][]pathwayEdge {
+	graph := make(map[string][]pathwayEdge)
+	for _, p := range Pathways {
+		graph[p.GetFromStopId()] = append(graph[p.GetFromStopId()], pathwayEdge{pathway: p, to: p.GetToStopId()})
+		if p.GetIsBidirectional() == 1 {
+			graph[p.GetToStopId()] = append(graph[p.GetToStopId()], pathwayEdge{pathway: p, to: p.GetFromStopId(), reversed: true})
+		}
+	}
+	return graph
+})
+
+// accessiblePathway reports whether a wheelchair user can take a pathway:
+// no stairs or escalators and no ramp steeper than 1:12.
+func accessiblePathway(p *protodata.PathwayProto) bool {
+	switch p.GetPathwayMode() {
+	case PathwayStairs, PathwayEscalator:
+		return false
+	}
+	slope := p.GetMaxSlope()
+	return slope <= maxAccessibleSlope && slope >= -maxAccessibleSlope
+}
+
+// pathwaySeconds is how long a pathway takes, estimated from its length,
+// stair count or end points when the feed doesn't say.
+func pathwaySeconds(p *protodata.PathwayProto) int {
+	switch {
+	case p.TraversalTime != nil:
+		return int(p.GetTraversalTime())
+	case p.GetPathwayMode() == PathwayElevator:
+		return elevatorSeconds
+	case p.Length != nil:
+		return int(p.GetLength()/walkingSpeed + 0.5)
+	case p.StairCount != nil:
+		return int(max(p.GetStairCount(), -p.GetStairCount())) * secondsPerStair
+	}
+
+	from, okFrom := findStopById(p.GetFromStopId())
+	to, okTo := findStopById(p.GetToStopId())
+	if okFrom && okTo && from.GetStopLat() != 0 && to.GetStopLat() != 0 {
+		d := haversineMeters(from.GetStopLat(), from.GetStopLon(), to.GetStopLat(), to.GetStopLon())
+		return int(d/walkingSpeed + 0.5)
+	}
+	return defaultPathwaySeconds
+}
+
+type PathStep struct {
+	PathwayId        string `json:"pathway_id"`
+	FromStopId       string `json:"from_stop_id"`
+	ToStopId         string `json:"to_stop_id"`
+	Mode             string `json:"mode"`
+	TraversalSeconds int    `json:"traversal_seconds"`
+	SignpostedAs     string `json:"signposted_as,omitempty"`
+	FromLevel        string `json:"from_level,omitempty"`
+	ToLevel          string `json:"to_level,omitempty"`
+}
+
+type StationPath struct {
+	Available        bool       `json:"available"`
+	StationId        string     `json:"station_id"`
+	FromStopId       string     `json:"from_stop_id"`
+	ToStopId         string     `json:"to_stop_id"`
+	Accessible       bool       `json:"accessible"`
+	TraversalSeconds int        `json:"traversal_seconds"`
+	LevelChanges     int        `json:"level_changes"`
+	Steps            []PathStep `json:"steps"`
+}
+
+type pathNode struct {
+	stopId  string
+	seconds int
+}
+
+type pathQueue []pathNode
+
+func (q pathQueue) Len() int           { return len(q) }
+func (q pathQueue) Less(i, j int) bool { return q[i].seconds < q[j].seconds }
+func (q pathQueue) Swap(i, j int)      { q[i], q[j] = q[j], q[i] }
+func (q *pathQueue) Push(x any)        { *q = append(*q, x.(pathNode)) }
+func (q *pathQueue) Pop() any {
+	old := *q
+	n := old[len(old)-1]
+	*q = old[:len(old)-1]
+	return n
+}
+
+// findStationPath is Dijkstra over the pathway graph, by traversal time.
+func findStationPath(fromStopId, toStopId string, accessible bool) ([]pathwayEdge, int, bool) {
+	graph := pathwayGraph()
+	best := map[string]int{fromStopId: 0}
+	via := make(map[string]pathwayEdge)
+
+	q := &pathQueue{{stopId: fromStopId}}
+	for q.Len() > 0 {
+		n := heap.Pop(q).(pathNode)
+		if n.seconds > best[n.stopId] {
+			continue
+		}
+		if n.stopId == toStopId {
+			break
+		}
+		for _, e := range graph[n.stopId] {
+			if accessible && !accessiblePathway(e.pathway) {
+				continue
+			}
+			s := n.seconds + pathwaySeconds(e.pathway)
+			if prev, seen := best[e.to]; seen && prev <= s {
+				continue
+			}
+			best[e.to] = s
+			via[e.to] = e
+			heap.Push(q, pathNode{stopId: e.to, seconds: s})
+		}
+	}
+
+	total, found := best[toStopId]
+	if !found {
+		return nil, 0, false
+	}
+	var edges []pathwayEdge
+	for at := toStopId; at != fromStopId; {
+		e := via[at]
+		edges = append(edges, e)
+		if e.reversed {
+			at = e.pathway.GetToStopId()
+		} else {
+			at = e.pathway.GetFromStopId()
+		}
+	}
+	for i, j := 0, len(edges)-1; i < j; i, j = i+1, j-1 {
+		edges[i], edges[j] = edges[j], edges[i]
+	}
+	return edges, total, true
+}
+
+// stopLevel returns the level a stop is on, if the feed says.
+func stopLevel(stopId string) (*protodata.LevelProto, bool) {
+	stop, found := findStopById(stopId)
+	if !found || stop.GetLevelId() == "" {
+		return nil, false
+	}
+	return findLevelById(stop.GetLevelId())
+}
+
+func buildStationPath(stationId, fromStopId, toStopId string, accessible bool, edges []pathwayEdge, total int) StationPath {
+	path := StationPath{
+		Available:        true,
+		StationId:        stationId,
+		FromStopId:       fromStopId,
+		ToStopId:         toStopId,
+		Accessible:       accessible,
+		TraversalSeconds: total,
+		Steps:            make([]PathStep, 0, len(edges)),
+	}
+
+	for _, e := range edges {
+		p := e.pathway
+		step := PathStep{
+			PathwayId:        p.GetPathwayId(),
+			FromStopId:       p.GetFromStopId(),
+			ToStopId:         p.GetToStopId(),
+			Mode:             pathwayModeNames[p.GetPathwayMode()],
+			TraversalSeconds: pathwaySeconds(p),
+			SignpostedAs:     p.GetSignpostedAs(),
+		}
+		if e.reversed {
+			step.FromStopId, step.ToStopId = step.ToStopId, step.FromStopId
+			step.SignpostedAs = p.GetReversedSignpostedAs()
+		}
+
+		from, okFrom := stopLevel(step.FromStopId)
+		to, okTo := stopLevel(step.ToStopId)
+		if okFrom {
+			step.FromLevel = from.GetLevelName()
+		}
+		if okTo {
+			step.ToLevel = to.GetLevelName()
+		}
+		if okFrom && okTo && from.GetLevelIndex() != to.GetLevelIndex() {
+			path.LevelChanges++
+		}
+		path.Steps = append(path.Steps, step)
+	}
+	return path
+}
+
+// GET /stations/:id/path?from=&to=&accessible=true
+func HandleStationPath(c *gin.Context) {
+	if !pathwaysAvailable() {
+		c.JSON(http.StatusNotFound, gin.H{
+			"available": false,
+			"error":     "In-station paths are not available: the feed has no pathways.txt",
+		})
+		return
+	}
+
+	fromId, toId := c.Query("from"), c.Query("to")
+	if fromId == "" || toId == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "from and to query params required"})
+		return
+	}
+	accessible := false
+	if s := c.Query("accessible"); s != "" {
+		b, err := strconv.ParseBool(s)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "accessible must be true or false"})
+			return
+		}
+		accessible = b
+	}
+
+	station, found := findStopById(c.Param("id"))
+	if !found || station.GetLocationType() != LocationStation {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Station not found"})
+		return
+	}
+	for _, id := range []string{fromId, toId} {
+		stop, found := findStopById(id)
+		if !found {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Stop " + id + " not found"})
+			return
+		}
+		if stationForStop(stop).GetStopId() != station.GetStopId() {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Stop " + id + " is not part of station " + station.GetStopId()})
+			return
+		}
+	}
+
+	edges, total, found := findStationPath(fromId, toId, accessible)
+	if !found {
+		c.JSON(http.StatusNotFound, gin.H{"available": true, "error": "No path between the two stops"})
+		return
+	}
+	c.JSON(http.StatusOK, buildStationPath(station.GetStopId(), fromId, toId, accessible, edges, total))
+}
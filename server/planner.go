@@ -0,0 +1,710 @@
+package server
+
+import (
+	"cmp"
+	"fmt"
+	"math"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"studious-waffle/server/protodata"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	// accessRadiusMeters is how far a rider will walk to the first stop or
+	// from the last one
+	accessRadiusMeters = 800
+	// footpathRadiusMeters is how far a rider will walk between stops to
+	// change vehicles
+	footpathRadiusMeters = 300
+	// maxDirectWalkMeters is the longest walk offered instead of transit
+	maxDirectWalkMeters  = 2000
+	defaultPlanTransfers = 3
+	maxPlanTransfers     = 6
+
+	unreached = math.MaxInt32
+)
+
+// raptorTrip is one run of a trip, with times in seconds from the start of
+// the service day being planned.
+type raptorTrip struct {
+	trip *protodata.TripProto
+	// date is the service date the run belongs to
+	date     string
+	arr, dep []int
+	// seqs are the stop_sequence of each position, for matching realtime
+	// updates
+	seqs []int32
+	// skip marks stops a realtime update says the trip won't serve; nil
+	// when it serves them all
+	skip []bool
+	// live is set on runs whose times come from a realtime update
+	live bool
+}
+
+func (t *raptorTrip) skips(pos int) bool {
+	return t.skip != nil && t.skip[pos]
+}
+
+// raptorRoute is a set of trips with the same stop pattern that never
+// overtake each other, so the first trip leaving a stop after a given time
+// is also the first to reach every later stop.
+type raptorRoute struct {
+	stops []int
+	trips []raptorTrip
+}
+
+// earliestTrip returns the first trip leaving position pos at or after t
+// that stops there.
+func (r *raptorRoute) earliestTrip(pos, t int) int {
+	i, _ := slices.BinarySearchFunc(r.trips, t, func(trip raptorTrip, t int) int {
+		return cmp.Compare(trip.dep[pos], t)
+	})
+	for i < len(r.trips) && r.trips[i].skips(pos) {
+		i++
+	}
+	if i == len(r.trips) {
+		return -1
+	}
+	return i
+}
+
+type routePosition struct {
+	route, pos int
+}
+
+type footpath struct {
+	to      int
+	seconds int
+}
+
+// raptorData is the schedule of one service day laid out for RAPTOR. Stops
+// are indexes into Stops.
+type raptorData struct {
+	date       string
+	dayStart   time.Time
+	routes     []raptorRoute
+	stopRoutes [][]routePosition
+	// runs locates each trip run in the routes built from the schedule, or
+	// holds -1 for frequency-based trips whose runs can't be told apart
+	runs map[tripRun]int
+	// realtime is set on copies patched with realtime updates
+	realtime bool
+}
+
+// tripRun names one run of a trip: the trip on a service date.
+type tripRun struct {
+	tripId, date string
+}
+
+// footpaths links every stop to those within walking distance, dropping
+// pairs transfers.txt marks as impossible and raising walks to any
+// min_transfer_time it sets. Rules for changing at the same stop are applied
+// when boarding instead.
+var footpaths = sync.OnceValue(func() [][]footpath {
+	paths := make([][]footpath, len(Stops))
+	for i, s := range Stops {
+		for _, n := range nearbyStops(s.GetStopLat(), s.GetStopLon(), footpathRadiusMeters) {
+			if n.stop == i {
+				continue
+			}
+			seconds := walkSeconds(n.meters)
+			to := Stops[n.stop]
+			if t, found := transferRule(TransferLeg{StopId: s.GetStopId()}, TransferLeg{StopId: to.GetStopId()}); found {
+				if t.GetTransferType() == TransferNotPossible {
+					continue
+				}
+				seconds = max(seconds, int(t.GetMinTransferTime()))
+			}
+			paths[i] = append(paths[i], footpath{to: n.stop, seconds: seconds})
+		}
+	}
+	return paths
+})
+
+// buildRaptorData lays out the trips running on a service date. Trips from
+// the previous service day still running after midnight are included, their
+// times shifted back a day.
+func buildRaptorData(dayStart time.Time) *raptorData {
+	date := serviceDate(dayStart)
+	prevDate := serviceDate(serviceDayStart(dayStart.Add(-12 * time.Hour)))
+	const day = 24 * 3600
+
+	patterns := make(map[string][]raptorTrip)
+	patternStops := make(map[string][]int)
+
+	for i := 0; i < len(StopTimesByTrip); {
+		j := i
+		for j < len(StopTimesByTrip) && StopTimesByTrip[j].GetTripId() == StopTimesByTrip[i].GetTripId() {
+			j++
+		}
+		stopTimes := StopTimesByTrip[i:j]
+		i = j
+
+		trip, found := findTripByID(stopTimes[0].GetTripId())
+		if !found {
+			continue
+		}
+		runsToday := serviceRunsOn(trip.GetServiceId(), date)
+		runsYesterday := serviceRunsOn(trip.GetServiceId(), prevDate)
+		if !runsToday && !runsYesterday {
+			continue
+		}
+
+		stops := make([]int, 0, len(stopTimes))
+		arr := make([]int, 0, len(stopTimes))
+		dep := make([]int, 0, len(stopTimes))
+		seqs := make([]int32, 0, len(stopTimes))
+		var key strings.Builder
+		ok := true
+		for _, st := range stopTimes {
+			idx, found := stopPosition(st.GetStopId())
+			a, d, timed := stopTimeSeconds(st)
+			if !found || !timed {
+				// untimed stops can't be boarded or alighted on a schedule
+				continue
+			}
+			if len(dep) > 0 && a < dep[len(dep)-1] {
+				ok = false
+				break
+			}
+			stops = append(stops, idx)
+			arr = append(arr, a)
+			dep = append(dep, d)
+			seqs = append(seqs, st.GetStopSequence())
+			fmt.Fprintf(&key, "%d,", idx)
+		}
+		if !ok || len(stops) < 2 {
+			continue
+		}
+
+		for _, run := range tripInstances(trip.GetTripId(), stopTimes) {
+			for _, shift := range []int{0, -day} {
+				if (shift == 0 && !runsToday) || (shift != 0 && (!runsYesterday || arr[len(arr)-1]+run.Shift < day)) {
+					continue
+				}
+				rt := raptorTrip{trip: trip, date: date, arr: make([]int, len(arr)), dep: make([]int, len(dep)), seqs: seqs}
+				if shift != 0 {
+					rt.date = prevDate
+				}
+				for k := range arr {
+					rt.arr[k] = arr[k] + run.Shift + shift
+					rt.dep[k] = dep[k] + run.Shift + shift
+				}
+				patterns[key.String()] = append(patterns[key.String()], rt)
+				patternStops[key.String()] = stops
+			}
+		}
+	}
+
+	data := &raptorData{date: date, dayStart: dayStart, stopRoutes: make([][]routePosition, len(Stops)), runs: make(map[tripRun]int)}
+	keys := make([]string, 0, len(patterns))
+	for k := range patterns {
+		keys = append(keys, k)
+	}
+	slices.Sort(keys)
+	for _, k := range keys {
+		for _, trips := range splitOvertaking(patterns[k]) {
+			data.addRoute(raptorRoute{stops: patternStops[k], trips: trips})
+		}
+	}
+	return data
+}
+
+// splitOvertaking divides a pattern's trips into groups in which no trip
+// overtakes another, as RAPTOR's trip search assumes.
+func splitOvertaking(trips []raptorTrip) [][]raptorTrip {
+	slices.SortFunc(trips, func(a, b raptorTrip) int {
+		return cmp.Compare(a.dep[0], b.dep[0])
+	})
+
+	var groups [][]raptorTrip
+	for _, t := range trips {
+		placed := false
+		for g, group := range groups {
+			last := group[len(group)-1]
+			fifo := true
+			for k := range t.dep {
+				if t.dep[k] < last.dep[k] || t.arr[k] < last.arr[k] {
+					fifo = false
+					break
+				}
+			}
+			if fifo {
+				groups[g] = append(group, t)
+				placed = true
+				break
+			}
+		}
+		if !placed {
+			groups = append(groups, []raptorTrip{t})
+		}
+	}
+	return groups
+}
+
+func (d *raptorData) addRoute(r raptorRoute) {
+	idx := len(d.routes)
+	d.routes = append(d.routes, r)
+	for pos, stop := range r.stops {
+		d.stopRoutes[stop] = append(d.stopRoutes[stop], routePosition{route: idx, pos: pos})
+	}
+	for _, t := range r.trips {
+		run := tripRun{tripId: t.trip.GetTripId(), date: t.date}
+		if _, seen := d.runs[run]; seen {
+			d.runs[run] = -1
+		} else {
+			d.runs[run] = idx
+		}
+	}
+}
+
+// stopPosition is the position of a stop in Stops.
+func stopPosition(stopId string) (int, bool) {
+	i, found := slices.BinarySearchFunc(Stops, stopId, func(s *protodata.StopProto, id string) int {
+		return cmp.Compare(s.GetStopId(), id)
+	})
+	return i, found
+}
+
+type stopWalk struct {
+	stop    int
+	seconds int
+	meters  float64
+}
+
+// transitLabel records how a stop was reached on a vehicle in a round.
+type transitLabel struct {
+	route, trip         int
+	boardPos, alightPos int
+}
+
+// raptorSearch is one run of RAPTOR: round k finds the earliest arrival at
+// every stop using k vehicles. Arrivals by vehicle and after a footpath are
+// kept apart so footpaths never chain.
+type raptorSearch struct {
+	data *raptorData
+	// arrival by vehicle in round k, and how
+	transitArr   [][]int
+	transitLabel [][]transitLabel
+	// best arrival in round k including a footpath; walkFrom is the stop
+	// walked from, or -1 when the arrival is by vehicle
+	arr      [][]int
+	walkFrom [][]int
+	walkSecs [][]int
+	access   map[int]stopWalk
+}
+
+func newRaptorSearch(data *raptorData, rounds int) *raptorSearch {
+	n := len(Stops)
+	s := &raptorSearch{data: data, access: make(map[int]stopWalk)}
+	for range rounds + 1 {
+		s.transitArr = append(s.transitArr, filled(n, unreached))
+		s.transitLabel = append(s.transitLabel, make([]transitLabel, n))
+		s.arr = append(s.arr, filled(n, unreached))
+		s.walkFrom = append(s.walkFrom, filled(n, -1))
+		s.walkSecs = append(s.walkSecs, make([]int, n))
+	}
+	return s
+}
+
+func filled(n, v int) []int {
+	s := make([]int, n)
+	for i := range s {
+		s[i] = v
+	}
+	return s
+}
+
+// destination is the best way to finish from round k's arrivals: the stop
+// to walk from and the arrival at the destination.
+func (s *raptorSearch) destination(k int, egress []stopWalk) (stopWalk, int) {
+	best, bestArr := stopWalk{stop: -1}, unreached
+	for _, e := range egress {
+		if t := s.transitArr[k][e.stop]; t != unreached && t+e.seconds < bestArr {
+			best, bestArr = e, t+e.seconds
+		}
+	}
+	return best, bestArr
+}
+
+// boardTrip is the first trip of route a rider reaching position pos at
+// prev in round k-1 can board there. A rider who got off a vehicle at the
+// stop is held to the transfers.txt rules for changing at it.
+func (s *raptorSearch) boardTrip(k int, route *raptorRoute, pos, prev int) int {
+	t := route.earliestTrip(pos, prev)
+	p := route.stops[pos]
+	if k == 1 || s.walkFrom[k-1][p] >= 0 {
+		return t
+	}
+
+	l := s.transitLabel[k-1][p]
+	alighted := s.data.routes[l.route].trips[l.trip].trip
+	from := TransferLeg{StopId: Stops[p].GetStopId(), RouteId: alighted.GetRouteId(), TripId: alighted.GetTripId()}
+	for ; t >= 0 && t < len(route.trips); t++ {
+		if route.trips[t].skips(pos) {
+			continue
+		}
+		next := route.trips[t].trip
+		rule, found := transferRule(from, TransferLeg{StopId: from.StopId, RouteId: next.GetRouteId(), TripId: next.GetTripId()})
+		if !found {
+			return t
+		}
+		if rule.GetTransferType() != TransferNotPossible && route.trips[t].dep[pos] >= prev+int(rule.GetMinTransferTime()) {
+			return t
+		}
+	}
+	return -1
+}
+
+type planResult struct {
+	round   int
+	egress  stopWalk
+	arrival int
+}
+
+// run searches from the access stops at depart and returns the Pareto set of
+// arrivals at the destination by number of vehicles used. Nothing arriving
+// at or after limit is explored.
+func (s *raptorSearch) run(access, egress []stopWalk, depart, rounds, limit int) []planResult {
+	best := filled(len(Stops), unreached)
+	marked := make(map[int]bool)
+	for _, a := range access {
+		t := depart + a.seconds
+		if t < s.arr[0][a.stop] {
+			s.arr[0][a.stop] = t
+			best[a.stop] = t
+			s.access[a.stop] = a
+			marked[a.stop] = true
+		}
+	}
+
+	var results []planResult
+	target := limit
+	for k := 1; k <= rounds && len(marked) > 0; k++ {
+		queue := make(map[int]int)
+		for p := range marked {
+			for _, rp := range s.data.stopRoutes[p] {
+				if pos, queued := queue[rp.route]; !queued || rp.pos < pos {
+					queue[rp.route] = rp.pos
+				}
+			}
+		}
+		marked = make(map[int]bool)
+
+		for r, start := range queue {
+			route := &s.data.routes[r]
+			trip, boardPos := -1, 0
+			for pos := start; pos < len(route.stops); pos++ {
+				p := route.stops[pos]
+				if trip >= 0 && !route.trips[trip].skips(pos) {
+					if t := route.trips[trip].arr[pos]; t < min(best[p], target) {
+						s.transitArr[k][p] = t
+						s.transitLabel[k][p] = transitLabel{route: r, trip: trip, boardPos: boardPos, alightPos: pos}
+						s.arr[k][p] = t
+						s.walkFrom[k][p] = -1
+						best[p] = t
+						marked[p] = true
+					}
+				}
+				if prev := s.arr[k-1][p]; prev != unreached && (trip < 0 || prev <= route.trips[trip].dep[pos]) {
+					if t := s.boardTrip(k, route, pos, prev); t >= 0 && (trip < 0 || route.trips[t].dep[pos] < route.trips[trip].dep[pos]) {
+						trip, boardPos = t, pos
+					}
+				}
+			}
+		}
+
+		walked := make(map[int]bool)
+		for p := range marked {
+			for _, fp := range footpaths()[p] {
+				if t := s.transitArr[k][p] + fp.seconds; t < min(best[fp.to], target) {
+					s.arr[k][fp.to] = t
+					s.walkFrom[k][fp.to] = p
+					s.walkSecs[k][fp.to] = fp.seconds
+					best[fp.to] = t
+					walked[fp.to] = true
+				}
+			}
+		}
+		for p := range walked {
+			marked[p] = true
+		}
+
+		if e, t := s.destination(k, egress); t < target {
+			target = t
+			results = append(results, planResult{round: k, egress: e, arrival: t})
+		}
+	}
+	return results
+}
+
+type PlanPlace struct {
+	StopId string  `json:"stop_id,omitempty"`
+	Name   string  `json:"name"`
+	Lat    float64 `json:"lat"`
+	Lon    float64 `json:"lon"`
+}
+
+type PlanLeg struct {
+	Mode           string    `json:"mode"`
+	From           PlanPlace `json:"from"`
+	To             PlanPlace `json:"to"`
+	Departure      time.Time `json:"departure"`
+	Arrival        time.Time `json:"arrival"`
+	DistanceMeters float64   `json:"distance_meters,omitempty"`
+	RouteId        string    `json:"route_id,omitempty"`
+	RouteShortName string    `json:"route_short_name,omitempty"`
+	TripId         string    `json:"trip_id,omitempty"`
+	TripHeadsign   string    `json:"trip_headsign,omitempty"`
+	// stops ridden past between boarding and alighting
+	IntermediateStops int `json:"intermediate_stops,omitempty"`
+	// set when the leg's times are predictions rather than the schedule
+	Realtime bool `json:"realtime,omitempty"`
+}
+
+type Itinerary struct {
+	Departure       time.Time `json:"departure"`
+	Arrival         time.Time `json:"arrival"`
+	DurationSeconds int       `json:"duration_seconds"`
+	Transfers       int       `json:"transfers"`
+	WalkSeconds     int       `json:"walk_seconds"`
+	Legs            []PlanLeg `json:"legs"`
+}
+
+type Plan struct {
+	From        PlanPlace   `json:"from"`
+	To          PlanPlace   `json:"to"`
+	Depart      time.Time   `json:"depart"`
+	Date        string      `json:"date"`
+	Realtime    bool        `json:"realtime"`
+	Itineraries []Itinerary `json:"itineraries"`
+}
+
+func stopPlace(i int) PlanPlace {
+	s := Stops[i]
+	return PlanPlace{StopId: s.GetStopId(), Name: s.GetStopName(), Lat: s.GetStopLat(), Lon: s.GetStopLon()}
+}
+
+func (s *raptorSearch) at(secs int) time.Time {
+	return s.data.dayStart.Add(time.Duration(secs) * time.Second)
+}
+
+// itinerary walks the labels back from the destination to the origin.
+func (s *raptorSearch) itinerary(from, to PlanPlace, res planResult) Itinerary {
+	var legs []PlanLeg
+	stop := res.egress.stop
+	legs = append(legs, PlanLeg{
+		Mode:           "walk",
+		From:           stopPlace(stop),
+		To:             to,
+		Departure:      s.at(res.arrival - res.egress.seconds),
+		Arrival:        s.at(res.arrival),
+		DistanceMeters: res.egress.meters,
+	})
+
+	for k := res.round; k > 0; k-- {
+		// destination finishes from round k's arrival by vehicle, even when a
+		// footpath later reached the stop sooner, so only earlier rounds can
+		// have walked to it
+		if f := s.walkFrom[k][stop]; f >= 0 && k < res.round {
+			dep := s.transitArr[k][f]
+			legs = append(legs, PlanLeg{
+				Mode:           "walk",
+				From:           stopPlace(f),
+				To:             stopPlace(stop),
+				Departure:      s.at(dep),
+				Arrival:        s.at(dep + s.walkSecs[k][stop]),
+				DistanceMeters: haversineMeters(Stops[f].GetStopLat(), Stops[f].GetStopLon(), Stops[stop].GetStopLat(), Stops[stop].GetStopLon()),
+			})
+			stop = f
+		}
+
+		l := s.transitLabel[k][stop]
+		route := &s.data.routes[l.route]
+		trip := route.trips[l.trip]
+		leg := PlanLeg{
+			Mode:              "transit",
+			From:              stopPlace(route.stops[l.boardPos]),
+			To:                stopPlace(stop),
+			Departure:         s.at(trip.dep[l.boardPos]),
+			Arrival:           s.at(trip.arr[l.alightPos]),
+			RouteId:           trip.trip.GetRouteId(),
+			TripId:            trip.trip.GetTripId(),
+			TripHeadsign:      trip.trip.GetTripHeadsign(),
+			IntermediateStops: l.alightPos - l.boardPos - 1,
+			Realtime:          trip.live,
+		}
+		if r, found := findRouteByID(leg.RouteId); found {
+			leg.RouteShortName = r.GetRouteShortName()
+		}
+		legs = append(legs, leg)
+		stop = route.stops[l.boardPos]
+	}
+
+	a := s.access[stop]
+	legs = append(legs, PlanLeg{
+		Mode:           "walk",
+		From:           from,
+		To:             stopPlace(stop),
+		Departure:      s.at(s.arr[0][stop] - a.seconds),
+		Arrival:        s.at(s.arr[0][stop]),
+		DistanceMeters: a.meters,
+	})
+	slices.Reverse(legs)
+	return newItinerary(legs, res.round-1)
+}
+
+// newItinerary totals up legs, dropping zero-length walks.
+func newItinerary(legs []PlanLeg, transfers int) Itinerary {
+	legs = slices.DeleteFunc(legs, func(l PlanLeg) bool {
+		return l.Mode == "walk" && l.Departure.Equal(l.Arrival)
+	})
+	it := Itinerary{
+		Departure: legs[0].Departure,
+		Arrival:   legs[len(legs)-1].Arrival,
+		Transfers: transfers,
+		Legs:      legs,
+	}
+	it.DurationSeconds = int(it.Arrival.Sub(it.Departure).Seconds())
+	for _, l := range legs {
+		if l.Mode == "walk" {
+			it.WalkSeconds += int(l.Arrival.Sub(l.Departure).Seconds())
+		}
+	}
+	return it
+}
+
+func stopWalks(lat, lon float64) []stopWalk {
+	var walks []stopWalk
+	for _, n := range nearbyStops(lat, lon, accessRadiusMeters) {
+		walks = append(walks, stopWalk{stop: n.stop, seconds: walkSeconds(n.meters), meters: n.meters})
+	}
+	return walks
+}
+
+// planJourney finds the itineraries from one point to another leaving at
+// depart that are Pareto-optimal by arrival time and number of transfers:
+// each uses more vehicles only to arrive earlier. A walk all the way is the
+// zero-vehicle option when the points are close enough. Journeys stay within
+// the service day of depart, so a late-night plan won't use the first trips
+// of the next one.
+func planJourney(data *raptorData, from, to PlanPlace, depart time.Time, maxTransfers int) []Itinerary {
+	departSecs := int(depart.Sub(data.dayStart).Seconds())
+	itineraries := make([]Itinerary, 0)
+
+	target := unreached
+	if d := haversineMeters(from.Lat, from.Lon, to.Lat, to.Lon); d <= maxDirectWalkMeters {
+		secs := walkSeconds(d)
+		target = departSecs + secs
+		itineraries = append(itineraries, Itinerary{
+			Departure:       depart,
+			Arrival:         depart.Add(time.Duration(secs) * time.Second),
+			DurationSeconds: secs,
+			WalkSeconds:     secs,
+			Legs: []PlanLeg{{
+				Mode: "walk", From: from, To: to, DistanceMeters: d,
+				Departure: depart, Arrival: depart.Add(time.Duration(secs) * time.Second),
+			}},
+		})
+	}
+
+	search := newRaptorSearch(data, maxTransfers+1)
+	for _, res := range search.run(stopWalks(from.Lat, from.Lon), stopWalks(to.Lat, to.Lon), departSecs, maxTransfers+1, target) {
+		itineraries = append(itineraries, search.itinerary(from, to, res))
+	}
+	return itineraries
+}
+
+// parseLatLon reads "lat,lon".
+func parseLatLon(s string) (float64, float64, error) {
+	parts := strings.Split(s, ",")
+	if len(parts) != 2 {
+		return 0, 0, fmt.Errorf("%q must be lat,lon", s)
+	}
+	lat, err1 := strconv.ParseFloat(strings.TrimSpace(parts[0]), 64)
+	lon, err2 := strconv.ParseFloat(strings.TrimSpace(parts[1]), 64)
+	if err1 != nil || err2 != nil || lat < -90 || lat > 90 || lon < -180 || lon > 180 {
+		return 0, 0, fmt.Errorf("%q must be lat,lon", s)
+	}
+	return lat, lon, nil
+}
+
+// parseDepart reads an RFC 3339 time, or a local "2006-01-02T15:04" or
+// "15:04" (today) in the agency's timezone.
+func parseDepart(s string, now time.Time) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return t, nil
+	}
+	if t, err := time.ParseInLocation("2006-01-02T15:04", s, agencyLocation); err == nil {
+		return t, nil
+	}
+	if t, err := time.ParseInLocation("15:04", s, agencyLocation); err == nil {
+		y, m, d := now.In(agencyLocation).Date()
+		return time.Date(y, m, d, t.Hour(), t.Minute(), 0, 0, agencyLocation), nil
+	}
+	return time.Time{}, fmt.Errorf("depart must be RFC 3339, YYYY-MM-DDTHH:MM or HH:MM")
+}
+
+type planRequest struct {
+	from, to     PlanPlace
+	depart       time.Time
+	maxTransfers int
+	realtime     bool
+}
+
+func planRequestFromQuery(c *gin.Context) (planRequest, error) {
+	var req planRequest
+	fromLat, fromLon, err := parseLatLon(c.Query("from"))
+	if err != nil {
+		return req, fmt.Errorf("from: %w", err)
+	}
+	toLat, toLon, err := parseLatLon(c.Query("to"))
+	if err != nil {
+		return req, fmt.Errorf("to: %w", err)
+	}
+	req.from = PlanPlace{Name: "Origin", Lat: fromLat, Lon: fromLon}
+	req.to = PlanPlace{Name: "Destination", Lat: toLat, Lon: toLon}
+
+	req.depart = time.Now()
+	if s := c.Query("depart"); s != "" {
+		if req.depart, err = parseDepart(s, time.Now()); err != nil {
+			return req, err
+		}
+	}
+	req.maxTransfers, err = queryInt(c, "max_transfers", defaultPlanTransfers, 0, maxPlanTransfers)
+	if err != nil {
+		return req, err
+	}
+	req.realtime = true
+	if s := c.Query("realtime"); s != "" {
+		if req.realtime, err = strconv.ParseBool(s); err != nil {
+			return req, fmt.Errorf("realtime must be true or false")
+		}
+	}
+	return req, nil
+}
+
+// GET /plan?from=lat,lon&to=lat,lon&depart=&max_transfers=&realtime=false
+func HandlePlan(c *gin.Context) {
+	req, err := planRequestFromQuery(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	data := plannerCache.get(serviceDayStart(req.depart), req.realtime)
+	c.JSON(http.StatusOK, Plan{
+		From:        req.from,
+		To:          req.to,
+		Depart:      req.depart,
+		Date:        data.date,
+		Realtime:    data.realtime,
+		Itineraries: planJourney(data, req.from, req.to, req.depart, req.maxTransfers),
+	})
+}
@@ -0,0 +1,158 @@
+package server
+
+import (
+	"fmt"
+	"studious-waffle/server/protodata"
+	"testing"
+	"time"
+
+	"google.golang.org/protobuf/proto"
+)
+
+// usePlannerStops swaps in four stops, a to d, with the given footpaths and
+// transfers.txt rules for the length of a test.
+func usePlannerStops(t *testing.T, paths map[int][]footpath, transfers ...*protodata.TransferProto) {
+	t.Helper()
+	stops, rules, routes, walks := Stops, Transfers, Routes, footpaths
+	t.Cleanup(func() {
+		Stops, Transfers, Routes, footpaths = stops, rules, routes, walks
+	})
+
+	Stops = nil
+	for i, id := range []string{"a", "b", "c", "d"} {
+		Stops = append(Stops, &protodata.StopProto{
+			StopId:  proto.String(id),
+			StopLat: proto.Float64(39.74 + float64(i)*0.01),
+			StopLon: proto.Float64(-104.99),
+		})
+	}
+	Transfers, Routes = transfers, nil
+	footpaths = func() [][]footpath {
+		all := make([][]footpath, len(Stops))
+		for from, fps := range paths {
+			all[from] = fps
+		}
+		return all
+	}
+}
+
+// testRoute is a route over stops with one trip per entry in deps, each
+// taking ten minutes between stops and given as seconds at the first stop.
+func testRoute(stops []int, deps map[string]int) raptorRoute {
+	r := raptorRoute{stops: stops}
+	for id, first := range deps {
+		trip := raptorTrip{trip: &protodata.TripProto{TripId: proto.String(id)}}
+		for pos := range stops {
+			trip.arr = append(trip.arr, first+pos*600)
+			trip.dep = append(trip.dep, first+pos*600)
+		}
+		r.trips = append(r.trips, trip)
+	}
+	// trips leave in order, as earliestTrip expects
+	for i := 1; i < len(r.trips); i++ {
+		for j := i; j > 0 && r.trips[j].dep[0] < r.trips[j-1].dep[0]; j-- {
+			r.trips[j], r.trips[j-1] = r.trips[j-1], r.trips[j]
+		}
+	}
+	return r
+}
+
+func testRaptorData(routes ...raptorRoute) *raptorData {
+	d := &raptorData{
+		dayStart:   time.Date(2026, 10, 19, 0, 0, 0, 0, time.UTC),
+		stopRoutes: make([][]routePosition, len(Stops)),
+		runs:       make(map[tripRun]int),
+	}
+	for _, r := range routes {
+		d.addRoute(r)
+	}
+	return d
+}
+
+func TestRaptorTwoRounds(t *testing.T) {
+	const a, b, c, d = 0, 1, 2, 3
+	clock := func(h, m int) int { return h*3600 + m*60 }
+
+	tests := []struct {
+		name      string
+		paths     map[int][]footpath
+		transfers []*protodata.TransferProto
+		routes    []raptorRoute
+		// legs as "mode from-to trip"
+		want    []string
+		arrival int
+	}{
+		{
+			name:  "walk between stops to change",
+			paths: map[int][]footpath{b: {{to: c, seconds: 120}}},
+			routes: []raptorRoute{
+				testRoute([]int{a, b}, map[string]int{"first": clock(8, 0)}),
+				// "gone" leaves c before the walk from b gets there
+				testRoute([]int{c, d}, map[string]int{"gone": clock(8, 11), "second": clock(8, 15)}),
+			},
+			want:    []string{"walk -a ", "transit a-b first", "walk b-c ", "transit c-d second", "walk d- "},
+			arrival: clock(8, 26),
+		},
+		{
+			name: "minimum transfer time at the same stop",
+			transfers: []*protodata.TransferProto{{
+				FromStopId: proto.String("b"), ToStopId: proto.String("b"),
+				TransferType: proto.Int32(TransferMinimumTime), MinTransferTime: proto.Int32(300),
+			}},
+			routes: []raptorRoute{
+				testRoute([]int{a, b}, map[string]int{"first": clock(8, 0)}),
+				testRoute([]int{b, d}, map[string]int{"tight": clock(8, 12), "second": clock(8, 20)}),
+			},
+			want:    []string{"walk -a ", "transit a-b first", "transit b-d second", "walk d- "},
+			arrival: clock(8, 31),
+		},
+		{
+			name: "transfer not possible to a trip",
+			transfers: []*protodata.TransferProto{{
+				FromStopId: proto.String("b"), ToStopId: proto.String("b"), ToTripId: proto.String("tight"),
+				TransferType: proto.Int32(TransferNotPossible),
+			}},
+			routes: []raptorRoute{
+				testRoute([]int{a, b}, map[string]int{"first": clock(8, 0)}),
+				testRoute([]int{b, d}, map[string]int{"tight": clock(8, 12), "second": clock(8, 20)}),
+			},
+			want:    []string{"walk -a ", "transit a-b first", "transit b-d second", "walk d- "},
+			arrival: clock(8, 31),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			usePlannerStops(t, tt.paths, tt.transfers...)
+			data := testRaptorData(tt.routes...)
+
+			s := newRaptorSearch(data, 3)
+			access := []stopWalk{{stop: a, seconds: 60}}
+			egress := []stopWalk{{stop: d, seconds: 60}}
+			results := s.run(access, egress, clock(7, 55), 3, unreached)
+			if len(results) != 1 {
+				t.Fatalf("got %d results, want 1", len(results))
+			}
+			if results[0].round != 2 {
+				t.Errorf("got %d vehicles, want 2", results[0].round)
+			}
+			if results[0].arrival != tt.arrival {
+				t.Errorf("got arrival %d, want %d", results[0].arrival, tt.arrival)
+			}
+
+			it := s.itinerary(PlanPlace{Name: "origin"}, PlanPlace{Name: "destination"}, results[0])
+			var got []string
+			for _, l := range it.Legs {
+				got = append(got, fmt.Sprintf("%s %s-%s %s", l.Mode, l.From.StopId, l.To.StopId, l.TripId))
+			}
+			if fmt.Sprint(got) != fmt.Sprint(tt.want) {
+				t.Errorf("got legs %q, want %q", got, tt.want)
+			}
+			if it.Transfers != 1 {
+				t.Errorf("got %d transfers, want 1", it.Transfers)
+			}
+			if want := data.dayStart.Add(time.Duration(tt.arrival) * time.Second); !it.Arrival.Equal(want) {
+				t.Errorf("itinerary arrives at %v, want %v", it.Arrival, want)
+			}
+		})
+	}
+}
@@ -0,0 +1,277 @@
+package server
+
+import (
+	"slices"
+	"studious-waffle/server/protodata"
+	"sync"
+	"time"
+
+	"google.golang.org/protobuf/proto"
+)
+
+// realtimeHorizon is how far ahead predictions are trusted. Later stops keep
+// their scheduled times: a delay now says little about a trip's last stop
+// three hours out.
+const realtimeHorizon = 3 * time.Hour
+
+// planDay is the planner's data for one service day: the schedule as built
+// and a copy patched with the latest realtime updates.
+type planDay struct {
+	static *raptorData
+	live   *raptorData
+	// applied is the update each patched run was last patched with
+	applied map[tripRun]*protodata.TripUpdateProto
+	// overflow lists, per static route, the extra live routes holding trips
+	// that overtake each other once delayed
+	overflow map[int][]int
+}
+
+// planCache keeps the last few service days built, since most requests
+// plan for today, and patches them on every realtime poll.
+type planCache struct {
+	mu   sync.Mutex
+	days []*planDay
+}
+
+var plannerCache = &planCache{}
+
+// get returns the planner data for a service day, patched with realtime
+// updates when live is set.
+func (c *planCache) get(dayStart time.Time, live bool) *raptorData {
+	date := serviceDate(dayStart)
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	var day *planDay
+	for _, d := range c.days {
+		if d.static.date == date {
+			day = d
+			break
+		}
+	}
+	if day == nil {
+		static := buildRaptorData(dayStart)
+		day = &planDay{static: static, live: static, applied: make(map[tripRun]*protodata.TripUpdateProto), overflow: make(map[int][]int)}
+		realtime.mu.RLock()
+		snap := realtime.latest
+		realtime.mu.RUnlock()
+		if snap != nil {
+			day.apply(realtimeTripRuns(snap), snap.FetchedAt)
+		}
+
+		c.days = append(c.days, day)
+		if len(c.days) > config.Cache.PlannerDays {
+			c.days = c.days[1:]
+		}
+	}
+
+	if live {
+		return day.live
+	}
+	return day.static
+}
+
+// Observe patches every cached day with a realtime poll.
+func (c *planCache) Observe(snap *RealtimeSnapshot) {
+	runs := realtimeTripRuns(snap)
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, d := range c.days {
+		d.apply(runs, snap.FetchedAt)
+	}
+}
+
+// realtimeTripRuns keys a poll's trip updates by the run they describe. An
+// update without a start_date is for the run closest to the poll.
+func realtimeTripRuns(snap *RealtimeSnapshot) map[tripRun]*protodata.TripUpdateProto {
+	runs := make(map[tripRun]*protodata.TripUpdateProto)
+	for _, e := range snap.TripUpdates {
+		tu := e.GetTripUpdate()
+		tripId := tu.GetTrip().GetTripId()
+		if tripId == "" {
+			continue
+		}
+		date := tu.GetTrip().GetStartDate()
+		if date == "" {
+			stopTimes, found := findStopTimesByTripID(tripId)
+			if !found {
+				continue
+			}
+			date = serviceDate(resolveServiceDay(stopTimes, snap.FetchedAt))
+		}
+		runs[tripRun{tripId: tripId, date: date}] = tu
+	}
+	return runs
+}
+
+// apply brings the live copy up to date with a poll. Only the routes whose
+// trips' updates changed since the last poll are rebuilt; the rest are
+// shared with the previous copy, which requests already planning on keep
+// using undisturbed.
+func (d *planDay) apply(runs map[tripRun]*protodata.TripUpdateProto, fetchedAt time.Time) {
+	updates := make(map[tripRun]*protodata.TripUpdateProto)
+	changed := make(map[int]bool)
+	for run, tu := range runs {
+		// added trips have no static pattern to patch, and updates to
+		// frequency-based trips don't say which run they mean
+		r, found := d.static.runs[run]
+		if !found || r < 0 {
+			continue
+		}
+		updates[run] = tu
+		if prev, found := d.applied[run]; !found || !proto.Equal(prev, tu) {
+			changed[r] = true
+		}
+	}
+	for run := range d.applied {
+		if _, found := updates[run]; !found {
+			changed[d.static.runs[run]] = true
+		}
+	}
+	d.applied = updates
+	if len(changed) == 0 {
+		return
+	}
+
+	live := &raptorData{
+		date:       d.static.date,
+		dayStart:   d.static.dayStart,
+		routes:     slices.Clone(d.live.routes),
+		stopRoutes: slices.Clone(d.live.stopRoutes),
+		runs:       d.static.runs,
+		realtime:   true,
+	}
+	horizon := int(fetchedAt.Add(realtimeHorizon).Sub(d.static.dayStart).Seconds())
+
+	routes := make([]int, 0, len(changed))
+	for r := range changed {
+		routes = append(routes, r)
+	}
+	slices.Sort(routes)
+	for _, r := range routes {
+		static := d.static.routes[r]
+		groups := splitOvertaking(patchRoute(static, updates, d.static.dayStart, horizon))
+
+		slots := append([]int{r}, d.overflow[r]...)
+		for i, trips := range groups {
+			if i < len(slots) {
+				live.routes[slots[i]] = raptorRoute{stops: static.stops, trips: trips}
+				continue
+			}
+			idx := len(live.routes)
+			live.routes = append(live.routes, raptorRoute{stops: static.stops, trips: trips})
+			for pos, stop := range static.stops {
+				// clipped so the previous copy's slice is never written to
+				live.stopRoutes[stop] = append(slices.Clip(live.stopRoutes[stop]), routePosition{route: idx, pos: pos})
+			}
+			d.overflow[r] = append(d.overflow[r], idx)
+		}
+		for _, slot := range slots[min(len(groups), len(slots)):] {
+			live.routes[slot] = raptorRoute{stops: static.stops}
+		}
+	}
+	d.live = live
+}
+
+// patchRoute applies updates to a route's trips, dropping cancelled ones.
+func patchRoute(route raptorRoute, updates map[tripRun]*protodata.TripUpdateProto, dayStart time.Time, horizon int) []raptorTrip {
+	trips := make([]raptorTrip, 0, len(route.trips))
+	for _, t := range route.trips {
+		tu, found := updates[tripRun{tripId: t.trip.GetTripId(), date: t.date}]
+		if !found {
+			trips = append(trips, t)
+			continue
+		}
+		switch tu.GetTrip().GetScheduleRelationship() {
+		case TripCanceled, TripDeleted:
+			continue
+		}
+		trips = append(trips, patchTrip(t, route.stops, tu, dayStart, horizon))
+	}
+	return trips
+}
+
+// patchTrip replaces a run's scheduled times with predicted ones up to the
+// horizon. As in GTFS-realtime, a stop without an update takes the delay of
+// the last stop before it that had one.
+func patchTrip(t raptorTrip, stops []int, tu *protodata.TripUpdateProto, dayStart time.Time, horizon int) raptorTrip {
+	p := t
+	p.arr, p.dep, p.skip, p.live = slices.Clone(t.arr), slices.Clone(t.dep), nil, true
+
+	bySeq := make(map[int32]*protodata.StopTimeUpdateProto)
+	byStop := make(map[string]*protodata.StopTimeUpdateProto)
+	for _, stu := range tu.GetStopTimeUpdate() {
+		if stu.GetStopSequence() != 0 {
+			bySeq[stu.GetStopSequence()] = stu
+		} else if _, seen := byStop[stu.GetStopId()]; !seen {
+			byStop[stu.GetStopId()] = stu
+		}
+	}
+
+	base := dayStart.Unix()
+	delay, propagating := 0, false
+	for pos := range p.arr {
+		if t.arr[pos] > horizon {
+			break
+		}
+		stu, found := bySeq[t.seqs[pos]]
+		if !found {
+			stu, found = byStop[Stops[stops[pos]].GetStopId()]
+		}
+		if found {
+			switch stu.GetScheduleRelationship() {
+			case StopTimeSkipped:
+				if p.skip == nil {
+					p.skip = make([]bool, len(p.arr))
+				}
+				p.skip[pos] = true
+			case StopTimeNoData:
+				propagating = false
+			default:
+				p.arr[pos], p.dep[pos] = predictedSeconds(stu, t.arr[pos], t.dep[pos], base)
+				delay, propagating = p.dep[pos]-t.dep[pos], true
+				continue
+			}
+		}
+		if propagating {
+			p.arr[pos] += delay
+			p.dep[pos] += delay
+		}
+	}
+
+	// running and dwell times can't be negative, whatever the mix of
+	// predictions and schedule
+	for pos := range p.arr {
+		if pos > 0 {
+			p.arr[pos] = max(p.arr[pos], p.dep[pos-1])
+		}
+		p.dep[pos] = max(p.dep[pos], p.arr[pos])
+	}
+	return p
+}
+
+// predictedSeconds reads a stop time update as seconds from the start of the
+// service day, preferring absolute times to delays. An event given only for
+// arrival or departure keeps the scheduled dwell.
+func predictedSeconds(stu *protodata.StopTimeUpdateProto, schedArr, schedDep int, base int64) (int, int) {
+	arrTime, depTime := stu.GetArrival().GetTime(), stu.GetDeparture().GetTime()
+	switch {
+	case arrTime != 0 && depTime != 0:
+		return int(arrTime - base), int(depTime - base)
+	case arrTime != 0:
+		arr := int(arrTime - base)
+		return arr, arr + schedDep - schedArr
+	case depTime != 0:
+		dep := int(depTime - base)
+		return dep - (schedDep - schedArr), dep
+	}
+
+	arrDelay, depDelay := int(stu.GetArrival().GetDelay()), int(stu.GetDeparture().GetDelay())
+	if depDelay == 0 {
+		depDelay = arrDelay
+	}
+	if arrDelay == 0 {
+		arrDelay = depDelay
+	}
+	return schedArr + arrDelay, schedDep + depDelay
+}
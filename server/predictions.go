@@ -0,0 +1,367 @@
+package server
+
+import (
+	"fmt"
+	"math"
+	"net/http"
+	"studious-waffle/server/protodata"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"google.golang.org/protobuf/proto"
+)
+
+const (
+	PredictionSourceFeed      int32 = 0
+	PredictionSourceEstimated int32 = 1
+)
+
+const (
+	// segment history needs a few observations before it is trusted over
+	// the schedule
+	minSegmentSamples = 3
+	segmentAlpha      = 0.2
+	// uncertainty assumed for segments without history, as a fraction of
+	// the scheduled running time
+	scheduleUncertainty = 0.25
+	baseUncertainty     = 30.0
+)
+
+// segmentStats is an exponentially weighted mean and variance of observed
+// running times between two consecutive stops, in seconds.
+type segmentStats struct {
+	Mean     float64 `json:"mean_s"`
+	Variance float64 `json:"variance"`
+	Samples  int     `json:"samples"`
+}
+
+func (s *segmentStats) add(x float64) {
+	if s.Samples == 0 {
+		s.Mean = x
+	} else {
+		diff := x - s.Mean
+		incr := segmentAlpha * diff
+		s.Mean += incr
+		s.Variance = (1 - segmentAlpha) * (s.Variance + diff*incr)
+	}
+	s.Samples++
+}
+
+// vehicleTrack is what the predictor remembers about a vehicle between polls
+// so it can time the stretch between two stops.
+type vehicleTrack struct {
+	tripId       string
+	along        float64
+	timestamp    int64
+	lastStop     int
+	lastStopTime float64
+}
+
+// StopPassing is a vehicle seen passing a stop, at a time interpolated
+// between the two positions either side of it.
+type StopPassing struct {
+	VehicleId string
+	TripId    string
+	// StartDate is the service date from the vehicle's trip descriptor,
+	// empty when the feed doesn't say
+	StartDate string
+	StopTime  *protodata.StopTimeProto
+	At        time.Time
+}
+
+// Predictor learns segment running times from successive vehicle positions
+// and estimates downstream arrivals for vehicles that have no trip update.
+type Predictor struct {
+	mu       sync.Mutex
+	segments map[string]*segmentStats
+	tracks   map[string]*vehicleTrack
+	passed   []func(StopPassing)
+}
+
+func NewPredictor() *Predictor {
+	return &Predictor{
+		segments: make(map[string]*segmentStats),
+		tracks:   make(map[string]*vehicleTrack),
+	}
+}
+
+var predictor = NewPredictor()
+
+// OnStopPassed registers fn to run whenever Observe sees a vehicle pass a
+// stop. It runs with the predictor locked and must not call back into it.
+func (p *Predictor) OnStopPassed(fn func(StopPassing)) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.passed = append(p.passed, fn)
+}
+
+func segmentKey(from, to string) string {
+	return from + ">" + to
+}
+
+// Observe records a realtime poll. Whenever a vehicle passes a stop between
+// two polls the passing time is interpolated from the two positions, and
+// the time since it passed the previous stop becomes a segment sample.
+func (p *Predictor) Observe(snap *RealtimeSnapshot) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	for _, e := range snap.Vehicles {
+		v := e.GetVehicle()
+		id := v.GetVehicle().GetId()
+		if id == "" {
+			continue
+		}
+		progress, err := SnapVehicle(v, offRouteThresholdMeters)
+		if err != nil || progress.OffRoute {
+			continue
+		}
+
+		track, ok := p.tracks[id]
+		if !ok || track.tripId != progress.TripId || progress.DistanceTraveled < track.along {
+			p.tracks[id] = &vehicleTrack{
+				tripId:    progress.TripId,
+				along:     progress.DistanceTraveled,
+				timestamp: v.GetTimestamp(),
+				lastStop:  -1,
+			}
+			continue
+		}
+		if v.GetTimestamp() <= track.timestamp {
+			continue
+		}
+
+		stops := progress.geometry.stops
+		span := progress.DistanceTraveled - track.along
+		elapsed := float64(v.GetTimestamp() - track.timestamp)
+		for k := range stops {
+			if stops[k].Along <= track.along || stops[k].Along > progress.DistanceTraveled {
+				continue
+			}
+			passed := float64(track.timestamp)
+			if span > 0 {
+				passed += (stops[k].Along - track.along) / span * elapsed
+			}
+			for _, fn := range p.passed {
+				fn(StopPassing{
+					VehicleId: id,
+					TripId:    progress.TripId,
+					StartDate: v.GetTrip().GetStartDate(),
+					StopTime:  stops[k].StopTime,
+					At:        time.Unix(0, int64(passed*float64(time.Second))),
+				})
+			}
+			if track.lastStop == k-1 && track.lastStop >= 0 {
+				if run := passed - track.lastStopTime; run > 0 && run < 3600 {
+					key := segmentKey(stops[k-1].StopTime.GetStopId(), stops[k].StopTime.GetStopId())
+					stats, ok := p.segments[key]
+					if !ok {
+						stats = &segmentStats{}
+						p.segments[key] = stats
+					}
+					stats.add(run)
+				}
+			}
+			track.lastStop, track.lastStopTime = k, passed
+		}
+		track.along = progress.DistanceTraveled
+		track.timestamp = v.GetTimestamp()
+	}
+}
+
+// segmentTime is the expected running time between stops k-1 and k and its
+// variance, from history when there is enough of it and from the schedule
+// otherwise.
+func (p *Predictor) segmentTime(stops []tripStop, k int) (float64, float64) {
+	if stats, ok := p.segments[segmentKey(stops[k-1].StopTime.GetStopId(), stops[k].StopTime.GetStopId())]; ok && stats.Samples >= minSegmentSamples {
+		return stats.Mean, stats.Variance
+	}
+	_, dep, _ := stopTimeSeconds(stops[k-1].StopTime)
+	arr, _, _ := stopTimeSeconds(stops[k].StopTime)
+	run := math.Max(0, float64(arr-dep))
+	sd := run * scheduleUncertainty
+	return run, sd * sd
+}
+
+// Predict estimates arrival and departure times at the stops ahead of a
+// vehicle. Estimates start from where and when the vehicle was last seen, so
+// its current delay against the schedule carries forward; running times
+// ahead come from segment history when there is enough of it, so a bus on a
+// slow stretch is not assumed to hold its delay constant. Buses do not leave
+// timepoints early.
+func (p *Predictor) Predict(v *protodata.VehiclePositionProto) (*protodata.TripUpdateProto, error) {
+	progress, err := SnapVehicle(v, offRouteThresholdMeters)
+	if err != nil {
+		return nil, err
+	}
+	if progress.OffRoute {
+		return nil, fmt.Errorf("vehicle %s is off route", progress.VehicleId)
+	}
+	g := progress.geometry
+	next := progress.nextIndex
+	if next >= len(g.stops) {
+		return nil, fmt.Errorf("vehicle %s has passed the last stop", progress.VehicleId)
+	}
+
+	observed := v.GetTimestamp()
+	if observed == 0 {
+		observed = time.Now().Unix()
+	}
+	stopTimes := make([]*protodata.StopTimeProto, len(g.stops))
+	for i, s := range g.stops {
+		stopTimes[i] = s.StopTime
+	}
+	dayStart := resolveServiceDay(stopTimes, time.Unix(observed, 0))
+	day := dayStart.Unix()
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	// time left to reach the next stop, prorated over the current segment
+	eta, variance := 0.0, baseUncertainty*baseUncertainty
+	if next > 0 {
+		run, vr := p.segmentTime(g.stops, next)
+		length := g.stops[next].Along - g.stops[next-1].Along
+		frac := 1.0
+		if length > 0 {
+			frac = (g.stops[next].Along - progress.DistanceTraveled) / length
+		}
+		eta = run * frac
+		variance += vr * frac * frac
+	} else {
+		// not yet at the first stop: the trip starts on schedule or now
+		_, dep, _ := stopTimeSeconds(g.stops[0].StopTime)
+		eta = math.Max(0, float64(day+int64(dep)-observed))
+	}
+
+	var updates []*protodata.StopTimeUpdateProto
+	t := float64(observed) + eta
+	for k := next; k < len(g.stops); k++ {
+		if k > next {
+			run, vr := p.segmentTime(g.stops, k)
+			t += run
+			variance += vr
+		}
+		st := g.stops[k].StopTime
+		schedArr, schedDep, ok := stopTimeSeconds(st)
+		if !ok {
+			continue
+		}
+
+		arrival := int64(math.Round(t))
+		departure := arrival + int64(schedDep-schedArr)
+		if st.GetTimepoint() == 1 && departure < day+int64(schedDep) {
+			departure = day + int64(schedDep)
+		}
+		t = float64(departure)
+
+		uncertainty := proto.Int32(int32(math.Round(math.Sqrt(variance))))
+		updates = append(updates, &protodata.StopTimeUpdateProto{
+			StopSequence: proto.Int32(st.GetStopSequence()),
+			StopId:       proto.String(st.GetStopId()),
+			Arrival: &protodata.StopTimeEventProto{
+				Time:        proto.Int64(arrival),
+				Delay:       proto.Int32(int32(arrival - day - int64(schedArr))),
+				Uncertainty: uncertainty,
+			},
+			Departure: &protodata.StopTimeEventProto{
+				Time:        proto.Int64(departure),
+				Delay:       proto.Int32(int32(departure - day - int64(schedDep))),
+				Uncertainty: uncertainty,
+			},
+			ScheduleRelationship: proto.Int32(0),
+		})
+	}
+
+	return &protodata.TripUpdateProto{
+		Trip: &protodata.TripDescriptorProto{
+			TripId:               proto.String(g.trip.GetTripId()),
+			RouteId:              proto.String(g.trip.GetRouteId()),
+			DirectionId:          proto.Int32(g.trip.GetDirectionId()),
+			ScheduleRelationship: proto.Int32(0),
+			StartDate:            proto.String(serviceDate(dayStart)),
+		},
+		Vehicle: &protodata.VehicleDescriptorProto{
+			Id:    proto.String(v.GetVehicle().GetId()),
+			Label: proto.String(v.GetVehicle().GetLabel()),
+		},
+		StopTimeUpdate: updates,
+		Timestamp:      proto.Int64(observed),
+		Source:         proto.Int32(PredictionSourceEstimated),
+	}, nil
+}
+
+// PredictTripUpdates merges the agency's trip updates with estimates for
+// every vehicle whose trip has none, or only empty ones.
+func (p *Predictor) PredictTripUpdates(snap *RealtimeSnapshot) []*protodata.TripUpdateEntityProto {
+	covered := make(map[string]bool)
+	results := make([]*protodata.TripUpdateEntityProto, 0, len(snap.TripUpdates))
+	for _, e := range snap.TripUpdates {
+		tu := e.GetTripUpdate()
+		if len(tu.GetStopTimeUpdate()) == 0 {
+			continue
+		}
+		covered[tu.GetTrip().GetTripId()] = true
+		results = append(results, e)
+	}
+
+	for _, e := range snap.Vehicles {
+		v := e.GetVehicle()
+		tripId := v.GetTrip().GetTripId()
+		if tripId == "" || covered[tripId] {
+			continue
+		}
+		tu, err := p.Predict(v)
+		if err != nil {
+			continue
+		}
+		covered[tripId] = true
+		results = append(results, &protodata.TripUpdateEntityProto{
+			Id:         proto.String("estimated_" + e.GetId()),
+			TripUpdate: tu,
+		})
+	}
+	return results
+}
+
+// GET /predictions?route_id=&trip_id=
+func HandlePredictions(c *gin.Context) {
+	snap, err := currentRealtime()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch realtime feeds"})
+		return
+	}
+
+	routeId, tripId := c.Query("route_id"), c.Query("trip_id")
+	results := make([]*protodata.TripUpdateEntityProto, 0)
+	for _, e := range predictor.PredictTripUpdates(snap) {
+		trip := e.GetTripUpdate().GetTrip()
+		if routeId != "" && trip.GetRouteId() != routeId {
+			continue
+		}
+		if tripId != "" && trip.GetTripId() != tripId {
+			continue
+		}
+		results = append(results, e)
+	}
+	c.JSON(http.StatusOK, results)
+}
+
+// GET /predictions/trip/:trip_id
+func HandlePredictionsByTripId(c *gin.Context) {
+	snap, err := currentRealtime()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch realtime feeds"})
+		return
+	}
+
+	id := c.Param("trip_id")
+	for _, e := range predictor.PredictTripUpdates(snap) {
+		if e.GetTripUpdate().GetTrip().GetTripId() == id {
+			c.JSON(http.StatusOK, e)
+			return
+		}
+	}
+	c.JSON(http.StatusNotFound, gin.H{"error": "No prediction for trip"})
+}
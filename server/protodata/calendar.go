@@ -0,0 +1,94 @@
+package protodata
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+
+	"google.golang.org/protobuf/proto"
+)
+
+// GenerateCalendarData ingests calendar.txt and calendar_dates.txt, which
+// say which days each service_id runs. Feeds may use either file alone.
+func GenerateCalendarData() bool {
+	calendarOk := generateCalendarData()
+	datesOk := generateCalendarDateData()
+	return calendarOk && datesOk
+}
+
+// weekdayColumns are calendar.txt's day columns, Monday first.
+var weekdayColumns = [7]string{"monday", "tuesday", "wednesday", "thursday", "friday", "saturday", "sunday"}
+
+func generateCalendarData() bool {
+	t, ok := readOptionalTable("calendar.txt")
+	if !ok {
+		return false
+	}
+
+	data := parseCalendars(t)
+
+	slices.SortFunc(data, func(a, b *CalendarProto) int {
+		return cmp.Compare(a.GetServiceId(), b.GetServiceId())
+	})
+
+	return writeGeneratedFile(outputUrl+"calendar.generated.go", "Calendars", data)
+}
+
+// parseCalendars reads the rows of calendar.txt.
+func parseCalendars(t *csvTable) []*CalendarProto {
+	data := make([]*CalendarProto, 0, len(t.rows))
+	for i := range t.rows {
+		var days [7]int32
+		for d, day := range weekdayColumns {
+			fmt.Sscanf(t.value(i, day), "%d", &days[d])
+		}
+
+		data = append(data, &CalendarProto{
+			ServiceId: proto.String(t.value(i, "service_id")),
+			Monday:    proto.Int32(days[0]),
+			Tuesday:   proto.Int32(days[1]),
+			Wednesday: proto.Int32(days[2]),
+			Thursday:  proto.Int32(days[3]),
+			Friday:    proto.Int32(days[4]),
+			Saturday:  proto.Int32(days[5]),
+			Sunday:    proto.Int32(days[6]),
+			StartDate: proto.String(t.value(i, "start_date")),
+			EndDate:   proto.String(t.value(i, "end_date")),
+		})
+	}
+	return data
+}
+
+func generateCalendarDateData() bool {
+	t, ok := readOptionalTable("calendar_dates.txt")
+	if !ok {
+		return false
+	}
+
+	data := parseCalendarDates(t)
+
+	slices.SortFunc(data, func(a, b *CalendarDateProto) int {
+		if c := cmp.Compare(a.GetServiceId(), b.GetServiceId()); c != 0 {
+			return c
+		}
+		return cmp.Compare(a.GetDate(), b.GetDate())
+	})
+
+	return writeGeneratedFile(outputUrl+"calendar_dates.generated.go", "CalendarDates", data)
+}
+
+// parseCalendarDates reads the rows of calendar_dates.txt.
+func parseCalendarDates(t *csvTable) []*CalendarDateProto {
+	data := make([]*CalendarDateProto, 0, len(t.rows))
+	for i := range t.rows {
+		var exceptionType int32
+		fmt.Sscanf(t.value(i, "exception_type"), "%d", &exceptionType)
+
+		data = append(data, &CalendarDateProto{
+			ServiceId:     proto.String(t.value(i, "service_id")),
+			Date:          proto.String(t.value(i, "date")),
+			ExceptionType: proto.Int32(exceptionType),
+		})
+	}
+	return data
+}
@@ -0,0 +1,650 @@
+package protodata
+
+import (
+	"archive/zip"
+	"cmp"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"math"
+	"os"
+	"slices"
+	"strings"
+	"time"
+)
+
+const (
+	// stops that moved less than this only had their coordinates rounded
+	stopMoveMeters = 1.0
+	// shapes whose points all stay within this of the other version are
+	// unchanged
+	shapeChangeMeters = 5.0
+	// maxSummaryItems caps the items listed per change in Summary
+	maxSummaryItems = 20
+)
+
+var weekdays = []string{"monday", "tuesday", "wednesday", "thursday", "friday", "saturday", "sunday"}
+
+// Feed is a static GTFS dataset held in memory, with the tables a diff
+// compares.
+type Feed struct {
+	Version       string
+	StartDate     string
+	EndDate       string
+	Routes        []*RouteProto
+	Stops         []*StopProto
+	Trips         []*TripProto
+	Shapes        []*ShapeProto
+	Calendars     []*CalendarProto
+	CalendarDates []*CalendarDateProto
+}
+
+// ReadFeed reads a feed from a directory or a .zip file of GTFS text files.
+// Zips that keep the files in a single folder are read too.
+func ReadFeed(path string) (*Feed, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	if info.IsDir() {
+		return readFeed(os.DirFS(path))
+	}
+	zr, err := zip.OpenReader(path)
+	if err != nil {
+		return nil, fmt.Errorf("%s is neither a directory nor a zip file: %w", path, err)
+	}
+	defer zr.Close()
+	return readFeed(zr)
+}
+
+// ReadFeedZip reads a feed from a zip file held in memory or uploaded.
+func ReadFeedZip(r io.ReaderAt, size int64) (*Feed, error) {
+	zr, err := zip.NewReader(r, size)
+	if err != nil {
+		return nil, err
+	}
+	return readFeed(zr)
+}
+
+func readFeed(fsys fs.FS) (*Feed, error) {
+	if _, err := fs.Stat(fsys, "routes.txt"); err != nil {
+		matches, _ := fs.Glob(fsys, "*/routes.txt")
+		if len(matches) != 1 {
+			return nil, errors.New("feed has no routes.txt")
+		}
+		if fsys, err = fs.Sub(fsys, strings.TrimSuffix(matches[0], "/routes.txt")); err != nil {
+			return nil, err
+		}
+	}
+
+	tables := make(map[string]*csvTable)
+	for _, name := range []string{"routes.txt", "stops.txt", "trips.txt", "shapes.txt", "calendar.txt", "calendar_dates.txt", "feed_info.txt"} {
+		f, err := fsys.Open(name)
+		if errors.Is(err, fs.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		t, err := readCSVTable(name, f, nil)
+		f.Close()
+		if err != nil {
+			return nil, err
+		}
+		tables[name] = t
+	}
+
+	// the tables are read as ingest reads them, so a diff shows what
+	// serving the new feed would change
+	feed := &Feed{}
+	if t := tables["feed_info.txt"]; t != nil && len(t.rows) > 0 {
+		feed.Version, feed.StartDate, feed.EndDate = t.value(0, "feed_version"), t.value(0, "feed_start_date"), t.value(0, "feed_end_date")
+	}
+	if t := tables["routes.txt"]; t != nil {
+		feed.Routes = parseRoutes(t)
+	}
+	if t := tables["stops.txt"]; t != nil {
+		feed.Stops = parseStops(t)
+	}
+	if t := tables["trips.txt"]; t != nil {
+		feed.Trips = parseTrips(t)
+	}
+	if t := tables["shapes.txt"]; t != nil {
+		feed.Shapes = parseShapes(t)
+	}
+	if t := tables["calendar.txt"]; t != nil {
+		feed.Calendars = parseCalendars(t)
+	}
+	if t := tables["calendar_dates.txt"]; t != nil {
+		feed.CalendarDates = parseCalendarDates(t)
+	}
+	return feed, nil
+}
+
+// RouteChange is a route added, removed or renamed. The From names are only
+// set for renamed routes.
+type RouteChange struct {
+	RouteId       string `json:"route_id"`
+	ShortName     string `json:"short_name"`
+	LongName      string `json:"long_name"`
+	FromShortName string `json:"from_short_name,omitempty"`
+	FromLongName  string `json:"from_long_name,omitempty"`
+}
+
+type RouteChanges struct {
+	Added   []RouteChange `json:"added"`
+	Removed []RouteChange `json:"removed"`
+	Renamed []RouteChange `json:"renamed"`
+}
+
+// StopChange is a stop added, removed, moved or renamed. The From fields are
+// only set for moved and renamed stops.
+type StopChange struct {
+	StopId         string  `json:"stop_id"`
+	Name           string  `json:"name"`
+	Lat            float64 `json:"lat"`
+	Lon            float64 `json:"lon"`
+	FromName       string  `json:"from_name,omitempty"`
+	FromLat        float64 `json:"from_lat,omitempty"`
+	FromLon        float64 `json:"from_lon,omitempty"`
+	DistanceMeters float64 `json:"distance_m,omitempty"`
+}
+
+type StopChanges struct {
+	Added   []StopChange `json:"added"`
+	Removed []StopChange `json:"removed"`
+	Moved   []StopChange `json:"moved"`
+	Renamed []StopChange `json:"renamed"`
+}
+
+// TripCountChange is a change in how many trips a route runs on a day of a
+// typical week.
+type TripCountChange struct {
+	RouteId   string `json:"route_id"`
+	ShortName string `json:"short_name"`
+	Day       string `json:"day"`
+	From      int    `json:"from"`
+	To        int    `json:"to"`
+	Change    int    `json:"change"`
+}
+
+type ShapeChange struct {
+	ShapeId            string  `json:"shape_id"`
+	FromPoints         int     `json:"from_points"`
+	ToPoints           int     `json:"to_points"`
+	MaxDeviationMeters float64 `json:"max_deviation_m"`
+}
+
+type ShapeChanges struct {
+	Added   []string      `json:"added"`
+	Removed []string      `json:"removed"`
+	Changed []ShapeChange `json:"changed"`
+}
+
+// ServiceChange is a change to a service's calendar.txt entry, each side
+// described as its running days and date range, like "MTWTF-- 20250831-20260103".
+type ServiceChange struct {
+	ServiceId string `json:"service_id"`
+	From      string `json:"from"`
+	To        string `json:"to"`
+}
+
+type CalendarException struct {
+	ServiceId     string `json:"service_id"`
+	Date          string `json:"date"`
+	ExceptionType int32  `json:"exception_type"`
+}
+
+type CalendarChanges struct {
+	Added             []string            `json:"added"`
+	Removed           []string            `json:"removed"`
+	Changed           []ServiceChange     `json:"changed"`
+	ExceptionsAdded   []CalendarException `json:"exceptions_added"`
+	ExceptionsRemoved []CalendarException `json:"exceptions_removed"`
+}
+
+// FeedDiff is what changed from one feed version to the next.
+type FeedDiff struct {
+	FromVersion string            `json:"from_version,omitempty"`
+	ToVersion   string            `json:"to_version,omitempty"`
+	FromDates   string            `json:"from_dates,omitempty"`
+	ToDates     string            `json:"to_dates,omitempty"`
+	Routes      RouteChanges      `json:"routes"`
+	Stops       StopChanges       `json:"stops"`
+	TripsPerDay []TripCountChange `json:"trips_per_day"`
+	Shapes      ShapeChanges      `json:"shapes"`
+	Calendar    CalendarChanges   `json:"calendar"`
+}
+
+func feedDates(f *Feed) string {
+	if f.StartDate == "" && f.EndDate == "" {
+		return ""
+	}
+	return f.StartDate + "-" + f.EndDate
+}
+
+func metersBetween(a, b shapePoint) float64 {
+	return metersToPolyline(a.lat, a.lon, []shapePoint{b})
+}
+
+// DiffFeeds compares two versions of a feed.
+func DiffFeeds(from, to *Feed) *FeedDiff {
+	d := &FeedDiff{
+		FromVersion: from.Version,
+		ToVersion:   to.Version,
+		FromDates:   feedDates(from),
+		ToDates:     feedDates(to),
+		Routes:      RouteChanges{Added: make([]RouteChange, 0), Removed: make([]RouteChange, 0), Renamed: make([]RouteChange, 0)},
+		Stops:       StopChanges{Added: make([]StopChange, 0), Removed: make([]StopChange, 0), Moved: make([]StopChange, 0), Renamed: make([]StopChange, 0)},
+		TripsPerDay: make([]TripCountChange, 0),
+		Shapes:      ShapeChanges{Added: make([]string, 0), Removed: make([]string, 0), Changed: make([]ShapeChange, 0)},
+		Calendar: CalendarChanges{
+			Added:             make([]string, 0),
+			Removed:           make([]string, 0),
+			Changed:           make([]ServiceChange, 0),
+			ExceptionsAdded:   make([]CalendarException, 0),
+			ExceptionsRemoved: make([]CalendarException, 0),
+		},
+	}
+	d.diffRoutes(from, to)
+	d.diffStops(from, to)
+	d.diffTripCounts(from, to)
+	d.diffShapes(from, to)
+	d.diffCalendar(from, to)
+	return d
+}
+
+// byId indexes a table by its id, keeping the first row for each.
+func byId[T any](rows []T, id func(T) string) (map[string]T, []string) {
+	m := make(map[string]T, len(rows))
+	var ids []string
+	for _, r := range rows {
+		k := id(r)
+		if _, found := m[k]; !found {
+			m[k] = r
+			ids = append(ids, k)
+		}
+	}
+	slices.Sort(ids)
+	return m, ids
+}
+
+func (d *FeedDiff) diffRoutes(from, to *Feed) {
+	id := func(r *RouteProto) string { return r.GetRouteId() }
+	old, oldIds := byId(from.Routes, id)
+	cur, curIds := byId(to.Routes, id)
+	change := func(r *RouteProto) RouteChange {
+		return RouteChange{RouteId: r.GetRouteId(), ShortName: r.GetRouteShortName(), LongName: r.GetRouteLongName()}
+	}
+	for _, k := range curIds {
+		o, found := old[k]
+		if !found {
+			d.Routes.Added = append(d.Routes.Added, change(cur[k]))
+			continue
+		}
+		if o.GetRouteShortName() != cur[k].GetRouteShortName() || o.GetRouteLongName() != cur[k].GetRouteLongName() {
+			c := change(cur[k])
+			c.FromShortName, c.FromLongName = o.GetRouteShortName(), o.GetRouteLongName()
+			d.Routes.Renamed = append(d.Routes.Renamed, c)
+		}
+	}
+	for _, k := range oldIds {
+		if _, found := cur[k]; !found {
+			d.Routes.Removed = append(d.Routes.Removed, change(old[k]))
+		}
+	}
+}
+
+func (d *FeedDiff) diffStops(from, to *Feed) {
+	id := func(s *StopProto) string { return s.GetStopId() }
+	old, oldIds := byId(from.Stops, id)
+	cur, curIds := byId(to.Stops, id)
+	change := func(s *StopProto) StopChange {
+		return StopChange{StopId: s.GetStopId(), Name: s.GetStopName(), Lat: s.GetStopLat(), Lon: s.GetStopLon()}
+	}
+	for _, k := range curIds {
+		o, found := old[k]
+		if !found {
+			d.Stops.Added = append(d.Stops.Added, change(cur[k]))
+			continue
+		}
+		if o.GetStopName() != cur[k].GetStopName() {
+			c := change(cur[k])
+			c.FromName = o.GetStopName()
+			d.Stops.Renamed = append(d.Stops.Renamed, c)
+		}
+		dist := metersBetween(shapePoint{o.GetStopLat(), o.GetStopLon()}, shapePoint{cur[k].GetStopLat(), cur[k].GetStopLon()})
+		if dist > stopMoveMeters {
+			c := change(cur[k])
+			c.FromLat, c.FromLon, c.DistanceMeters = o.GetStopLat(), o.GetStopLon(), math.Round(dist*10)/10
+			d.Stops.Moved = append(d.Stops.Moved, c)
+		}
+	}
+	for _, k := range oldIds {
+		if _, found := cur[k]; !found {
+			d.Stops.Removed = append(d.Stops.Removed, change(old[k]))
+		}
+	}
+	slices.SortFunc(d.Stops.Moved, func(a, b StopChange) int {
+		return cmp.Compare(b.DistanceMeters, a.DistanceMeters)
+	})
+}
+
+// serviceWeekdays says which days of a typical week each service runs:
+// those calendar.txt gives, or for services only in calendar_dates.txt, the
+// days of the week of the dates added.
+func serviceWeekdays(f *Feed) map[string][7]bool {
+	days := make(map[string][7]bool)
+	for _, c := range f.Calendars {
+		days[c.GetServiceId()] = [7]bool{c.GetMonday() == 1, c.GetTuesday() == 1, c.GetWednesday() == 1, c.GetThursday() == 1, c.GetFriday() == 1, c.GetSaturday() == 1, c.GetSunday() == 1}
+	}
+	added := make(map[string][7]bool)
+	for _, cd := range f.CalendarDates {
+		if _, found := days[cd.GetServiceId()]; found || cd.GetExceptionType() != 1 {
+			continue
+		}
+		t, err := time.Parse("20060102", cd.GetDate())
+		if err != nil {
+			continue
+		}
+		w := added[cd.GetServiceId()]
+		// time.Weekday counts from Sunday
+		w[(int(t.Weekday())+6)%7] = true
+		added[cd.GetServiceId()] = w
+	}
+	for id, w := range added {
+		days[id] = w
+	}
+	return days
+}
+
+// tripsPerWeekday counts each route's trips on each day of a typical week.
+func tripsPerWeekday(f *Feed) map[string][7]int {
+	days := serviceWeekdays(f)
+	counts := make(map[string][7]int)
+	for _, t := range f.Trips {
+		w := days[t.GetServiceId()]
+		c := counts[t.GetRouteId()]
+		for i, runs := range w {
+			if runs {
+				c[i]++
+			}
+		}
+		counts[t.GetRouteId()] = c
+	}
+	return counts
+}
+
+func (d *FeedDiff) diffTripCounts(from, to *Feed) {
+	old, cur := tripsPerWeekday(from), tripsPerWeekday(to)
+	names := make(map[string]string)
+	for _, r := range from.Routes {
+		names[r.GetRouteId()] = r.GetRouteShortName()
+	}
+	for _, r := range to.Routes {
+		names[r.GetRouteId()] = r.GetRouteShortName()
+	}
+	routes := make([]string, 0, len(old)+len(cur))
+	for id := range old {
+		routes = append(routes, id)
+	}
+	for id := range cur {
+		if _, found := old[id]; !found {
+			routes = append(routes, id)
+		}
+	}
+	slices.Sort(routes)
+	for _, id := range routes {
+		for i, day := range weekdays {
+			if o, c := old[id][i], cur[id][i]; o != c {
+				d.TripsPerDay = append(d.TripsPerDay, TripCountChange{RouteId: id, ShortName: names[id], Day: day, From: o, To: c, Change: c - o})
+			}
+		}
+	}
+}
+
+// shapePolylines returns each shape's points in sequence order.
+func shapePolylines(shapes []*ShapeProto) map[string][]shapePoint {
+	sorted := slices.Clone(shapes)
+	slices.SortStableFunc(sorted, func(a, b *ShapeProto) int {
+		if c := cmp.Compare(a.GetShapeId(), b.GetShapeId()); c != 0 {
+			return c
+		}
+		return cmp.Compare(a.GetShapePtSequence(), b.GetShapePtSequence())
+	})
+	lines := make(map[string][]shapePoint)
+	for _, s := range sorted {
+		lines[s.GetShapeId()] = append(lines[s.GetShapeId()], shapePoint{s.GetShapePtLat(), s.GetShapePtLon()})
+	}
+	return lines
+}
+
+// shapeDeviation is the furthest either polyline strays from the other.
+// Shapes re-exported unchanged match point for point, which is checked
+// first as it is much cheaper.
+func shapeDeviation(a, b []shapePoint) float64 {
+	if len(a) == len(b) {
+		same := true
+		for i := range a {
+			if metersBetween(a[i], b[i]) > shapeChangeMeters {
+				same = false
+				break
+			}
+		}
+		if same {
+			return 0
+		}
+	}
+	furthest := 0.0
+	for _, p := range a {
+		furthest = math.Max(furthest, metersToPolyline(p.lat, p.lon, b))
+	}
+	for _, p := range b {
+		furthest = math.Max(furthest, metersToPolyline(p.lat, p.lon, a))
+	}
+	return furthest
+}
+
+func (d *FeedDiff) diffShapes(from, to *Feed) {
+	old, cur := shapePolylines(from.Shapes), shapePolylines(to.Shapes)
+	for id, points := range cur {
+		o, found := old[id]
+		if !found {
+			d.Shapes.Added = append(d.Shapes.Added, id)
+			continue
+		}
+		if dev := shapeDeviation(o, points); dev > shapeChangeMeters {
+			d.Shapes.Changed = append(d.Shapes.Changed, ShapeChange{ShapeId: id, FromPoints: len(o), ToPoints: len(points), MaxDeviationMeters: math.Round(dev)})
+		}
+	}
+	for id := range old {
+		if _, found := cur[id]; !found {
+			d.Shapes.Removed = append(d.Shapes.Removed, id)
+		}
+	}
+	slices.Sort(d.Shapes.Added)
+	slices.Sort(d.Shapes.Removed)
+	slices.SortFunc(d.Shapes.Changed, func(a, b ShapeChange) int {
+		return cmp.Compare(a.ShapeId, b.ShapeId)
+	})
+}
+
+func describeService(c *CalendarProto) string {
+	flags := []int32{c.GetMonday(), c.GetTuesday(), c.GetWednesday(), c.GetThursday(), c.GetFriday(), c.GetSaturday(), c.GetSunday()}
+	var b strings.Builder
+	for i, f := range flags {
+		if f == 1 {
+			b.WriteByte("MTWTFSS"[i])
+		} else {
+			b.WriteByte('-')
+		}
+	}
+	return b.String() + " " + c.GetStartDate() + "-" + c.GetEndDate()
+}
+
+func (d *FeedDiff) diffCalendar(from, to *Feed) {
+	services := func(f *Feed) map[string]bool {
+		ids := make(map[string]bool)
+		for _, c := range f.Calendars {
+			ids[c.GetServiceId()] = true
+		}
+		for _, cd := range f.CalendarDates {
+			ids[cd.GetServiceId()] = true
+		}
+		return ids
+	}
+	oldIds, curIds := services(from), services(to)
+	for id := range curIds {
+		if !oldIds[id] {
+			d.Calendar.Added = append(d.Calendar.Added, id)
+		}
+	}
+	for id := range oldIds {
+		if !curIds[id] {
+			d.Calendar.Removed = append(d.Calendar.Removed, id)
+		}
+	}
+	slices.Sort(d.Calendar.Added)
+	slices.Sort(d.Calendar.Removed)
+
+	id := func(c *CalendarProto) string { return c.GetServiceId() }
+	old, _ := byId(from.Calendars, id)
+	cur, ids := byId(to.Calendars, id)
+	for _, k := range ids {
+		if o, found := old[k]; found {
+			if a, b := describeService(o), describeService(cur[k]); a != b {
+				d.Calendar.Changed = append(d.Calendar.Changed, ServiceChange{ServiceId: k, From: a, To: b})
+			}
+		}
+	}
+
+	exception := func(cd *CalendarDateProto) CalendarException {
+		return CalendarException{ServiceId: cd.GetServiceId(), Date: cd.GetDate(), ExceptionType: cd.GetExceptionType()}
+	}
+	oldExceptions, curExceptions := make(map[CalendarException]bool), make(map[CalendarException]bool)
+	for _, cd := range from.CalendarDates {
+		oldExceptions[exception(cd)] = true
+	}
+	for _, cd := range to.CalendarDates {
+		curExceptions[exception(cd)] = true
+	}
+	for e := range curExceptions {
+		if !oldExceptions[e] {
+			d.Calendar.ExceptionsAdded = append(d.Calendar.ExceptionsAdded, e)
+		}
+	}
+	for e := range oldExceptions {
+		if !curExceptions[e] {
+			d.Calendar.ExceptionsRemoved = append(d.Calendar.ExceptionsRemoved, e)
+		}
+	}
+	sortExceptions := func(s []CalendarException) {
+		slices.SortFunc(s, func(a, b CalendarException) int {
+			if c := cmp.Compare(a.Date, b.Date); c != 0 {
+				return c
+			}
+			return cmp.Compare(a.ServiceId, b.ServiceId)
+		})
+	}
+	sortExceptions(d.Calendar.ExceptionsAdded)
+	sortExceptions(d.Calendar.ExceptionsRemoved)
+}
+
+// Summary renders the diff for people, listing the first few items of each
+// kind of change.
+func (d *FeedDiff) Summary() string {
+	var b strings.Builder
+	from, to := cmp.Or(d.FromVersion, "current"), cmp.Or(d.ToVersion, "new")
+	fmt.Fprintf(&b, "Feed %s -> %s\n", from, to)
+	if d.FromDates != "" || d.ToDates != "" {
+		fmt.Fprintf(&b, "Dates %s -> %s\n", cmp.Or(d.FromDates, "?"), cmp.Or(d.ToDates, "?"))
+	}
+
+	section := func(title string, lines []string) {
+		fmt.Fprintf(&b, "\n%s\n", title)
+		for i, line := range lines {
+			if i == maxSummaryItems {
+				fmt.Fprintf(&b, "  ... and %d more\n", len(lines)-i)
+				break
+			}
+			fmt.Fprintf(&b, "  %s\n", line)
+		}
+	}
+	routeName := func(c RouteChange) string {
+		return strings.TrimSpace(c.RouteId + " " + strings.TrimSpace(c.ShortName+" "+c.LongName))
+	}
+
+	routes := make([]string, 0)
+	for _, c := range d.Routes.Added {
+		routes = append(routes, "+ "+routeName(c))
+	}
+	for _, c := range d.Routes.Removed {
+		routes = append(routes, "- "+routeName(c))
+	}
+	for _, c := range d.Routes.Renamed {
+		routes = append(routes, fmt.Sprintf("~ %s: %q -> %q", c.RouteId, strings.TrimSpace(c.FromShortName+" "+c.FromLongName), strings.TrimSpace(c.ShortName+" "+c.LongName)))
+	}
+	section(fmt.Sprintf("Routes (%d added, %d removed, %d renamed)", len(d.Routes.Added), len(d.Routes.Removed), len(d.Routes.Renamed)), routes)
+
+	stops := make([]string, 0)
+	for _, c := range d.Stops.Added {
+		stops = append(stops, fmt.Sprintf("+ %s %s", c.StopId, c.Name))
+	}
+	for _, c := range d.Stops.Removed {
+		stops = append(stops, fmt.Sprintf("- %s %s", c.StopId, c.Name))
+	}
+	for _, c := range d.Stops.Moved {
+		stops = append(stops, fmt.Sprintf("> %s %s moved %.0fm", c.StopId, c.Name, c.DistanceMeters))
+	}
+	for _, c := range d.Stops.Renamed {
+		stops = append(stops, fmt.Sprintf("~ %s: %q -> %q", c.StopId, c.FromName, c.Name))
+	}
+	section(fmt.Sprintf("Stops (%d added, %d removed, %d moved, %d renamed)", len(d.Stops.Added), len(d.Stops.Removed), len(d.Stops.Moved), len(d.Stops.Renamed)), stops)
+
+	trips := make([]string, 0, len(d.TripsPerDay))
+	for _, c := range d.TripsPerDay {
+		trips = append(trips, fmt.Sprintf("%s %s: %d -> %d (%+d)", cmp.Or(c.ShortName, c.RouteId), c.Day, c.From, c.To, c.Change))
+	}
+	section(fmt.Sprintf("Trips per day (%d route days changed)", len(trips)), trips)
+
+	shapes := make([]string, 0)
+	for _, id := range d.Shapes.Added {
+		shapes = append(shapes, "+ "+id)
+	}
+	for _, id := range d.Shapes.Removed {
+		shapes = append(shapes, "- "+id)
+	}
+	for _, c := range d.Shapes.Changed {
+		shapes = append(shapes, fmt.Sprintf("~ %s deviates up to %.0fm", c.ShapeId, c.MaxDeviationMeters))
+	}
+	section(fmt.Sprintf("Shapes (%d added, %d removed, %d changed)", len(d.Shapes.Added), len(d.Shapes.Removed), len(d.Shapes.Changed)), shapes)
+
+	calendar := make([]string, 0)
+	for _, id := range d.Calendar.Added {
+		calendar = append(calendar, "+ "+id)
+	}
+	for _, id := range d.Calendar.Removed {
+		calendar = append(calendar, "- "+id)
+	}
+	for _, c := range d.Calendar.Changed {
+		calendar = append(calendar, fmt.Sprintf("~ %s: %s -> %s", c.ServiceId, c.From, c.To))
+	}
+	for _, e := range d.Calendar.ExceptionsAdded {
+		calendar = append(calendar, fmt.Sprintf("+ %s %s %s", e.Date, e.ServiceId, exceptionName(e.ExceptionType)))
+	}
+	for _, e := range d.Calendar.ExceptionsRemoved {
+		calendar = append(calendar, fmt.Sprintf("- %s %s %s", e.Date, e.ServiceId, exceptionName(e.ExceptionType)))
+	}
+	section(fmt.Sprintf("Calendar (%d services added, %d removed, %d changed, %d exceptions added, %d removed)",
+		len(d.Calendar.Added), len(d.Calendar.Removed), len(d.Calendar.Changed), len(d.Calendar.ExceptionsAdded), len(d.Calendar.ExceptionsRemoved)),
+		calendar)
+	return b.String()
+}
+
+func exceptionName(exceptionType int32) string {
+	if exceptionType == 1 {
+		return "added"
+	}
+	return "removed"
+}
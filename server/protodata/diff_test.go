@@ -0,0 +1,128 @@
+package protodata
+
+import (
+	"fmt"
+	"maps"
+	"testing"
+)
+
+// readTestFeed writes validFeed with changes applied and reads it back.
+func readTestFeed(t *testing.T, changes map[string]string) *Feed {
+	t.Helper()
+	files := maps.Clone(validFeed)
+	files["routes.txt"] = "route_id,agency_id,route_short_name,route_long_name,route_type\n" +
+		"R1,A,15,East Colfax,3\n"
+	files["stops.txt"] = "stop_id,stop_name,stop_lat,stop_lon\n" +
+		"S1,Civic Center,39.74,-104.99\n" +
+		"S2,Colfax & Grant,39.75,-104.99\n"
+	maps.Copy(files, changes)
+	feed, err := ReadFeed(writeFeed(t, files))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return feed
+}
+
+func TestDiffFeeds(t *testing.T) {
+	tests := []struct {
+		name    string
+		changes map[string]string
+		// the parts of the diff that should be set, summed up as text; every
+		// other kind of change should be empty
+		want map[string]string
+	}{
+		{"same feed", nil, map[string]string{}},
+		{
+			"route added and renamed",
+			map[string]string{"routes.txt": "route_id,agency_id,route_short_name,route_long_name,route_type\n" +
+				"R1,A,15L,East Colfax Limited,3\n" +
+				"R2,A,16,West Colfax,3\n"},
+			map[string]string{
+				"routes added":   "[R2 16]",
+				"routes renamed": "[R1 15 -> 15L]",
+			},
+		},
+		{
+			"stop removed, moved and renamed",
+			map[string]string{"stops.txt": "stop_id,stop_name,stop_lat,stop_lon\n" +
+				"S1,Civic Center Station,39.74,-104.98\n"},
+			map[string]string{
+				"stops removed": "[S2]",
+				"stops moved":   "[S1 856m]",
+				"stops renamed": "[S1 Civic Center -> Civic Center Station]",
+			},
+		},
+		{
+			"weekday trip added",
+			map[string]string{"trips.txt": "route_id,service_id,trip_id\nR1,WK,T1\nR1,WK,T2\n"},
+			map[string]string{"trips per day": "[R1 monday +1 R1 tuesday +1 R1 wednesday +1 R1 thursday +1 R1 friday +1]"},
+		},
+		{
+			"service extended and a holiday added",
+			map[string]string{
+				"calendar.txt": "service_id,monday,tuesday,wednesday,thursday,friday,saturday,sunday,start_date,end_date\n" +
+					"WK,1,1,1,1,1,0,0,20260101,20270630\n",
+				"calendar_dates.txt": "service_id,date,exception_type\nWK,20261126,2\n",
+			},
+			map[string]string{
+				"services changed": "[WK MTWTF-- 20260101-20261231 -> MTWTF-- 20260101-20270630]",
+				"exceptions added": "[WK 20261126 2]",
+			},
+		},
+	}
+	from := readTestFeed(t, nil)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := DiffFeeds(from, readTestFeed(t, tt.changes))
+
+			got := make(map[string]string)
+			record := func(kind string, n int, item func(i int) string) {
+				if n == 0 {
+					return
+				}
+				items := make([]string, n)
+				for i := range items {
+					items[i] = item(i)
+				}
+				got[kind] = fmt.Sprint(items)
+			}
+			record("routes added", len(d.Routes.Added), func(i int) string {
+				return d.Routes.Added[i].RouteId + " " + d.Routes.Added[i].ShortName
+			})
+			record("routes removed", len(d.Routes.Removed), func(i int) string { return d.Routes.Removed[i].RouteId })
+			record("routes renamed", len(d.Routes.Renamed), func(i int) string {
+				r := d.Routes.Renamed[i]
+				return r.RouteId + " " + r.FromShortName + " -> " + r.ShortName
+			})
+			record("stops added", len(d.Stops.Added), func(i int) string { return d.Stops.Added[i].StopId })
+			record("stops removed", len(d.Stops.Removed), func(i int) string { return d.Stops.Removed[i].StopId })
+			record("stops moved", len(d.Stops.Moved), func(i int) string {
+				return fmt.Sprintf("%s %.0fm", d.Stops.Moved[i].StopId, d.Stops.Moved[i].DistanceMeters)
+			})
+			record("stops renamed", len(d.Stops.Renamed), func(i int) string {
+				s := d.Stops.Renamed[i]
+				return s.StopId + " " + s.FromName + " -> " + s.Name
+			})
+			record("trips per day", len(d.TripsPerDay), func(i int) string {
+				c := d.TripsPerDay[i]
+				return fmt.Sprintf("%s %s %+d", c.RouteId, c.Day, c.Change)
+			})
+			record("services changed", len(d.Calendar.Changed), func(i int) string {
+				c := d.Calendar.Changed[i]
+				return c.ServiceId + " " + c.From + " -> " + c.To
+			})
+			record("exceptions added", len(d.Calendar.ExceptionsAdded), func(i int) string {
+				e := d.Calendar.ExceptionsAdded[i]
+				return fmt.Sprintf("%s %s %d", e.ServiceId, e.Date, e.ExceptionType)
+			})
+			record("exceptions removed", len(d.Calendar.ExceptionsRemoved), func(i int) string {
+				e := d.Calendar.ExceptionsRemoved[i]
+				return fmt.Sprintf("%s %s %d", e.ServiceId, e.Date, e.ExceptionType)
+			})
+
+			if !maps.Equal(got, tt.want) {
+				t.Errorf("got changes %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
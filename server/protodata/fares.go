@@ -0,0 +1,254 @@
+package protodata
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+	"strconv"
+
+	"google.golang.org/protobuf/proto"
+)
+
+// GenerateFareData ingests the GTFS Fares v2 files: fare media and products,
+// leg and transfer rules, and the networks and areas the rules refer to.
+func GenerateFareData() bool {
+	ok := true
+	for _, gen := range []func() bool{
+		generateFareMediaData,
+		generateFareProductData,
+		generateFareLegRuleData,
+		generateFareTransferRuleData,
+		generateNetworkData,
+		generateRouteNetworkData,
+		generateAreaData,
+		generateStopAreaData,
+	} {
+		if !gen() {
+			ok = false
+		}
+	}
+	return ok
+}
+
+// openTable reads an input file for a generator, reporting why it couldn't.
+func openTable(fileName string) (*csvTable, bool) {
+	t, err := readInputTable(fileName)
+	if err != nil {
+		fmt.Printf("Error opening %s: %v\n", fileName, err)
+		return nil, false
+	}
+	return t, true
+}
+
+// optionalInt32 keeps empty optional columns unset so "no limit" stays
+// distinguishable from 0.
+func optionalInt32(s string) *int32 {
+	v, err := strconv.Atoi(s)
+	if err != nil {
+		return nil
+	}
+	return proto.Int32(int32(v))
+}
+
+func generateFareMediaData() bool {
+	t, ok := openTable("fare_media.txt")
+	if !ok {
+		return false
+	}
+
+	var data []*FareMediaProto
+	for i := range t.rows {
+		var mediaType int32
+		fmt.Sscanf(t.value(i, "fare_media_type"), "%d", &mediaType)
+
+		data = append(data, &FareMediaProto{
+			FareMediaId:   proto.String(t.value(i, "fare_media_id")),
+			FareMediaName: proto.String(t.value(i, "fare_media_name")),
+			FareMediaType: proto.Int32(mediaType),
+		})
+	}
+
+	slices.SortFunc(data, func(a, b *FareMediaProto) int {
+		return cmp.Compare(a.GetFareMediaId(), b.GetFareMediaId())
+	})
+
+	return writeGeneratedFile(outputUrl+"fare_media.generated.go", "FareMedia", data)
+}
+
+func generateFareProductData() bool {
+	t, ok := openTable("fare_products.txt")
+	if !ok {
+		return false
+	}
+
+	var data []*FareProductProto
+	for i := range t.rows {
+		amount, _ := strconv.ParseFloat(t.value(i, "amount"), 64)
+
+		data = append(data, &FareProductProto{
+			FareProductId:   proto.String(t.value(i, "fare_product_id")),
+			FareProductName: proto.String(t.value(i, "fare_product_name")),
+			FareMediaId:     proto.String(t.value(i, "fare_media_id")),
+			Amount:          proto.Float64(amount),
+			Currency:        proto.String(t.value(i, "currency")),
+		})
+	}
+
+	// a product is listed once per fare media it can be bought with
+	slices.SortFunc(data, func(a, b *FareProductProto) int {
+		if c := cmp.Compare(a.GetFareProductId(), b.GetFareProductId()); c != 0 {
+			return c
+		}
+		return cmp.Compare(a.GetFareMediaId(), b.GetFareMediaId())
+	})
+
+	return writeGeneratedFile(outputUrl+"fare_products.generated.go", "FareProducts", data)
+}
+
+func generateFareLegRuleData() bool {
+	t, ok := openTable("fare_leg_rules.txt")
+	if !ok {
+		return false
+	}
+
+	var data []*FareLegRuleProto
+	for i := range t.rows {
+		data = append(data, &FareLegRuleProto{
+			LegGroupId:           proto.String(t.value(i, "leg_group_id")),
+			NetworkId:            proto.String(t.value(i, "network_id")),
+			FromAreaId:           proto.String(t.value(i, "from_area_id")),
+			ToAreaId:             proto.String(t.value(i, "to_area_id")),
+			FromTimeframeGroupId: proto.String(t.value(i, "from_timeframe_group_id")),
+			ToTimeframeGroupId:   proto.String(t.value(i, "to_timeframe_group_id")),
+			FareProductId:        proto.String(t.value(i, "fare_product_id")),
+		})
+	}
+
+	slices.SortFunc(data, func(a, b *FareLegRuleProto) int {
+		if c := cmp.Compare(a.GetNetworkId(), b.GetNetworkId()); c != 0 {
+			return c
+		}
+		return cmp.Compare(a.GetLegGroupId(), b.GetLegGroupId())
+	})
+
+	return writeGeneratedFile(outputUrl+"fare_leg_rules.generated.go", "FareLegRules", data)
+}
+
+func generateFareTransferRuleData() bool {
+	t, ok := openTable("fare_transfer_rules.txt")
+	if !ok {
+		return false
+	}
+
+	var data []*FareTransferRuleProto
+	for i := range t.rows {
+		var transferType int32
+		fmt.Sscanf(t.value(i, "fare_transfer_type"), "%d", &transferType)
+
+		data = append(data, &FareTransferRuleProto{
+			FromLegGroupId:    proto.String(t.value(i, "from_leg_group_id")),
+			ToLegGroupId:      proto.String(t.value(i, "to_leg_group_id")),
+			TransferCount:     optionalInt32(t.value(i, "transfer_count")),
+			DurationLimit:     optionalInt32(t.value(i, "duration_limit")),
+			DurationLimitType: optionalInt32(t.value(i, "duration_limit_type")),
+			FareTransferType:  proto.Int32(transferType),
+			FareProductId:     proto.String(t.value(i, "fare_product_id")),
+		})
+	}
+
+	slices.SortFunc(data, func(a, b *FareTransferRuleProto) int {
+		if c := cmp.Compare(a.GetFromLegGroupId(), b.GetFromLegGroupId()); c != 0 {
+			return c
+		}
+		return cmp.Compare(a.GetToLegGroupId(), b.GetToLegGroupId())
+	})
+
+	return writeGeneratedFile(outputUrl+"fare_transfer_rules.generated.go", "FareTransferRules", data)
+}
+
+func generateNetworkData() bool {
+	t, ok := openTable("networks.txt")
+	if !ok {
+		return false
+	}
+
+	var data []*NetworkProto
+	for i := range t.rows {
+		data = append(data, &NetworkProto{
+			NetworkId:   proto.String(t.value(i, "network_id")),
+			NetworkName: proto.String(t.value(i, "network_name")),
+		})
+	}
+
+	slices.SortFunc(data, func(a, b *NetworkProto) int {
+		return cmp.Compare(a.GetNetworkId(), b.GetNetworkId())
+	})
+
+	return writeGeneratedFile(outputUrl+"networks.generated.go", "Networks", data)
+}
+
+func generateRouteNetworkData() bool {
+	t, ok := openTable("route_networks.txt")
+	if !ok {
+		return false
+	}
+
+	var data []*RouteNetworkProto
+	for i := range t.rows {
+		data = append(data, &RouteNetworkProto{
+			RouteId:   proto.String(t.value(i, "route_id")),
+			NetworkId: proto.String(t.value(i, "network_id")),
+		})
+	}
+
+	slices.SortFunc(data, func(a, b *RouteNetworkProto) int {
+		return cmp.Compare(a.GetRouteId(), b.GetRouteId())
+	})
+
+	return writeGeneratedFile(outputUrl+"route_networks.generated.go", "RouteNetworks", data)
+}
+
+func generateAreaData() bool {
+	t, ok := openTable("areas.txt")
+	if !ok {
+		return false
+	}
+
+	var data []*AreaProto
+	for i := range t.rows {
+		data = append(data, &AreaProto{
+			AreaId:   proto.String(t.value(i, "area_id")),
+			AreaName: proto.String(t.value(i, "area_name")),
+		})
+	}
+
+	slices.SortFunc(data, func(a, b *AreaProto) int {
+		return cmp.Compare(a.GetAreaId(), b.GetAreaId())
+	})
+
+	return writeGeneratedFile(outputUrl+"areas.generated.go", "Areas", data)
+}
+
+func generateStopAreaData() bool {
+	t, ok := openTable("stop_areas.txt")
+	if !ok {
+		return false
+	}
+
+	var data []*StopAreaProto
+	for i := range t.rows {
+		data = append(data, &StopAreaProto{
+			StopId: proto.String(t.value(i, "stop_id")),
+			AreaId: proto.String(t.value(i, "area_id")),
+		})
+	}
+
+	slices.SortFunc(data, func(a, b *StopAreaProto) int {
+		if c := cmp.Compare(a.GetStopId(), b.GetStopId()); c != 0 {
+			return c
+		}
+		return cmp.Compare(a.GetAreaId(), b.GetAreaId())
+	})
+
+	return writeGeneratedFile(outputUrl+"stop_areas.generated.go", "StopAreas", data)
+}
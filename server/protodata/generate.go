@@ -1,73 +1,200 @@
 package protodata
 
 import (
+	"archive/zip"
 	"bufio"
 	"cmp"
 	"encoding/csv"
+	"errors"
 	"fmt"
 	"io"
+	"io/fs"
 	"os"
+	"path/filepath"
+	"reflect"
 	"slices"
 	"strconv"
+	"strings"
 
 	"google.golang.org/protobuf/proto"
 )
 
-const outputUrl = "/Users/peterbishop/Development/studious-waffle/server/protodata/"
-const inputUrl = "/Users/peterbishop/Development/studious-waffle/server/protodata/input/"
+// outputUrl is where the generated data and validation report are written,
+// relative to the repository root the binary is run from; UseOutput points
+// it elsewhere.
+var outputUrl = "server/protodata/"
 
-func OpenCSVReader(fileName string) (*csv.Reader, *os.File, error) {
-	file, err := os.Open(inputUrl + fileName)
+// inputUrl is where the generators and validator read the static feed from;
+// UseInput points it elsewhere.
+var inputUrl = "server/protodata/input/"
+
+// InputDir is the directory the static feed is read from.
+func InputDir() string {
+	return inputUrl
+}
+
+// OutputDir is the directory the generated data is written to.
+func OutputDir() string {
+	return outputUrl
+}
+
+// UseOutput has the generators write to dir instead of the package's own
+// directory.
+func UseOutput(dir string) {
+	outputUrl = strings.TrimSuffix(dir, "/") + "/"
+}
+
+// UseInput points the generators and validator at a directory or .zip file
+// of GTFS text files instead of the bundled input. A zip is extracted to a
+// temporary directory, which the returned func removes.
+func UseInput(path string) (func(), error) {
+	info, err := os.Stat(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, fmt.Errorf("feed %s does not exist, give a directory or zip of GTFS text files", path)
+	}
 	if err != nil {
-		return nil, nil, fmt.Errorf("error opening file: %w", err)
+		return nil, err
+	}
+	if info.IsDir() {
+		inputUrl = strings.TrimSuffix(path, "/") + "/"
+		return func() {}, nil
 	}
 
-	reader := csv.NewReader(file)
-	reader.TrimLeadingSpace = true
-	// Read the header line to skip it
-	if _, err := reader.Read(); err != nil {
-		file.Close()
-		return nil, nil, fmt.Errorf("error reading header: %w", err)
+	zr, err := zip.OpenReader(path)
+	if err != nil {
+		return nil, fmt.Errorf("%s is neither a directory nor a zip file: %w", path, err)
+	}
+	defer zr.Close()
+	dir, err := os.MkdirTemp("", "gtfs-")
+	if err != nil {
+		return nil, err
 	}
+	cleanup := func() { os.RemoveAll(dir) }
+	for _, f := range zr.File {
+		// only the text files matter; zips that keep them in a single
+		// folder are flattened
+		name := filepath.Base(f.Name)
+		if f.FileInfo().IsDir() || !strings.HasSuffix(name, ".txt") || strings.HasPrefix(name, ".") {
+			continue
+		}
+		if err := extractFile(f, filepath.Join(dir, name)); err != nil {
+			cleanup()
+			return nil, err
+		}
+	}
+	inputUrl = dir + "/"
+	return cleanup, nil
+}
 
-	return reader, file, nil
+func extractFile(f *zip.File, outPath string) error {
+	in, err := f.Open()
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	out, err := os.Create(outPath)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
 }
 
-func GenerateRouteData() bool {
-	outPath := outputUrl + "routes.generated.go"
-	reader, inFile, err := OpenCSVReader("routes.txt")
+// csvTable is an input file read whole, with the line each row started on.
+// Generators look its columns up by name, since feeds order and include
+// them as they like.
+type csvTable struct {
+	file    string
+	columns map[string]int
+	rows    [][]string
+	lines   []int
+}
+
+// value is column of row i, or "" when the file doesn't have the column.
+func (t *csvTable) value(i int, column string) string {
+	if c, found := t.columns[column]; found && c < len(t.rows[i]) {
+		return t.rows[i][c]
+	}
+	return ""
+}
+
+// readCSVTable reads a whole CSV file, handing rows that don't parse to
+// malformed, if set, and skipping them as the generators do.
+func readCSVTable(fileName string, r io.Reader, malformed func(line int, err error)) (*csvTable, error) {
+	reader := csv.NewReader(r)
+	reader.TrimLeadingSpace = true
+	header, err := reader.Read()
 	if err != nil {
-		fmt.Println("Error opening routes.txt:", err)
-		return false
+		return nil, fmt.Errorf("%s has no header row", fileName)
 	}
-	defer inFile.Close()
+	// a UTF-8 byte order mark would otherwise hide the first column
+	header[0] = strings.TrimPrefix(header[0], "\ufeff")
 
-	var data []*RouteProto
+	t := &csvTable{file: fileName, columns: make(map[string]int)}
+	for i, name := range header {
+		t.columns[strings.TrimSpace(name)] = i
+	}
 	for {
 		row, err := reader.Read()
 		if err == io.EOF {
 			break
 		}
 		if err != nil {
+			if malformed != nil {
+				line := 0
+				var perr *csv.ParseError
+				if errors.As(err, &perr) {
+					line = perr.StartLine
+				}
+				malformed(line, err)
+			}
 			continue
 		}
+		line, _ := reader.FieldPos(0)
+		t.rows = append(t.rows, row)
+		t.lines = append(t.lines, line)
+	}
+	return t, nil
+}
 
-		var routeType int32
-		fmt.Sscanf(row[5], "%d", &routeType)
+// readInputTable reads an input file whole.
+func readInputTable(fileName string) (*csvTable, error) {
+	file, err := os.Open(inputUrl + fileName)
+	if err != nil {
+		return nil, fmt.Errorf("error opening file: %w", err)
+	}
+	defer file.Close()
+	return readCSVTable(fileName, file, nil)
+}
 
-		data = append(data, &RouteProto{
-			RouteId:        proto.String(row[0]),
-			AgencyId:       proto.String(row[1]),
-			RouteShortName: proto.String(row[2]),
-			RouteLongName:  proto.String(row[3]),
-			RouteDesc:      proto.String(row[4]),
-			RouteType:      proto.Int32(routeType),
-			RouteUrl:       proto.String(row[6]),
-			RouteColor:     proto.String(row[7]),
-			RouteTextColor: proto.String(row[8]),
-		})
+// optionalColumnInt32 is optionalInt32 for a column that a file may not
+// have at all.
+func optionalColumnInt32(t *csvTable, i int, column string) *int32 {
+	return optionalInt32(t.value(i, column))
+}
+
+// optionalColumnString is an optional text column that a file may not have,
+// or rows leave empty.
+func optionalColumnString(t *csvTable, i int, column string) *string {
+	if v := t.value(i, column); v != "" {
+		return proto.String(v)
+	}
+	return nil
+}
+
+func GenerateRouteData() bool {
+	outPath := outputUrl + "routes.generated.go"
+	t, err := readInputTable("routes.txt")
+	if err != nil {
+		fmt.Println("Error opening routes.txt:", err)
+		return false
 	}
 
+	data := parseRoutes(t)
+
 	fmt.Println("Sorting Routes...")
 	slices.SortFunc(data, func(a, b *RouteProto) int {
 		return cmp.Compare(a.GetRouteId(), b.GetRouteId())
@@ -76,123 +203,138 @@ func GenerateRouteData() bool {
 	return writeGeneratedFile(outPath, "Routes", data)
 }
 
+// parseRoutes reads the rows of routes.txt, for ingest and the feed diff alike.
+func parseRoutes(t *csvTable) []*RouteProto {
+	data := make([]*RouteProto, 0, len(t.rows))
+	for i := range t.rows {
+		var routeType int32
+		fmt.Sscanf(t.value(i, "route_type"), "%d", &routeType)
+
+		data = append(data, &RouteProto{
+			RouteId:        proto.String(t.value(i, "route_id")),
+			AgencyId:       proto.String(t.value(i, "agency_id")),
+			RouteShortName: proto.String(t.value(i, "route_short_name")),
+			RouteLongName:  proto.String(t.value(i, "route_long_name")),
+			RouteDesc:      proto.String(t.value(i, "route_desc")),
+			RouteType:      proto.Int32(routeType),
+			RouteUrl:       proto.String(t.value(i, "route_url")),
+			RouteColor:     proto.String(t.value(i, "route_color")),
+			RouteTextColor: proto.String(t.value(i, "route_text_color")),
+			// route_sort_order is optional and absent from some feeds
+			RouteSortOrder: optionalColumnInt32(t, i, "route_sort_order"),
+		})
+	}
+	return data
+}
+
 func GenerateTripData() bool {
 	outPath := outputUrl + "trips.generated.go"
-	reader, inFile, err := OpenCSVReader("trips.txt")
+	t, err := readInputTable("trips.txt")
 	if err != nil {
 		return false
 	}
-	defer inFile.Close()
 
-	var data []*TripProto
-	for {
-		row, err := reader.Read()
-		if err == io.EOF {
-			break
-		}
-		if err != nil {
-			continue
-		}
+	data := parseTrips(t)
+
+	slices.SortFunc(data, func(a, b *TripProto) int {
+		return cmp.Compare(a.GetTripId(), b.GetTripId())
+	})
 
+	return writeGeneratedFile(outPath, "Trips", data)
+}
+
+// parseTrips reads the rows of trips.txt.
+func parseTrips(t *csvTable) []*TripProto {
+	data := make([]*TripProto, 0, len(t.rows))
+	for i := range t.rows {
 		var dID int32
-		fmt.Sscanf(row[4], "%d", &dID)
+		fmt.Sscanf(t.value(i, "direction_id"), "%d", &dID)
 
 		data = append(data, &TripProto{
-			RouteId:      proto.String(row[0]),
-			ServiceId:    proto.String(row[1]),
-			TripId:       proto.String(row[2]),
-			TripHeadsign: proto.String(row[3]),
+			RouteId:      proto.String(t.value(i, "route_id")),
+			ServiceId:    proto.String(t.value(i, "service_id")),
+			TripId:       proto.String(t.value(i, "trip_id")),
+			TripHeadsign: proto.String(t.value(i, "trip_headsign")),
 			DirectionId:  proto.Int32(dID),
-			BlockId:      proto.String(row[5]),
-			ShapeId:      proto.String(row[6]),
+			BlockId:      proto.String(t.value(i, "block_id")),
+			ShapeId:      proto.String(t.value(i, "shape_id")),
 		})
 	}
-
-	slices.SortFunc(data, func(a, b *TripProto) int {
-		return cmp.Compare(a.GetTripId(), b.GetTripId())
-	})
-
-	return writeGeneratedFile(outPath, "Trips", data)
+	return data
 }
 
 func GenerateStopData() bool {
 	outPath := outputUrl + "stops.generated.go"
-	reader, inFile, err := OpenCSVReader("stops.txt")
+	t, err := readInputTable("stops.txt")
 	if err != nil {
 		return false
 	}
-	defer inFile.Close()
 
-	var data []*StopProto
-	for {
-		row, err := reader.Read()
-		if err == io.EOF {
-			break
-		}
-		if err != nil {
-			continue
-		}
+	data := parseStops(t)
+
+	slices.SortFunc(data, func(a, b *StopProto) int {
+		return cmp.Compare(a.GetStopId(), b.GetStopId())
+	})
 
-		lat, _ := strconv.ParseFloat(row[4], 64)
-		lon, _ := strconv.ParseFloat(row[5], 64)
+	return writeGeneratedFile(outPath, "Stops", data)
+}
+
+// parseStops reads the rows of stops.txt.
+func parseStops(t *csvTable) []*StopProto {
+	data := make([]*StopProto, 0, len(t.rows))
+	for i := range t.rows {
+		lat, _ := strconv.ParseFloat(t.value(i, "stop_lat"), 64)
+		lon, _ := strconv.ParseFloat(t.value(i, "stop_lon"), 64)
 		var locType, wheel int32
-		fmt.Sscanf(row[8], "%d", &locType)
-		fmt.Sscanf(row[11], "%d", &wheel)
+		fmt.Sscanf(t.value(i, "location_type"), "%d", &locType)
+		fmt.Sscanf(t.value(i, "wheelchair_boarding"), "%d", &wheel)
 
 		data = append(data, &StopProto{
-			StopId:             proto.String(row[0]),
-			StopCode:           proto.String(row[1]),
-			StopName:           proto.String(row[2]),
-			StopDesc:           proto.String(row[3]),
+			StopId:             proto.String(t.value(i, "stop_id")),
+			StopCode:           proto.String(t.value(i, "stop_code")),
+			StopName:           proto.String(t.value(i, "stop_name")),
+			StopDesc:           proto.String(t.value(i, "stop_desc")),
 			StopLat:            proto.Float64(lat),
 			StopLon:            proto.Float64(lon),
-			ZoneId:             proto.String(row[6]),
-			StopUrl:            proto.String(row[7]),
+			ZoneId:             proto.String(t.value(i, "zone_id")),
+			StopUrl:            proto.String(t.value(i, "stop_url")),
 			LocationType:       proto.Int32(locType),
-			ParentStation:      proto.String(row[9]),
-			StopTimezone:       proto.String(row[10]),
+			ParentStation:      proto.String(t.value(i, "parent_station")),
+			StopTimezone:       proto.String(t.value(i, "stop_timezone")),
 			WheelchairBoarding: proto.Int32(wheel),
+			// level_id is only in feeds that publish levels.txt
+			LevelId: optionalColumnString(t, i, "level_id"),
 		})
 	}
-
-	slices.SortFunc(data, func(a, b *StopProto) int {
-		return cmp.Compare(a.GetStopId(), b.GetStopId())
-	})
-
-	return writeGeneratedFile(outPath, "Stops", data)
+	return data
 }
 
 func GenerateStopTimeData() bool {
-	reader, inFile, err := OpenCSVReader("stop_times.txt")
+	t, err := readInputTable("stop_times.txt")
 	if err != nil {
 		return false
 	}
-	defer inFile.Close()
-
-	var data []*StopTimeProto
-	for {
-		row, err := reader.Read()
-		if err == io.EOF {
-			break
-		}
-		if err != nil {
-			continue
-		}
 
+	data := make([]*StopTimeProto, 0, len(t.rows))
+	for i := range t.rows {
 		var seq, pick, drop, timep int32
-		fmt.Sscanf(row[4], "%d", &seq)
-		fmt.Sscanf(row[6], "%d", &pick)
-		fmt.Sscanf(row[7], "%d", &drop)
-		fmt.Sscanf(row[9], "%d", &timep)
-		dist, _ := strconv.ParseFloat(row[8], 64)
+		fmt.Sscanf(t.value(i, "stop_sequence"), "%d", &seq)
+		fmt.Sscanf(t.value(i, "pickup_type"), "%d", &pick)
+		fmt.Sscanf(t.value(i, "drop_off_type"), "%d", &drop)
+		fmt.Sscanf(t.value(i, "timepoint"), "%d", &timep)
+		// an empty timepoint means the times are exact whenever they're given
+		if t.value(i, "timepoint") == "" && t.value(i, "arrival_time") != "" {
+			timep = 1
+		}
+		dist, _ := strconv.ParseFloat(t.value(i, "shape_dist_traveled"), 64)
 
 		data = append(data, &StopTimeProto{
-			TripId:            proto.String(row[0]),
-			ArrivalTime:       proto.String(row[1]),
-			DepartureTime:     proto.String(row[2]),
-			StopId:            proto.String(row[3]),
+			TripId:            proto.String(t.value(i, "trip_id")),
+			ArrivalTime:       proto.String(t.value(i, "arrival_time")),
+			DepartureTime:     proto.String(t.value(i, "departure_time")),
+			StopId:            proto.String(t.value(i, "stop_id")),
 			StopSequence:      proto.Int32(seq),
-			StopHeadsign:      proto.String(row[5]),
+			StopHeadsign:      proto.String(t.value(i, "stop_headsign")),
 			PickupType:        proto.Int32(pick),
 			DropOffType:       proto.Int32(drop),
 			ShapeDistTraveled: proto.Float64(dist),
@@ -221,46 +363,43 @@ func GenerateStopTimeData() bool {
 
 func GenerateShapeData() bool {
 	outPath := outputUrl + "shapes.generated.go"
-	reader, inFile, err := OpenCSVReader("shapes.txt")
+	t, err := readInputTable("shapes.txt")
 	if err != nil {
 		return false
 	}
-	defer inFile.Close()
 
-	var data []*ShapeProto
-	for {
-		row, err := reader.Read()
-		if err == io.EOF {
-			break
-		}
-		if err != nil {
-			continue
+	data := parseShapes(t)
+
+	// sort primarily by ShapeId, then by sequence
+	slices.SortFunc(data, func(a, b *ShapeProto) int {
+		if c := cmp.Compare(a.GetShapeId(), b.GetShapeId()); c != 0 {
+			return c
 		}
+		return cmp.Compare(a.GetShapePtSequence(), b.GetShapePtSequence())
+	})
+
+	return writeGeneratedFile(outPath, "Shapes", data)
+}
 
-		lat, _ := strconv.ParseFloat(row[1], 64)
-		lon, _ := strconv.ParseFloat(row[2], 64)
+// parseShapes reads the rows of shapes.txt.
+func parseShapes(t *csvTable) []*ShapeProto {
+	data := make([]*ShapeProto, 0, len(t.rows))
+	for i := range t.rows {
+		lat, _ := strconv.ParseFloat(t.value(i, "shape_pt_lat"), 64)
+		lon, _ := strconv.ParseFloat(t.value(i, "shape_pt_lon"), 64)
 		var seq int32
-		fmt.Sscanf(row[3], "%d", &seq)
-		dist, _ := strconv.ParseFloat(row[4], 64)
+		fmt.Sscanf(t.value(i, "shape_pt_sequence"), "%d", &seq)
+		dist, _ := strconv.ParseFloat(t.value(i, "shape_dist_traveled"), 64)
 
 		data = append(data, &ShapeProto{
-			ShapeId:           proto.String(row[0]),
+			ShapeId:           proto.String(t.value(i, "shape_id")),
 			ShapePtLat:        proto.Float64(lat),
 			ShapePtLon:        proto.Float64(lon),
 			ShapePtSequence:   proto.Int32(seq),
 			ShapeDistTraveled: proto.Float64(dist),
 		})
 	}
-
-	// sort primarily by ShapeId, then by sequence
-	slices.SortFunc(data, func(a, b *ShapeProto) int {
-		if c := cmp.Compare(a.GetShapeId(), b.GetShapeId()); c != 0 {
-			return c
-		}
-		return cmp.Compare(a.GetShapePtSequence(), b.GetShapePtSequence())
-	})
-
-	return writeGeneratedFile(outPath, "Shapes", data)
+	return data
 }
 
 func writeGeneratedFile(outPath string, varName string, data interface{}) bool {
@@ -277,16 +416,20 @@ func writeGeneratedFile(outPath string, varName string, data interface{}) bool {
 	fmt.Fprintln(writer, "// Code generated by transit-generator; DO NOT EDIT.")
 	fmt.Fprintln(writer, "package protodata")
 	fmt.Fprintln(writer, "")
-	fmt.Fprintln(writer, "import \"google.golang.org/protobuf/proto\"")
-	fmt.Fprintln(writer, "")
+	// an empty table makes no proto.* calls and the import would not compile
+	if reflect.ValueOf(data).Len() > 0 {
+		fmt.Fprintln(writer, "import \"google.golang.org/protobuf/proto\"")
+		fmt.Fprintln(writer, "")
+	}
 	fmt.Fprintf(writer, "var %s = []", varName)
 
 	switch v := data.(type) {
 	case []*RouteProto:
 		fmt.Fprintln(writer, "*RouteProto{")
 		for _, r := range v {
-			fmt.Fprintf(writer, "\t{RouteId: proto.String(%q), AgencyId: proto.String(%q), RouteShortName: proto.String(%q), RouteLongName: proto.String(%q), RouteDesc: proto.String(%q), RouteType: proto.Int32(%d), RouteUrl: proto.String(%q), RouteColor: proto.String(%q), RouteTextColor: proto.String(%q)},\n",
-				r.GetRouteId(), r.GetAgencyId(), r.GetRouteShortName(), r.GetRouteLongName(), r.GetRouteDesc(), r.GetRouteType(), r.GetRouteUrl(), r.GetRouteColor(), r.GetRouteTextColor())
+			fmt.Fprintf(writer, "\t{RouteId: proto.String(%q), AgencyId: proto.String(%q), RouteShortName: proto.String(%q), RouteLongName: proto.String(%q), RouteDesc: proto.String(%q), RouteType: proto.Int32(%d), RouteUrl: proto.String(%q), RouteColor: proto.String(%q), RouteTextColor: proto.String(%q)%s},\n",
+				r.GetRouteId(), r.GetAgencyId(), r.GetRouteShortName(), r.GetRouteLongName(), r.GetRouteDesc(), r.GetRouteType(), r.GetRouteUrl(), r.GetRouteColor(), r.GetRouteTextColor(),
+				optionalInt32Field("RouteSortOrder", r.RouteSortOrder))
 		}
 	case []*TripProto:
 		fmt.Fprintln(writer, "*TripProto{")
@@ -297,8 +440,9 @@ func writeGeneratedFile(outPath string, varName string, data interface{}) bool {
 	case []*StopProto:
 		fmt.Fprintln(writer, "*StopProto{")
 		for _, s := range v {
-			fmt.Fprintf(writer, "\t{StopId: proto.String(%q), StopName: proto.String(%q), StopLat: proto.Float64(%f), StopLon: proto.Float64(%f), LocationType: proto.Int32(%d)},\n",
-				s.GetStopId(), s.GetStopName(), s.GetStopLat(), s.GetStopLon(), s.GetLocationType())
+			fmt.Fprintf(writer, "\t{StopId: proto.String(%q), StopCode: proto.String(%q), StopName: proto.String(%q), StopDesc: proto.String(%q), StopLat: proto.Float64(%f), StopLon: proto.Float64(%f), LocationType: proto.Int32(%d), ParentStation: proto.String(%q)%s},\n",
+				s.GetStopId(), s.GetStopCode(), s.GetStopName(), s.GetStopDesc(), s.GetStopLat(), s.GetStopLon(), s.GetLocationType(), s.GetParentStation(),
+				optionalStringField("LevelId", s.LevelId))
 		}
 	case []*ShapeProto:
 		fmt.Fprintln(writer, "*ShapeProto{")
@@ -309,11 +453,129 @@ func writeGeneratedFile(outPath string, varName string, data interface{}) bool {
 	case []*StopTimeProto:
 		fmt.Fprintln(writer, "*StopTimeProto{")
 		for _, st := range v {
-			fmt.Fprintf(writer, "\t{TripId: proto.String(%q), ArrivalTime: proto.String(%q), DepartureTime: proto.String(%q), StopId: proto.String(%q), StopSequence: proto.Int32(%d)},\n",
-				st.GetTripId(), st.GetArrivalTime(), st.GetDepartureTime(), st.GetStopId(), st.GetStopSequence())
+			fmt.Fprintf(writer, "\t{TripId: proto.String(%q), ArrivalTime: proto.String(%q), DepartureTime: proto.String(%q), StopId: proto.String(%q), StopSequence: proto.Int32(%d), ShapeDistTraveled: proto.Float64(%f), Timepoint: proto.Int32(%d)},\n",
+				st.GetTripId(), st.GetArrivalTime(), st.GetDepartureTime(), st.GetStopId(), st.GetStopSequence(), st.GetShapeDistTraveled(), st.GetTimepoint())
+		}
+	case []*CalendarProto:
+		fmt.Fprintln(writer, "*CalendarProto{")
+		for _, c := range v {
+			fmt.Fprintf(writer, "\t{ServiceId: proto.String(%q), Monday: proto.Int32(%d), Tuesday: proto.Int32(%d), Wednesday: proto.Int32(%d), Thursday: proto.Int32(%d), Friday: proto.Int32(%d), Saturday: proto.Int32(%d), Sunday: proto.Int32(%d), StartDate: proto.String(%q), EndDate: proto.String(%q)},\n",
+				c.GetServiceId(), c.GetMonday(), c.GetTuesday(), c.GetWednesday(), c.GetThursday(), c.GetFriday(), c.GetSaturday(), c.GetSunday(), c.GetStartDate(), c.GetEndDate())
+		}
+	case []*CalendarDateProto:
+		fmt.Fprintln(writer, "*CalendarDateProto{")
+		for _, cd := range v {
+			fmt.Fprintf(writer, "\t{ServiceId: proto.String(%q), Date: proto.String(%q), ExceptionType: proto.Int32(%d)},\n",
+				cd.GetServiceId(), cd.GetDate(), cd.GetExceptionType())
+		}
+	case []*FareMediaProto:
+		fmt.Fprintln(writer, "*FareMediaProto{")
+		for _, m := range v {
+			fmt.Fprintf(writer, "\t{FareMediaId: proto.String(%q), FareMediaName: proto.String(%q), FareMediaType: proto.Int32(%d)},\n",
+				m.GetFareMediaId(), m.GetFareMediaName(), m.GetFareMediaType())
+		}
+	case []*FareProductProto:
+		fmt.Fprintln(writer, "*FareProductProto{")
+		for _, p := range v {
+			fmt.Fprintf(writer, "\t{FareProductId: proto.String(%q), FareProductName: proto.String(%q), FareMediaId: proto.String(%q), Amount: proto.Float64(%f), Currency: proto.String(%q)},\n",
+				p.GetFareProductId(), p.GetFareProductName(), p.GetFareMediaId(), p.GetAmount(), p.GetCurrency())
+		}
+	case []*FareLegRuleProto:
+		fmt.Fprintln(writer, "*FareLegRuleProto{")
+		for _, r := range v {
+			fmt.Fprintf(writer, "\t{LegGroupId: proto.String(%q), NetworkId: proto.String(%q), FromAreaId: proto.String(%q), ToAreaId: proto.String(%q), FromTimeframeGroupId: proto.String(%q), ToTimeframeGroupId: proto.String(%q), FareProductId: proto.String(%q)},\n",
+				r.GetLegGroupId(), r.GetNetworkId(), r.GetFromAreaId(), r.GetToAreaId(), r.GetFromTimeframeGroupId(), r.GetToTimeframeGroupId(), r.GetFareProductId())
+		}
+	case []*FareTransferRuleProto:
+		fmt.Fprintln(writer, "*FareTransferRuleProto{")
+		for _, r := range v {
+			fmt.Fprintf(writer, "\t{FromLegGroupId: proto.String(%q), ToLegGroupId: proto.String(%q)%s%s%s, FareTransferType: proto.Int32(%d), FareProductId: proto.String(%q)},\n",
+				r.GetFromLegGroupId(), r.GetToLegGroupId(),
+				optionalInt32Field("TransferCount", r.TransferCount),
+				optionalInt32Field("DurationLimit", r.DurationLimit),
+				optionalInt32Field("DurationLimitType", r.DurationLimitType),
+				r.GetFareTransferType(), r.GetFareProductId())
+		}
+	case []*NetworkProto:
+		fmt.Fprintln(writer, "*NetworkProto{")
+		for _, n := range v {
+			fmt.Fprintf(writer, "\t{NetworkId: proto.String(%q), NetworkName: proto.String(%q)},\n",
+				n.GetNetworkId(), n.GetNetworkName())
+		}
+	case []*RouteNetworkProto:
+		fmt.Fprintln(writer, "*RouteNetworkProto{")
+		for _, rn := range v {
+			fmt.Fprintf(writer, "\t{RouteId: proto.String(%q), NetworkId: proto.String(%q)},\n",
+				rn.GetRouteId(), rn.GetNetworkId())
+		}
+	case []*AreaProto:
+		fmt.Fprintln(writer, "*AreaProto{")
+		for _, a := range v {
+			fmt.Fprintf(writer, "\t{AreaId: proto.String(%q), AreaName: proto.String(%q)},\n",
+				a.GetAreaId(), a.GetAreaName())
+		}
+	case []*StopAreaProto:
+		fmt.Fprintln(writer, "*StopAreaProto{")
+		for _, sa := range v {
+			fmt.Fprintf(writer, "\t{AreaId: proto.String(%q), StopId: proto.String(%q)},\n",
+				sa.GetAreaId(), sa.GetStopId())
+		}
+	case []*LevelProto:
+		fmt.Fprintln(writer, "*LevelProto{")
+		for _, l := range v {
+			fmt.Fprintf(writer, "\t{LevelId: proto.String(%q), LevelIndex: proto.Float64(%f), LevelName: proto.String(%q)},\n",
+				l.GetLevelId(), l.GetLevelIndex(), l.GetLevelName())
+		}
+	case []*PathwayProto:
+		fmt.Fprintln(writer, "*PathwayProto{")
+		for _, p := range v {
+			fmt.Fprintf(writer, "\t{PathwayId: proto.String(%q), FromStopId: proto.String(%q), ToStopId: proto.String(%q), PathwayMode: proto.Int32(%d), IsBidirectional: proto.Int32(%d)%s%s%s%s%s, SignpostedAs: proto.String(%q), ReversedSignpostedAs: proto.String(%q)},\n",
+				p.GetPathwayId(), p.GetFromStopId(), p.GetToStopId(), p.GetPathwayMode(), p.GetIsBidirectional(),
+				optionalFloat64Field("Length", p.Length),
+				optionalInt32Field("TraversalTime", p.TraversalTime),
+				optionalInt32Field("StairCount", p.StairCount),
+				optionalFloat64Field("MaxSlope", p.MaxSlope),
+				optionalFloat64Field("MinWidth", p.MinWidth),
+				p.GetSignpostedAs(), p.GetReversedSignpostedAs())
+		}
+	case []*TransferProto:
+		fmt.Fprintln(writer, "*TransferProto{")
+		for _, t := range v {
+			fmt.Fprintf(writer, "\t{FromStopId: proto.String(%q), ToStopId: proto.String(%q), FromRouteId: proto.String(%q), ToRouteId: proto.String(%q), FromTripId: proto.String(%q), ToTripId: proto.String(%q), TransferType: proto.Int32(%d)%s},\n",
+				t.GetFromStopId(), t.GetToStopId(), t.GetFromRouteId(), t.GetToRouteId(), t.GetFromTripId(), t.GetToTripId(), t.GetTransferType(),
+				optionalInt32Field("MinTransferTime", t.MinTransferTime))
+		}
+	case []*FrequencyProto:
+		fmt.Fprintln(writer, "*FrequencyProto{")
+		for _, f := range v {
+			fmt.Fprintf(writer, "\t{TripId: proto.String(%q), StartTime: proto.String(%q), EndTime: proto.String(%q), HeadwaySecs: proto.Int32(%d), ExactTimes: proto.Int32(%d)},\n",
+				f.GetTripId(), f.GetStartTime(), f.GetEndTime(), f.GetHeadwaySecs(), f.GetExactTimes())
 		}
 	}
 
 	fmt.Fprintln(writer, "}")
 	return true
 }
+
+// optionalInt32Field renders an optional field for a generated literal, or
+// nothing when it is unset.
+func optionalInt32Field(name string, v *int32) string {
+	if v == nil {
+		return ""
+	}
+	return fmt.Sprintf(", %s: proto.Int32(%d)", name, *v)
+}
+
+func optionalFloat64Field(name string, v *float64) string {
+	if v == nil {
+		return ""
+	}
+	return fmt.Sprintf(", %s: proto.Float64(%f)", name, *v)
+}
+
+func optionalStringField(name string, v *string) string {
+	if v == nil {
+		return ""
+	}
+	return fmt.Sprintf(", %s: proto.String(%q)", name, *v)
+}
@@ -0,0 +1,100 @@
+package protodata
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"slices"
+	"strconv"
+
+	"google.golang.org/protobuf/proto"
+)
+
+// GeneratePathwayData ingests levels.txt and pathways.txt, the in-station
+// graph used for accessible routing. Both files are optional in GTFS and the
+// RTD feed leaves them out, so a missing file generates an empty table.
+func GeneratePathwayData() bool {
+	levelsOk := generateLevelData()
+	pathwaysOk := generatePathwayData()
+	return levelsOk && pathwaysOk
+}
+
+// readOptionalTable is openTable for files a feed may leave out. A
+// missing file reads as an empty table.
+func readOptionalTable(fileName string) (*csvTable, bool) {
+	if _, err := os.Stat(inputUrl + fileName); errors.Is(err, fs.ErrNotExist) {
+		fmt.Printf("%s is not in the feed, generating an empty table\n", fileName)
+		return &csvTable{file: fileName, columns: make(map[string]int)}, true
+	}
+	return openTable(fileName)
+}
+
+// optionalFloat64 keeps empty optional columns unset, like optionalInt32.
+func optionalFloat64(s string) *float64 {
+	v, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return nil
+	}
+	return proto.Float64(v)
+}
+
+func generateLevelData() bool {
+	t, ok := readOptionalTable("levels.txt")
+	if !ok {
+		return false
+	}
+
+	data := make([]*LevelProto, 0, len(t.rows))
+	for i := range t.rows {
+		index, _ := strconv.ParseFloat(t.value(i, "level_index"), 64)
+
+		data = append(data, &LevelProto{
+			LevelId:    proto.String(t.value(i, "level_id")),
+			LevelIndex: proto.Float64(index),
+			LevelName:  proto.String(t.value(i, "level_name")),
+		})
+	}
+
+	slices.SortFunc(data, func(a, b *LevelProto) int {
+		return cmp.Compare(a.GetLevelId(), b.GetLevelId())
+	})
+
+	return writeGeneratedFile(outputUrl+"levels.generated.go", "Levels", data)
+}
+
+func generatePathwayData() bool {
+	t, ok := readOptionalTable("pathways.txt")
+	if !ok {
+		return false
+	}
+
+	data := make([]*PathwayProto, 0, len(t.rows))
+	for i := range t.rows {
+		var mode, bidirectional int32
+		fmt.Sscanf(t.value(i, "pathway_mode"), "%d", &mode)
+		fmt.Sscanf(t.value(i, "is_bidirectional"), "%d", &bidirectional)
+
+		data = append(data, &PathwayProto{
+			PathwayId:            proto.String(t.value(i, "pathway_id")),
+			FromStopId:           proto.String(t.value(i, "from_stop_id")),
+			ToStopId:             proto.String(t.value(i, "to_stop_id")),
+			PathwayMode:          proto.Int32(mode),
+			IsBidirectional:      proto.Int32(bidirectional),
+			Length:               optionalFloat64(t.value(i, "length")),
+			TraversalTime:        optionalInt32(t.value(i, "traversal_time")),
+			StairCount:           optionalInt32(t.value(i, "stair_count")),
+			MaxSlope:             optionalFloat64(t.value(i, "max_slope")),
+			MinWidth:             optionalFloat64(t.value(i, "min_width")),
+			SignpostedAs:         proto.String(t.value(i, "signposted_as")),
+			ReversedSignpostedAs: proto.String(t.value(i, "reversed_signposted_as")),
+		})
+	}
+
+	slices.SortFunc(data, func(a, b *PathwayProto) int {
+		return cmp.Compare(a.GetPathwayId(), b.GetPathwayId())
+	})
+
+	return writeGeneratedFile(outputUrl+"pathways.generated.go", "Pathways", data)
+}
@@ -0,0 +1,79 @@
+package protodata
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+
+	"google.golang.org/protobuf/proto"
+)
+
+// GenerateTransferData ingests transfers.txt and frequencies.txt. Like the
+// pathway files both are optional, and a feed without them gets empty tables.
+func GenerateTransferData() bool {
+	transfersOk := generateTransferData()
+	frequenciesOk := generateFrequencyData()
+	return transfersOk && frequenciesOk
+}
+
+func generateTransferData() bool {
+	t, ok := readOptionalTable("transfers.txt")
+	if !ok {
+		return false
+	}
+
+	data := make([]*TransferProto, 0, len(t.rows))
+	for i := range t.rows {
+		var transferType int32
+		fmt.Sscanf(t.value(i, "transfer_type"), "%d", &transferType)
+
+		data = append(data, &TransferProto{
+			FromStopId:      proto.String(t.value(i, "from_stop_id")),
+			ToStopId:        proto.String(t.value(i, "to_stop_id")),
+			FromRouteId:     proto.String(t.value(i, "from_route_id")),
+			ToRouteId:       proto.String(t.value(i, "to_route_id")),
+			FromTripId:      proto.String(t.value(i, "from_trip_id")),
+			ToTripId:        proto.String(t.value(i, "to_trip_id")),
+			TransferType:    proto.Int32(transferType),
+			MinTransferTime: optionalInt32(t.value(i, "min_transfer_time")),
+		})
+	}
+
+	// looked up by the stop a rider transfers from
+	slices.SortFunc(data, func(a, b *TransferProto) int {
+		if c := cmp.Compare(a.GetFromStopId(), b.GetFromStopId()); c != 0 {
+			return c
+		}
+		return cmp.Compare(a.GetToStopId(), b.GetToStopId())
+	})
+
+	return writeGeneratedFile(outputUrl+"transfers.generated.go", "Transfers", data)
+}
+
+func generateFrequencyData() bool {
+	t, ok := readOptionalTable("frequencies.txt")
+	if !ok {
+		return false
+	}
+
+	data := make([]*FrequencyProto, 0, len(t.rows))
+	for i := range t.rows {
+		var headway, exact int32
+		fmt.Sscanf(t.value(i, "headway_secs"), "%d", &headway)
+		fmt.Sscanf(t.value(i, "exact_times"), "%d", &exact)
+
+		data = append(data, &FrequencyProto{
+			TripId:      proto.String(t.value(i, "trip_id")),
+			StartTime:   proto.String(t.value(i, "start_time")),
+			EndTime:     proto.String(t.value(i, "end_time")),
+			HeadwaySecs: proto.Int32(headway),
+			ExactTimes:  proto.Int32(exact),
+		})
+	}
+
+	slices.SortStableFunc(data, func(a, b *FrequencyProto) int {
+		return cmp.Compare(a.GetTripId(), b.GetTripId())
+	})
+
+	return writeGeneratedFile(outputUrl+"frequencies.generated.go", "Frequencies", data)
+}
@@ -124,6 +124,8 @@ type RouteProto struct {
 	RouteUrl       *string                `protobuf:"bytes,7,opt,name=route_url,json=routeUrl" json:"route_url,omitempty"`
 	RouteColor     *string                `protobuf:"bytes,8,opt,name=route_color,json=routeColor" json:"route_color,omitempty"`
 	RouteTextColor *string                `protobuf:"bytes,9,opt,name=route_text_color,json=routeTextColor" json:"route_text_color,omitempty"`
+	NetworkId      *string                `protobuf:"bytes,10,opt,name=network_id,json=networkId" json:"network_id,omitempty"`
+	RouteSortOrder *int32                 `protobuf:"varint,11,opt,name=route_sort_order,json=routeSortOrder" json:"route_sort_order,omitempty"`
 	unknownFields  protoimpl.UnknownFields
 	sizeCache      protoimpl.SizeCache
 }
@@ -221,6 +223,20 @@ func (x *RouteProto) GetRouteTextColor() string {
 	return ""
 }
 
+func (x *RouteProto) GetNetworkId() string {
+	if x != nil && x.NetworkId != nil {
+		return *x.NetworkId
+	}
+	return ""
+}
+
+func (x *RouteProto) GetRouteSortOrder() int32 {
+	if x != nil && x.RouteSortOrder != nil {
+		return *x.RouteSortOrder
+	}
+	return 0
+}
+
 type ShapeProto struct {
 	state             protoimpl.MessageState `protogen:"open.v1"`
 	ShapeId           *string                `protobuf:"bytes,1,opt,name=shape_id,json=shapeId" json:"shape_id,omitempty"`
@@ -311,6 +327,7 @@ type StopProto struct {
 	ParentStation      *string                `protobuf:"bytes,10,opt,name=parent_station,json=parentStation" json:"parent_station,omitempty"`
 	StopTimezone       *string                `protobuf:"bytes,11,opt,name=stop_timezone,json=stopTimezone" json:"stop_timezone,omitempty"`
 	WheelchairBoarding *int32                 `protobuf:"varint,12,opt,name=wheelchair_boarding,json=wheelchairBoarding" json:"wheelchair_boarding,omitempty"`
+	LevelId            *string                `protobuf:"bytes,13,opt,name=level_id,json=levelId" json:"level_id,omitempty"`
 	unknownFields      protoimpl.UnknownFields
 	sizeCache          protoimpl.SizeCache
 }
@@ -328,8 +345,1027 @@ func (x *StopProto) String() string {
 
 func (*StopProto) ProtoMessage() {}
 
-func (x *StopProto) ProtoReflect() protoreflect.Message {
-	mi := &file_transit_proto_msgTypes[3]
+func (x *StopProto) ProtoReflect() protoreflect.Message {
+	mi := &file_transit_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StopProto.ProtoReflect.Descriptor instead.
+func (*StopProto) Descriptor() ([]byte, []int) {
+	return file_transit_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *StopProto) GetStopId() string {
+	if x != nil && x.StopId != nil {
+		return *x.StopId
+	}
+	return ""
+}
+
+func (x *StopProto) GetStopCode() string {
+	if x != nil && x.StopCode != nil {
+		return *x.StopCode
+	}
+	return ""
+}
+
+func (x *StopProto) GetStopName() string {
+	if x != nil && x.StopName != nil {
+		return *x.StopName
+	}
+	return ""
+}
+
+func (x *StopProto) GetStopDesc() string {
+	if x != nil && x.StopDesc != nil {
+		return *x.StopDesc
+	}
+	return ""
+}
+
+func (x *StopProto) GetStopLat() float64 {
+	if x != nil && x.StopLat != nil {
+		return *x.StopLat
+	}
+	return 0
+}
+
+func (x *StopProto) GetStopLon() float64 {
+	if x != nil && x.StopLon != nil {
+		return *x.StopLon
+	}
+	return 0
+}
+
+func (x *StopProto) GetZoneId() string {
+	if x != nil && x.ZoneId != nil {
+		return *x.ZoneId
+	}
+	return ""
+}
+
+func (x *StopProto) GetStopUrl() string {
+	if x != nil && x.StopUrl != nil {
+		return *x.StopUrl
+	}
+	return ""
+}
+
+func (x *StopProto) GetLocationType() int32 {
+	if x != nil && x.LocationType != nil {
+		return *x.LocationType
+	}
+	return 0
+}
+
+func (x *StopProto) GetParentStation() string {
+	if x != nil && x.ParentStation != nil {
+		return *x.ParentStation
+	}
+	return ""
+}
+
+func (x *StopProto) GetStopTimezone() string {
+	if x != nil && x.StopTimezone != nil {
+		return *x.StopTimezone
+	}
+	return ""
+}
+
+func (x *StopProto) GetWheelchairBoarding() int32 {
+	if x != nil && x.WheelchairBoarding != nil {
+		return *x.WheelchairBoarding
+	}
+	return 0
+}
+
+func (x *StopProto) GetLevelId() string {
+	if x != nil && x.LevelId != nil {
+		return *x.LevelId
+	}
+	return ""
+}
+
+type CalendarProto struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	ServiceId     *string                `protobuf:"bytes,1,opt,name=service_id,json=serviceId" json:"service_id,omitempty"`
+	Monday        *int32                 `protobuf:"varint,2,opt,name=monday" json:"monday,omitempty"`
+	Tuesday       *int32                 `protobuf:"varint,3,opt,name=tuesday" json:"tuesday,omitempty"`
+	Wednesday     *int32                 `protobuf:"varint,4,opt,name=wednesday" json:"wednesday,omitempty"`
+	Thursday      *int32                 `protobuf:"varint,5,opt,name=thursday" json:"thursday,omitempty"`
+	Friday        *int32                 `protobuf:"varint,6,opt,name=friday" json:"friday,omitempty"`
+	Saturday      *int32                 `protobuf:"varint,7,opt,name=saturday" json:"saturday,omitempty"`
+	Sunday        *int32                 `protobuf:"varint,8,opt,name=sunday" json:"sunday,omitempty"`
+	StartDate     *string                `protobuf:"bytes,9,opt,name=start_date,json=startDate" json:"start_date,omitempty"`
+	EndDate       *string                `protobuf:"bytes,10,opt,name=end_date,json=endDate" json:"end_date,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CalendarProto) Reset() {
+	*x = CalendarProto{}
+	mi := &file_transit_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CalendarProto) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CalendarProto) ProtoMessage() {}
+
+func (x *CalendarProto) ProtoReflect() protoreflect.Message {
+	mi := &file_transit_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CalendarProto.ProtoReflect.Descriptor instead.
+func (*CalendarProto) Descriptor() ([]byte, []int) {
+	return file_transit_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *CalendarProto) GetServiceId() string {
+	if x != nil && x.ServiceId != nil {
+		return *x.ServiceId
+	}
+	return ""
+}
+
+func (x *CalendarProto) GetMonday() int32 {
+	if x != nil && x.Monday != nil {
+		return *x.Monday
+	}
+	return 0
+}
+
+func (x *CalendarProto) GetTuesday() int32 {
+	if x != nil && x.Tuesday != nil {
+		return *x.Tuesday
+	}
+	return 0
+}
+
+func (x *CalendarProto) GetWednesday() int32 {
+	if x != nil && x.Wednesday != nil {
+		return *x.Wednesday
+	}
+	return 0
+}
+
+func (x *CalendarProto) GetThursday() int32 {
+	if x != nil && x.Thursday != nil {
+		return *x.Thursday
+	}
+	return 0
+}
+
+func (x *CalendarProto) GetFriday() int32 {
+	if x != nil && x.Friday != nil {
+		return *x.Friday
+	}
+	return 0
+}
+
+func (x *CalendarProto) GetSaturday() int32 {
+	if x != nil && x.Saturday != nil {
+		return *x.Saturday
+	}
+	return 0
+}
+
+func (x *CalendarProto) GetSunday() int32 {
+	if x != nil && x.Sunday != nil {
+		return *x.Sunday
+	}
+	return 0
+}
+
+func (x *CalendarProto) GetStartDate() string {
+	if x != nil && x.StartDate != nil {
+		return *x.StartDate
+	}
+	return ""
+}
+
+func (x *CalendarProto) GetEndDate() string {
+	if x != nil && x.EndDate != nil {
+		return *x.EndDate
+	}
+	return ""
+}
+
+type CalendarDateProto struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	ServiceId     *string                `protobuf:"bytes,1,opt,name=service_id,json=serviceId" json:"service_id,omitempty"`
+	Date          *string                `protobuf:"bytes,2,opt,name=date" json:"date,omitempty"`
+	ExceptionType *int32                 `protobuf:"varint,3,opt,name=exception_type,json=exceptionType" json:"exception_type,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CalendarDateProto) Reset() {
+	*x = CalendarDateProto{}
+	mi := &file_transit_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CalendarDateProto) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CalendarDateProto) ProtoMessage() {}
+
+func (x *CalendarDateProto) ProtoReflect() protoreflect.Message {
+	mi := &file_transit_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CalendarDateProto.ProtoReflect.Descriptor instead.
+func (*CalendarDateProto) Descriptor() ([]byte, []int) {
+	return file_transit_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *CalendarDateProto) GetServiceId() string {
+	if x != nil && x.ServiceId != nil {
+		return *x.ServiceId
+	}
+	return ""
+}
+
+func (x *CalendarDateProto) GetDate() string {
+	if x != nil && x.Date != nil {
+		return *x.Date
+	}
+	return ""
+}
+
+func (x *CalendarDateProto) GetExceptionType() int32 {
+	if x != nil && x.ExceptionType != nil {
+		return *x.ExceptionType
+	}
+	return 0
+}
+
+type StopTimeProto struct {
+	state             protoimpl.MessageState `protogen:"open.v1"`
+	TripId            *string                `protobuf:"bytes,1,opt,name=trip_id,json=tripId" json:"trip_id,omitempty"`
+	ArrivalTime       *string                `protobuf:"bytes,2,opt,name=arrival_time,json=arrivalTime" json:"arrival_time,omitempty"`
+	DepartureTime     *string                `protobuf:"bytes,3,opt,name=departure_time,json=departureTime" json:"departure_time,omitempty"`
+	StopId            *string                `protobuf:"bytes,4,opt,name=stop_id,json=stopId" json:"stop_id,omitempty"`
+	StopSequence      *int32                 `protobuf:"varint,5,opt,name=stop_sequence,json=stopSequence" json:"stop_sequence,omitempty"`
+	StopHeadsign      *string                `protobuf:"bytes,6,opt,name=stop_headsign,json=stopHeadsign" json:"stop_headsign,omitempty"`
+	PickupType        *int32                 `protobuf:"varint,7,opt,name=pickup_type,json=pickupType" json:"pickup_type,omitempty"`
+	DropOffType       *int32                 `protobuf:"varint,8,opt,name=drop_off_type,json=dropOffType" json:"drop_off_type,omitempty"`
+	ShapeDistTraveled *float64               `protobuf:"fixed64,9,opt,name=shape_dist_traveled,json=shapeDistTraveled" json:"shape_dist_traveled,omitempty"`
+	Timepoint         *int32                 `protobuf:"varint,10,opt,name=timepoint" json:"timepoint,omitempty"`
+	unknownFields     protoimpl.UnknownFields
+	sizeCache         protoimpl.SizeCache
+}
+
+func (x *StopTimeProto) Reset() {
+	*x = StopTimeProto{}
+	mi := &file_transit_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *StopTimeProto) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StopTimeProto) ProtoMessage() {}
+
+func (x *StopTimeProto) ProtoReflect() protoreflect.Message {
+	mi := &file_transit_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StopTimeProto.ProtoReflect.Descriptor instead.
+func (*StopTimeProto) Descriptor() ([]byte, []int) {
+	return file_transit_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *StopTimeProto) GetTripId() string {
+	if x != nil && x.TripId != nil {
+		return *x.TripId
+	}
+	return ""
+}
+
+func (x *StopTimeProto) GetArrivalTime() string {
+	if x != nil && x.ArrivalTime != nil {
+		return *x.ArrivalTime
+	}
+	return ""
+}
+
+func (x *StopTimeProto) GetDepartureTime() string {
+	if x != nil && x.DepartureTime != nil {
+		return *x.DepartureTime
+	}
+	return ""
+}
+
+func (x *StopTimeProto) GetStopId() string {
+	if x != nil && x.StopId != nil {
+		return *x.StopId
+	}
+	return ""
+}
+
+func (x *StopTimeProto) GetStopSequence() int32 {
+	if x != nil && x.StopSequence != nil {
+		return *x.StopSequence
+	}
+	return 0
+}
+
+func (x *StopTimeProto) GetStopHeadsign() string {
+	if x != nil && x.StopHeadsign != nil {
+		return *x.StopHeadsign
+	}
+	return ""
+}
+
+func (x *StopTimeProto) GetPickupType() int32 {
+	if x != nil && x.PickupType != nil {
+		return *x.PickupType
+	}
+	return 0
+}
+
+func (x *StopTimeProto) GetDropOffType() int32 {
+	if x != nil && x.DropOffType != nil {
+		return *x.DropOffType
+	}
+	return 0
+}
+
+func (x *StopTimeProto) GetShapeDistTraveled() float64 {
+	if x != nil && x.ShapeDistTraveled != nil {
+		return *x.ShapeDistTraveled
+	}
+	return 0
+}
+
+func (x *StopTimeProto) GetTimepoint() int32 {
+	if x != nil && x.Timepoint != nil {
+		return *x.Timepoint
+	}
+	return 0
+}
+
+type FareMediaProto struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	FareMediaId   *string                `protobuf:"bytes,1,opt,name=fare_media_id,json=fareMediaId" json:"fare_media_id,omitempty"`
+	FareMediaName *string                `protobuf:"bytes,2,opt,name=fare_media_name,json=fareMediaName" json:"fare_media_name,omitempty"`
+	FareMediaType *int32                 `protobuf:"varint,3,opt,name=fare_media_type,json=fareMediaType" json:"fare_media_type,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *FareMediaProto) Reset() {
+	*x = FareMediaProto{}
+	mi := &file_transit_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *FareMediaProto) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*FareMediaProto) ProtoMessage() {}
+
+func (x *FareMediaProto) ProtoReflect() protoreflect.Message {
+	mi := &file_transit_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use FareMediaProto.ProtoReflect.Descriptor instead.
+func (*FareMediaProto) Descriptor() ([]byte, []int) {
+	return file_transit_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *FareMediaProto) GetFareMediaId() string {
+	if x != nil && x.FareMediaId != nil {
+		return *x.FareMediaId
+	}
+	return ""
+}
+
+func (x *FareMediaProto) GetFareMediaName() string {
+	if x != nil && x.FareMediaName != nil {
+		return *x.FareMediaName
+	}
+	return ""
+}
+
+func (x *FareMediaProto) GetFareMediaType() int32 {
+	if x != nil && x.FareMediaType != nil {
+		return *x.FareMediaType
+	}
+	return 0
+}
+
+type FareProductProto struct {
+	state           protoimpl.MessageState `protogen:"open.v1"`
+	FareProductId   *string                `protobuf:"bytes,1,opt,name=fare_product_id,json=fareProductId" json:"fare_product_id,omitempty"`
+	FareProductName *string                `protobuf:"bytes,2,opt,name=fare_product_name,json=fareProductName" json:"fare_product_name,omitempty"`
+	FareMediaId     *string                `protobuf:"bytes,3,opt,name=fare_media_id,json=fareMediaId" json:"fare_media_id,omitempty"`
+	Amount          *float64               `protobuf:"fixed64,4,opt,name=amount" json:"amount,omitempty"`
+	Currency        *string                `protobuf:"bytes,5,opt,name=currency" json:"currency,omitempty"`
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
+}
+
+func (x *FareProductProto) Reset() {
+	*x = FareProductProto{}
+	mi := &file_transit_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *FareProductProto) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*FareProductProto) ProtoMessage() {}
+
+func (x *FareProductProto) ProtoReflect() protoreflect.Message {
+	mi := &file_transit_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use FareProductProto.ProtoReflect.Descriptor instead.
+func (*FareProductProto) Descriptor() ([]byte, []int) {
+	return file_transit_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *FareProductProto) GetFareProductId() string {
+	if x != nil && x.FareProductId != nil {
+		return *x.FareProductId
+	}
+	return ""
+}
+
+func (x *FareProductProto) GetFareProductName() string {
+	if x != nil && x.FareProductName != nil {
+		return *x.FareProductName
+	}
+	return ""
+}
+
+func (x *FareProductProto) GetFareMediaId() string {
+	if x != nil && x.FareMediaId != nil {
+		return *x.FareMediaId
+	}
+	return ""
+}
+
+func (x *FareProductProto) GetAmount() float64 {
+	if x != nil && x.Amount != nil {
+		return *x.Amount
+	}
+	return 0
+}
+
+func (x *FareProductProto) GetCurrency() string {
+	if x != nil && x.Currency != nil {
+		return *x.Currency
+	}
+	return ""
+}
+
+type FareLegRuleProto struct {
+	state                protoimpl.MessageState `protogen:"open.v1"`
+	LegGroupId           *string                `protobuf:"bytes,1,opt,name=leg_group_id,json=legGroupId" json:"leg_group_id,omitempty"`
+	NetworkId            *string                `protobuf:"bytes,2,opt,name=network_id,json=networkId" json:"network_id,omitempty"`
+	FromAreaId           *string                `protobuf:"bytes,3,opt,name=from_area_id,json=fromAreaId" json:"from_area_id,omitempty"`
+	ToAreaId             *string                `protobuf:"bytes,4,opt,name=to_area_id,json=toAreaId" json:"to_area_id,omitempty"`
+	FromTimeframeGroupId *string                `protobuf:"bytes,5,opt,name=from_timeframe_group_id,json=fromTimeframeGroupId" json:"from_timeframe_group_id,omitempty"`
+	ToTimeframeGroupId   *string                `protobuf:"bytes,6,opt,name=to_timeframe_group_id,json=toTimeframeGroupId" json:"to_timeframe_group_id,omitempty"`
+	FareProductId        *string                `protobuf:"bytes,7,opt,name=fare_product_id,json=fareProductId" json:"fare_product_id,omitempty"`
+	unknownFields        protoimpl.UnknownFields
+	sizeCache            protoimpl.SizeCache
+}
+
+func (x *FareLegRuleProto) Reset() {
+	*x = FareLegRuleProto{}
+	mi := &file_transit_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *FareLegRuleProto) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*FareLegRuleProto) ProtoMessage() {}
+
+func (x *FareLegRuleProto) ProtoReflect() protoreflect.Message {
+	mi := &file_transit_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use FareLegRuleProto.ProtoReflect.Descriptor instead.
+func (*FareLegRuleProto) Descriptor() ([]byte, []int) {
+	return file_transit_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *FareLegRuleProto) GetLegGroupId() string {
+	if x != nil && x.LegGroupId != nil {
+		return *x.LegGroupId
+	}
+	return ""
+}
+
+func (x *FareLegRuleProto) GetNetworkId() string {
+	if x != nil && x.NetworkId != nil {
+		return *x.NetworkId
+	}
+	return ""
+}
+
+func (x *FareLegRuleProto) GetFromAreaId() string {
+	if x != nil && x.FromAreaId != nil {
+		return *x.FromAreaId
+	}
+	return ""
+}
+
+func (x *FareLegRuleProto) GetToAreaId() string {
+	if x != nil && x.ToAreaId != nil {
+		return *x.ToAreaId
+	}
+	return ""
+}
+
+func (x *FareLegRuleProto) GetFromTimeframeGroupId() string {
+	if x != nil && x.FromTimeframeGroupId != nil {
+		return *x.FromTimeframeGroupId
+	}
+	return ""
+}
+
+func (x *FareLegRuleProto) GetToTimeframeGroupId() string {
+	if x != nil && x.ToTimeframeGroupId != nil {
+		return *x.ToTimeframeGroupId
+	}
+	return ""
+}
+
+func (x *FareLegRuleProto) GetFareProductId() string {
+	if x != nil && x.FareProductId != nil {
+		return *x.FareProductId
+	}
+	return ""
+}
+
+type FareTransferRuleProto struct {
+	state             protoimpl.MessageState `protogen:"open.v1"`
+	FromLegGroupId    *string                `protobuf:"bytes,1,opt,name=from_leg_group_id,json=fromLegGroupId" json:"from_leg_group_id,omitempty"`
+	ToLegGroupId      *string                `protobuf:"bytes,2,opt,name=to_leg_group_id,json=toLegGroupId" json:"to_leg_group_id,omitempty"`
+	TransferCount     *int32                 `protobuf:"varint,3,opt,name=transfer_count,json=transferCount" json:"transfer_count,omitempty"` // unset when the file leaves it empty, -1 = unlimited
+	DurationLimit     *int32                 `protobuf:"varint,4,opt,name=duration_limit,json=durationLimit" json:"duration_limit,omitempty"` // seconds, unset = no limit
+	DurationLimitType *int32                 `protobuf:"varint,5,opt,name=duration_limit_type,json=durationLimitType" json:"duration_limit_type,omitempty"`
+	FareTransferType  *int32                 `protobuf:"varint,6,opt,name=fare_transfer_type,json=fareTransferType" json:"fare_transfer_type,omitempty"`
+	FareProductId     *string                `protobuf:"bytes,7,opt,name=fare_product_id,json=fareProductId" json:"fare_product_id,omitempty"`
+	unknownFields     protoimpl.UnknownFields
+	sizeCache         protoimpl.SizeCache
+}
+
+func (x *FareTransferRuleProto) Reset() {
+	*x = FareTransferRuleProto{}
+	mi := &file_transit_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *FareTransferRuleProto) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*FareTransferRuleProto) ProtoMessage() {}
+
+func (x *FareTransferRuleProto) ProtoReflect() protoreflect.Message {
+	mi := &file_transit_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use FareTransferRuleProto.ProtoReflect.Descriptor instead.
+func (*FareTransferRuleProto) Descriptor() ([]byte, []int) {
+	return file_transit_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *FareTransferRuleProto) GetFromLegGroupId() string {
+	if x != nil && x.FromLegGroupId != nil {
+		return *x.FromLegGroupId
+	}
+	return ""
+}
+
+func (x *FareTransferRuleProto) GetToLegGroupId() string {
+	if x != nil && x.ToLegGroupId != nil {
+		return *x.ToLegGroupId
+	}
+	return ""
+}
+
+func (x *FareTransferRuleProto) GetTransferCount() int32 {
+	if x != nil && x.TransferCount != nil {
+		return *x.TransferCount
+	}
+	return 0
+}
+
+func (x *FareTransferRuleProto) GetDurationLimit() int32 {
+	if x != nil && x.DurationLimit != nil {
+		return *x.DurationLimit
+	}
+	return 0
+}
+
+func (x *FareTransferRuleProto) GetDurationLimitType() int32 {
+	if x != nil && x.DurationLimitType != nil {
+		return *x.DurationLimitType
+	}
+	return 0
+}
+
+func (x *FareTransferRuleProto) GetFareTransferType() int32 {
+	if x != nil && x.FareTransferType != nil {
+		return *x.FareTransferType
+	}
+	return 0
+}
+
+func (x *FareTransferRuleProto) GetFareProductId() string {
+	if x != nil && x.FareProductId != nil {
+		return *x.FareProductId
+	}
+	return ""
+}
+
+type NetworkProto struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	NetworkId     *string                `protobuf:"bytes,1,opt,name=network_id,json=networkId" json:"network_id,omitempty"`
+	NetworkName   *string                `protobuf:"bytes,2,opt,name=network_name,json=networkName" json:"network_name,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *NetworkProto) Reset() {
+	*x = NetworkProto{}
+	mi := &file_transit_proto_msgTypes[11]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *NetworkProto) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*NetworkProto) ProtoMessage() {}
+
+func (x *NetworkProto) ProtoReflect() protoreflect.Message {
+	mi := &file_transit_proto_msgTypes[11]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use NetworkProto.ProtoReflect.Descriptor instead.
+func (*NetworkProto) Descriptor() ([]byte, []int) {
+	return file_transit_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *NetworkProto) GetNetworkId() string {
+	if x != nil && x.NetworkId != nil {
+		return *x.NetworkId
+	}
+	return ""
+}
+
+func (x *NetworkProto) GetNetworkName() string {
+	if x != nil && x.NetworkName != nil {
+		return *x.NetworkName
+	}
+	return ""
+}
+
+type RouteNetworkProto struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	RouteId       *string                `protobuf:"bytes,1,opt,name=route_id,json=routeId" json:"route_id,omitempty"`
+	NetworkId     *string                `protobuf:"bytes,2,opt,name=network_id,json=networkId" json:"network_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RouteNetworkProto) Reset() {
+	*x = RouteNetworkProto{}
+	mi := &file_transit_proto_msgTypes[12]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RouteNetworkProto) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RouteNetworkProto) ProtoMessage() {}
+
+func (x *RouteNetworkProto) ProtoReflect() protoreflect.Message {
+	mi := &file_transit_proto_msgTypes[12]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RouteNetworkProto.ProtoReflect.Descriptor instead.
+func (*RouteNetworkProto) Descriptor() ([]byte, []int) {
+	return file_transit_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *RouteNetworkProto) GetRouteId() string {
+	if x != nil && x.RouteId != nil {
+		return *x.RouteId
+	}
+	return ""
+}
+
+func (x *RouteNetworkProto) GetNetworkId() string {
+	if x != nil && x.NetworkId != nil {
+		return *x.NetworkId
+	}
+	return ""
+}
+
+type AreaProto struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	AreaId        *string                `protobuf:"bytes,1,opt,name=area_id,json=areaId" json:"area_id,omitempty"`
+	AreaName      *string                `protobuf:"bytes,2,opt,name=area_name,json=areaName" json:"area_name,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *AreaProto) Reset() {
+	*x = AreaProto{}
+	mi := &file_transit_proto_msgTypes[13]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AreaProto) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AreaProto) ProtoMessage() {}
+
+func (x *AreaProto) ProtoReflect() protoreflect.Message {
+	mi := &file_transit_proto_msgTypes[13]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AreaProto.ProtoReflect.Descriptor instead.
+func (*AreaProto) Descriptor() ([]byte, []int) {
+	return file_transit_proto_rawDescGZIP(), []int{13}
+}
+
+func (x *AreaProto) GetAreaId() string {
+	if x != nil && x.AreaId != nil {
+		return *x.AreaId
+	}
+	return ""
+}
+
+func (x *AreaProto) GetAreaName() string {
+	if x != nil && x.AreaName != nil {
+		return *x.AreaName
+	}
+	return ""
+}
+
+type StopAreaProto struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	AreaId        *string                `protobuf:"bytes,1,opt,name=area_id,json=areaId" json:"area_id,omitempty"`
+	StopId        *string                `protobuf:"bytes,2,opt,name=stop_id,json=stopId" json:"stop_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *StopAreaProto) Reset() {
+	*x = StopAreaProto{}
+	mi := &file_transit_proto_msgTypes[14]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *StopAreaProto) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StopAreaProto) ProtoMessage() {}
+
+func (x *StopAreaProto) ProtoReflect() protoreflect.Message {
+	mi := &file_transit_proto_msgTypes[14]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StopAreaProto.ProtoReflect.Descriptor instead.
+func (*StopAreaProto) Descriptor() ([]byte, []int) {
+	return file_transit_proto_rawDescGZIP(), []int{14}
+}
+
+func (x *StopAreaProto) GetAreaId() string {
+	if x != nil && x.AreaId != nil {
+		return *x.AreaId
+	}
+	return ""
+}
+
+func (x *StopAreaProto) GetStopId() string {
+	if x != nil && x.StopId != nil {
+		return *x.StopId
+	}
+	return ""
+}
+
+type LevelProto struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	LevelId       *string                `protobuf:"bytes,1,opt,name=level_id,json=levelId" json:"level_id,omitempty"`
+	LevelIndex    *float64               `protobuf:"fixed64,2,opt,name=level_index,json=levelIndex" json:"level_index,omitempty"`
+	LevelName     *string                `protobuf:"bytes,3,opt,name=level_name,json=levelName" json:"level_name,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *LevelProto) Reset() {
+	*x = LevelProto{}
+	mi := &file_transit_proto_msgTypes[15]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *LevelProto) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*LevelProto) ProtoMessage() {}
+
+func (x *LevelProto) ProtoReflect() protoreflect.Message {
+	mi := &file_transit_proto_msgTypes[15]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use LevelProto.ProtoReflect.Descriptor instead.
+func (*LevelProto) Descriptor() ([]byte, []int) {
+	return file_transit_proto_rawDescGZIP(), []int{15}
+}
+
+func (x *LevelProto) GetLevelId() string {
+	if x != nil && x.LevelId != nil {
+		return *x.LevelId
+	}
+	return ""
+}
+
+func (x *LevelProto) GetLevelIndex() float64 {
+	if x != nil && x.LevelIndex != nil {
+		return *x.LevelIndex
+	}
+	return 0
+}
+
+func (x *LevelProto) GetLevelName() string {
+	if x != nil && x.LevelName != nil {
+		return *x.LevelName
+	}
+	return ""
+}
+
+type PathwayProto struct {
+	state                protoimpl.MessageState `protogen:"open.v1"`
+	PathwayId            *string                `protobuf:"bytes,1,opt,name=pathway_id,json=pathwayId" json:"pathway_id,omitempty"`
+	FromStopId           *string                `protobuf:"bytes,2,opt,name=from_stop_id,json=fromStopId" json:"from_stop_id,omitempty"`
+	ToStopId             *string                `protobuf:"bytes,3,opt,name=to_stop_id,json=toStopId" json:"to_stop_id,omitempty"`
+	PathwayMode          *int32                 `protobuf:"varint,4,opt,name=pathway_mode,json=pathwayMode" json:"pathway_mode,omitempty"`
+	IsBidirectional      *int32                 `protobuf:"varint,5,opt,name=is_bidirectional,json=isBidirectional" json:"is_bidirectional,omitempty"`
+	Length               *float64               `protobuf:"fixed64,6,opt,name=length" json:"length,omitempty"`
+	TraversalTime        *int32                 `protobuf:"varint,7,opt,name=traversal_time,json=traversalTime" json:"traversal_time,omitempty"`
+	StairCount           *int32                 `protobuf:"varint,8,opt,name=stair_count,json=stairCount" json:"stair_count,omitempty"`
+	MaxSlope             *float64               `protobuf:"fixed64,9,opt,name=max_slope,json=maxSlope" json:"max_slope,omitempty"`
+	MinWidth             *float64               `protobuf:"fixed64,10,opt,name=min_width,json=minWidth" json:"min_width,omitempty"`
+	SignpostedAs         *string                `protobuf:"bytes,11,opt,name=signposted_as,json=signpostedAs" json:"signposted_as,omitempty"`
+	ReversedSignpostedAs *string                `protobuf:"bytes,12,opt,name=reversed_signposted_as,json=reversedSignpostedAs" json:"reversed_signposted_as,omitempty"`
+	unknownFields        protoimpl.UnknownFields
+	sizeCache            protoimpl.SizeCache
+}
+
+func (x *PathwayProto) Reset() {
+	*x = PathwayProto{}
+	mi := &file_transit_proto_msgTypes[16]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PathwayProto) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PathwayProto) ProtoMessage() {}
+
+func (x *PathwayProto) ProtoReflect() protoreflect.Message {
+	mi := &file_transit_proto_msgTypes[16]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -340,126 +1376,124 @@ func (x *StopProto) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use StopProto.ProtoReflect.Descriptor instead.
-func (*StopProto) Descriptor() ([]byte, []int) {
-	return file_transit_proto_rawDescGZIP(), []int{3}
+// Deprecated: Use PathwayProto.ProtoReflect.Descriptor instead.
+func (*PathwayProto) Descriptor() ([]byte, []int) {
+	return file_transit_proto_rawDescGZIP(), []int{16}
 }
 
-func (x *StopProto) GetStopId() string {
-	if x != nil && x.StopId != nil {
-		return *x.StopId
+func (x *PathwayProto) GetPathwayId() string {
+	if x != nil && x.PathwayId != nil {
+		return *x.PathwayId
 	}
 	return ""
 }
 
-func (x *StopProto) GetStopCode() string {
-	if x != nil && x.StopCode != nil {
-		return *x.StopCode
+func (x *PathwayProto) GetFromStopId() string {
+	if x != nil && x.FromStopId != nil {
+		return *x.FromStopId
 	}
 	return ""
 }
 
-func (x *StopProto) GetStopName() string {
-	if x != nil && x.StopName != nil {
-		return *x.StopName
+func (x *PathwayProto) GetToStopId() string {
+	if x != nil && x.ToStopId != nil {
+		return *x.ToStopId
 	}
 	return ""
 }
 
-func (x *StopProto) GetStopDesc() string {
-	if x != nil && x.StopDesc != nil {
-		return *x.StopDesc
+func (x *PathwayProto) GetPathwayMode() int32 {
+	if x != nil && x.PathwayMode != nil {
+		return *x.PathwayMode
 	}
-	return ""
+	return 0
 }
 
-func (x *StopProto) GetStopLat() float64 {
-	if x != nil && x.StopLat != nil {
-		return *x.StopLat
+func (x *PathwayProto) GetIsBidirectional() int32 {
+	if x != nil && x.IsBidirectional != nil {
+		return *x.IsBidirectional
 	}
 	return 0
 }
 
-func (x *StopProto) GetStopLon() float64 {
-	if x != nil && x.StopLon != nil {
-		return *x.StopLon
+func (x *PathwayProto) GetLength() float64 {
+	if x != nil && x.Length != nil {
+		return *x.Length
 	}
 	return 0
 }
 
-func (x *StopProto) GetZoneId() string {
-	if x != nil && x.ZoneId != nil {
-		return *x.ZoneId
+func (x *PathwayProto) GetTraversalTime() int32 {
+	if x != nil && x.TraversalTime != nil {
+		return *x.TraversalTime
 	}
-	return ""
+	return 0
 }
 
-func (x *StopProto) GetStopUrl() string {
-	if x != nil && x.StopUrl != nil {
-		return *x.StopUrl
+func (x *PathwayProto) GetStairCount() int32 {
+	if x != nil && x.StairCount != nil {
+		return *x.StairCount
 	}
-	return ""
+	return 0
 }
 
-func (x *StopProto) GetLocationType() int32 {
-	if x != nil && x.LocationType != nil {
-		return *x.LocationType
+func (x *PathwayProto) GetMaxSlope() float64 {
+	if x != nil && x.MaxSlope != nil {
+		return *x.MaxSlope
 	}
 	return 0
 }
 
-func (x *StopProto) GetParentStation() string {
-	if x != nil && x.ParentStation != nil {
-		return *x.ParentStation
+func (x *PathwayProto) GetMinWidth() float64 {
+	if x != nil && x.MinWidth != nil {
+		return *x.MinWidth
 	}
-	return ""
+	return 0
 }
 
-func (x *StopProto) GetStopTimezone() string {
-	if x != nil && x.StopTimezone != nil {
-		return *x.StopTimezone
+func (x *PathwayProto) GetSignpostedAs() string {
+	if x != nil && x.SignpostedAs != nil {
+		return *x.SignpostedAs
 	}
 	return ""
 }
 
-func (x *StopProto) GetWheelchairBoarding() int32 {
-	if x != nil && x.WheelchairBoarding != nil {
-		return *x.WheelchairBoarding
+func (x *PathwayProto) GetReversedSignpostedAs() string {
+	if x != nil && x.ReversedSignpostedAs != nil {
+		return *x.ReversedSignpostedAs
 	}
-	return 0
+	return ""
 }
 
-type StopTimeProto struct {
-	state             protoimpl.MessageState `protogen:"open.v1"`
-	TripId            *string                `protobuf:"bytes,1,opt,name=trip_id,json=tripId" json:"trip_id,omitempty"`
-	ArrivalTime       *string                `protobuf:"bytes,2,opt,name=arrival_time,json=arrivalTime" json:"arrival_time,omitempty"`
-	DepartureTime     *string                `protobuf:"bytes,3,opt,name=departure_time,json=departureTime" json:"departure_time,omitempty"`
-	StopId            *string                `protobuf:"bytes,4,opt,name=stop_id,json=stopId" json:"stop_id,omitempty"`
-	StopSequence      *int32                 `protobuf:"varint,5,opt,name=stop_sequence,json=stopSequence" json:"stop_sequence,omitempty"`
-	StopHeadsign      *string                `protobuf:"bytes,6,opt,name=stop_headsign,json=stopHeadsign" json:"stop_headsign,omitempty"`
-	PickupType        *int32                 `protobuf:"varint,7,opt,name=pickup_type,json=pickupType" json:"pickup_type,omitempty"`
-	DropOffType       *int32                 `protobuf:"varint,8,opt,name=drop_off_type,json=dropOffType" json:"drop_off_type,omitempty"`
-	ShapeDistTraveled *float64               `protobuf:"fixed64,9,opt,name=shape_dist_traveled,json=shapeDistTraveled" json:"shape_dist_traveled,omitempty"`
-	Timepoint         *int32                 `protobuf:"varint,10,opt,name=timepoint" json:"timepoint,omitempty"`
-	unknownFields     protoimpl.UnknownFields
-	sizeCache         protoimpl.SizeCache
+type TransferProto struct {
+	state           protoimpl.MessageState `protogen:"open.v1"`
+	FromStopId      *string                `protobuf:"bytes,1,opt,name=from_stop_id,json=fromStopId" json:"from_stop_id,omitempty"`
+	ToStopId        *string                `protobuf:"bytes,2,opt,name=to_stop_id,json=toStopId" json:"to_stop_id,omitempty"`
+	FromRouteId     *string                `protobuf:"bytes,3,opt,name=from_route_id,json=fromRouteId" json:"from_route_id,omitempty"`
+	ToRouteId       *string                `protobuf:"bytes,4,opt,name=to_route_id,json=toRouteId" json:"to_route_id,omitempty"`
+	FromTripId      *string                `protobuf:"bytes,5,opt,name=from_trip_id,json=fromTripId" json:"from_trip_id,omitempty"`
+	ToTripId        *string                `protobuf:"bytes,6,opt,name=to_trip_id,json=toTripId" json:"to_trip_id,omitempty"`
+	TransferType    *int32                 `protobuf:"varint,7,opt,name=transfer_type,json=transferType" json:"transfer_type,omitempty"`
+	MinTransferTime *int32                 `protobuf:"varint,8,opt,name=min_transfer_time,json=minTransferTime" json:"min_transfer_time,omitempty"`
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
 }
 
-func (x *StopTimeProto) Reset() {
-	*x = StopTimeProto{}
-	mi := &file_transit_proto_msgTypes[4]
+func (x *TransferProto) Reset() {
+	*x = TransferProto{}
+	mi := &file_transit_proto_msgTypes[17]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *StopTimeProto) String() string {
+func (x *TransferProto) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*StopTimeProto) ProtoMessage() {}
+func (*TransferProto) ProtoMessage() {}
 
-func (x *StopTimeProto) ProtoReflect() protoreflect.Message {
-	mi := &file_transit_proto_msgTypes[4]
+func (x *TransferProto) ProtoReflect() protoreflect.Message {
+	mi := &file_transit_proto_msgTypes[17]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -470,77 +1504,139 @@ func (x *StopTimeProto) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use StopTimeProto.ProtoReflect.Descriptor instead.
-func (*StopTimeProto) Descriptor() ([]byte, []int) {
-	return file_transit_proto_rawDescGZIP(), []int{4}
+// Deprecated: Use TransferProto.ProtoReflect.Descriptor instead.
+func (*TransferProto) Descriptor() ([]byte, []int) {
+	return file_transit_proto_rawDescGZIP(), []int{17}
 }
 
-func (x *StopTimeProto) GetTripId() string {
-	if x != nil && x.TripId != nil {
-		return *x.TripId
+func (x *TransferProto) GetFromStopId() string {
+	if x != nil && x.FromStopId != nil {
+		return *x.FromStopId
 	}
 	return ""
 }
 
-func (x *StopTimeProto) GetArrivalTime() string {
-	if x != nil && x.ArrivalTime != nil {
-		return *x.ArrivalTime
+func (x *TransferProto) GetToStopId() string {
+	if x != nil && x.ToStopId != nil {
+		return *x.ToStopId
 	}
 	return ""
 }
 
-func (x *StopTimeProto) GetDepartureTime() string {
-	if x != nil && x.DepartureTime != nil {
-		return *x.DepartureTime
+func (x *TransferProto) GetFromRouteId() string {
+	if x != nil && x.FromRouteId != nil {
+		return *x.FromRouteId
 	}
 	return ""
 }
 
-func (x *StopTimeProto) GetStopId() string {
-	if x != nil && x.StopId != nil {
-		return *x.StopId
+func (x *TransferProto) GetToRouteId() string {
+	if x != nil && x.ToRouteId != nil {
+		return *x.ToRouteId
 	}
 	return ""
 }
 
-func (x *StopTimeProto) GetStopSequence() int32 {
-	if x != nil && x.StopSequence != nil {
-		return *x.StopSequence
+func (x *TransferProto) GetFromTripId() string {
+	if x != nil && x.FromTripId != nil {
+		return *x.FromTripId
 	}
-	return 0
+	return ""
 }
 
-func (x *StopTimeProto) GetStopHeadsign() string {
-	if x != nil && x.StopHeadsign != nil {
-		return *x.StopHeadsign
+func (x *TransferProto) GetToTripId() string {
+	if x != nil && x.ToTripId != nil {
+		return *x.ToTripId
 	}
 	return ""
 }
 
-func (x *StopTimeProto) GetPickupType() int32 {
-	if x != nil && x.PickupType != nil {
-		return *x.PickupType
+func (x *TransferProto) GetTransferType() int32 {
+	if x != nil && x.TransferType != nil {
+		return *x.TransferType
 	}
 	return 0
 }
 
-func (x *StopTimeProto) GetDropOffType() int32 {
-	if x != nil && x.DropOffType != nil {
-		return *x.DropOffType
+func (x *TransferProto) GetMinTransferTime() int32 {
+	if x != nil && x.MinTransferTime != nil {
+		return *x.MinTransferTime
 	}
 	return 0
 }
 
-func (x *StopTimeProto) GetShapeDistTraveled() float64 {
-	if x != nil && x.ShapeDistTraveled != nil {
-		return *x.ShapeDistTraveled
+type FrequencyProto struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	TripId        *string                `protobuf:"bytes,1,opt,name=trip_id,json=tripId" json:"trip_id,omitempty"`
+	StartTime     *string                `protobuf:"bytes,2,opt,name=start_time,json=startTime" json:"start_time,omitempty"`
+	EndTime       *string                `protobuf:"bytes,3,opt,name=end_time,json=endTime" json:"end_time,omitempty"`
+	HeadwaySecs   *int32                 `protobuf:"varint,4,opt,name=headway_secs,json=headwaySecs" json:"headway_secs,omitempty"`
+	ExactTimes    *int32                 `protobuf:"varint,5,opt,name=exact_times,json=exactTimes" json:"exact_times,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *FrequencyProto) Reset() {
+	*x = FrequencyProto{}
+	mi := &file_transit_proto_msgTypes[18]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *FrequencyProto) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*FrequencyProto) ProtoMessage() {}
+
+func (x *FrequencyProto) ProtoReflect() protoreflect.Message {
+	mi := &file_transit_proto_msgTypes[18]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use FrequencyProto.ProtoReflect.Descriptor instead.
+func (*FrequencyProto) Descriptor() ([]byte, []int) {
+	return file_transit_proto_rawDescGZIP(), []int{18}
+}
+
+func (x *FrequencyProto) GetTripId() string {
+	if x != nil && x.TripId != nil {
+		return *x.TripId
+	}
+	return ""
+}
+
+func (x *FrequencyProto) GetStartTime() string {
+	if x != nil && x.StartTime != nil {
+		return *x.StartTime
+	}
+	return ""
+}
+
+func (x *FrequencyProto) GetEndTime() string {
+	if x != nil && x.EndTime != nil {
+		return *x.EndTime
+	}
+	return ""
+}
+
+func (x *FrequencyProto) GetHeadwaySecs() int32 {
+	if x != nil && x.HeadwaySecs != nil {
+		return *x.HeadwaySecs
 	}
 	return 0
 }
 
-func (x *StopTimeProto) GetTimepoint() int32 {
-	if x != nil && x.Timepoint != nil {
-		return *x.Timepoint
+func (x *FrequencyProto) GetExactTimes() int32 {
+	if x != nil && x.ExactTimes != nil {
+		return *x.ExactTimes
 	}
 	return 0
 }
@@ -555,7 +1651,7 @@ type AlertEntityProto struct {
 
 func (x *AlertEntityProto) Reset() {
 	*x = AlertEntityProto{}
-	mi := &file_transit_proto_msgTypes[5]
+	mi := &file_transit_proto_msgTypes[19]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -567,7 +1663,7 @@ func (x *AlertEntityProto) String() string {
 func (*AlertEntityProto) ProtoMessage() {}
 
 func (x *AlertEntityProto) ProtoReflect() protoreflect.Message {
-	mi := &file_transit_proto_msgTypes[5]
+	mi := &file_transit_proto_msgTypes[19]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -580,7 +1676,7 @@ func (x *AlertEntityProto) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use AlertEntityProto.ProtoReflect.Descriptor instead.
 func (*AlertEntityProto) Descriptor() ([]byte, []int) {
-	return file_transit_proto_rawDescGZIP(), []int{5}
+	return file_transit_proto_rawDescGZIP(), []int{19}
 }
 
 func (x *AlertEntityProto) GetId() string {
@@ -611,7 +1707,7 @@ type AlertProto struct {
 
 func (x *AlertProto) Reset() {
 	*x = AlertProto{}
-	mi := &file_transit_proto_msgTypes[6]
+	mi := &file_transit_proto_msgTypes[20]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -623,7 +1719,7 @@ func (x *AlertProto) String() string {
 func (*AlertProto) ProtoMessage() {}
 
 func (x *AlertProto) ProtoReflect() protoreflect.Message {
-	mi := &file_transit_proto_msgTypes[6]
+	mi := &file_transit_proto_msgTypes[20]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -636,7 +1732,7 @@ func (x *AlertProto) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use AlertProto.ProtoReflect.Descriptor instead.
 func (*AlertProto) Descriptor() ([]byte, []int) {
-	return file_transit_proto_rawDescGZIP(), []int{6}
+	return file_transit_proto_rawDescGZIP(), []int{20}
 }
 
 func (x *AlertProto) GetActivePeriod() []*ActivePeriodProto {
@@ -691,7 +1787,7 @@ type ActivePeriodProto struct {
 
 func (x *ActivePeriodProto) Reset() {
 	*x = ActivePeriodProto{}
-	mi := &file_transit_proto_msgTypes[7]
+	mi := &file_transit_proto_msgTypes[21]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -703,7 +1799,7 @@ func (x *ActivePeriodProto) String() string {
 func (*ActivePeriodProto) ProtoMessage() {}
 
 func (x *ActivePeriodProto) ProtoReflect() protoreflect.Message {
-	mi := &file_transit_proto_msgTypes[7]
+	mi := &file_transit_proto_msgTypes[21]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -716,7 +1812,7 @@ func (x *ActivePeriodProto) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use ActivePeriodProto.ProtoReflect.Descriptor instead.
 func (*ActivePeriodProto) Descriptor() ([]byte, []int) {
-	return file_transit_proto_rawDescGZIP(), []int{7}
+	return file_transit_proto_rawDescGZIP(), []int{21}
 }
 
 func (x *ActivePeriodProto) GetStart() int64 {
@@ -745,7 +1841,7 @@ type InformedEntityProto struct {
 
 func (x *InformedEntityProto) Reset() {
 	*x = InformedEntityProto{}
-	mi := &file_transit_proto_msgTypes[8]
+	mi := &file_transit_proto_msgTypes[22]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -757,7 +1853,7 @@ func (x *InformedEntityProto) String() string {
 func (*InformedEntityProto) ProtoMessage() {}
 
 func (x *InformedEntityProto) ProtoReflect() protoreflect.Message {
-	mi := &file_transit_proto_msgTypes[8]
+	mi := &file_transit_proto_msgTypes[22]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -770,7 +1866,7 @@ func (x *InformedEntityProto) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use InformedEntityProto.ProtoReflect.Descriptor instead.
 func (*InformedEntityProto) Descriptor() ([]byte, []int) {
-	return file_transit_proto_rawDescGZIP(), []int{8}
+	return file_transit_proto_rawDescGZIP(), []int{22}
 }
 
 func (x *InformedEntityProto) GetAgencyId() string {
@@ -810,7 +1906,7 @@ type TranslatedStringProto struct {
 
 func (x *TranslatedStringProto) Reset() {
 	*x = TranslatedStringProto{}
-	mi := &file_transit_proto_msgTypes[9]
+	mi := &file_transit_proto_msgTypes[23]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -822,7 +1918,7 @@ func (x *TranslatedStringProto) String() string {
 func (*TranslatedStringProto) ProtoMessage() {}
 
 func (x *TranslatedStringProto) ProtoReflect() protoreflect.Message {
-	mi := &file_transit_proto_msgTypes[9]
+	mi := &file_transit_proto_msgTypes[23]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -835,7 +1931,7 @@ func (x *TranslatedStringProto) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use TranslatedStringProto.ProtoReflect.Descriptor instead.
 func (*TranslatedStringProto) Descriptor() ([]byte, []int) {
-	return file_transit_proto_rawDescGZIP(), []int{9}
+	return file_transit_proto_rawDescGZIP(), []int{23}
 }
 
 func (x *TranslatedStringProto) GetTranslation() []*TranslationProto {
@@ -855,7 +1951,7 @@ type TranslationProto struct {
 
 func (x *TranslationProto) Reset() {
 	*x = TranslationProto{}
-	mi := &file_transit_proto_msgTypes[10]
+	mi := &file_transit_proto_msgTypes[24]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -867,7 +1963,7 @@ func (x *TranslationProto) String() string {
 func (*TranslationProto) ProtoMessage() {}
 
 func (x *TranslationProto) ProtoReflect() protoreflect.Message {
-	mi := &file_transit_proto_msgTypes[10]
+	mi := &file_transit_proto_msgTypes[24]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -880,7 +1976,7 @@ func (x *TranslationProto) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use TranslationProto.ProtoReflect.Descriptor instead.
 func (*TranslationProto) Descriptor() ([]byte, []int) {
-	return file_transit_proto_rawDescGZIP(), []int{10}
+	return file_transit_proto_rawDescGZIP(), []int{24}
 }
 
 func (x *TranslationProto) GetText() string {
@@ -907,7 +2003,7 @@ type TripUpdateEntityProto struct {
 
 func (x *TripUpdateEntityProto) Reset() {
 	*x = TripUpdateEntityProto{}
-	mi := &file_transit_proto_msgTypes[11]
+	mi := &file_transit_proto_msgTypes[25]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -919,7 +2015,7 @@ func (x *TripUpdateEntityProto) String() string {
 func (*TripUpdateEntityProto) ProtoMessage() {}
 
 func (x *TripUpdateEntityProto) ProtoReflect() protoreflect.Message {
-	mi := &file_transit_proto_msgTypes[11]
+	mi := &file_transit_proto_msgTypes[25]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -932,7 +2028,7 @@ func (x *TripUpdateEntityProto) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use TripUpdateEntityProto.ProtoReflect.Descriptor instead.
 func (*TripUpdateEntityProto) Descriptor() ([]byte, []int) {
-	return file_transit_proto_rawDescGZIP(), []int{11}
+	return file_transit_proto_rawDescGZIP(), []int{25}
 }
 
 func (x *TripUpdateEntityProto) GetId() string {
@@ -955,13 +2051,14 @@ type TripUpdateProto struct {
 	Vehicle        *VehicleDescriptorProto `protobuf:"bytes,2,opt,name=vehicle" json:"vehicle,omitempty"`
 	StopTimeUpdate []*StopTimeUpdateProto  `protobuf:"bytes,3,rep,name=stop_time_update,json=stopTimeUpdate" json:"stop_time_update,omitempty"`
 	Timestamp      *int64                  `protobuf:"varint,4,opt,name=timestamp" json:"timestamp,omitempty"`
+	Source         *int32                  `protobuf:"varint,5,opt,name=source" json:"source,omitempty"` // 0 = agency feed, 1 = estimated from vehicle position
 	unknownFields  protoimpl.UnknownFields
 	sizeCache      protoimpl.SizeCache
 }
 
 func (x *TripUpdateProto) Reset() {
 	*x = TripUpdateProto{}
-	mi := &file_transit_proto_msgTypes[12]
+	mi := &file_transit_proto_msgTypes[26]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -973,7 +2070,7 @@ func (x *TripUpdateProto) String() string {
 func (*TripUpdateProto) ProtoMessage() {}
 
 func (x *TripUpdateProto) ProtoReflect() protoreflect.Message {
-	mi := &file_transit_proto_msgTypes[12]
+	mi := &file_transit_proto_msgTypes[26]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -986,7 +2083,7 @@ func (x *TripUpdateProto) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use TripUpdateProto.ProtoReflect.Descriptor instead.
 func (*TripUpdateProto) Descriptor() ([]byte, []int) {
-	return file_transit_proto_rawDescGZIP(), []int{12}
+	return file_transit_proto_rawDescGZIP(), []int{26}
 }
 
 func (x *TripUpdateProto) GetTrip() *TripDescriptorProto {
@@ -1017,19 +2114,27 @@ func (x *TripUpdateProto) GetTimestamp() int64 {
 	return 0
 }
 
+func (x *TripUpdateProto) GetSource() int32 {
+	if x != nil && x.Source != nil {
+		return *x.Source
+	}
+	return 0
+}
+
 type TripDescriptorProto struct {
 	state                protoimpl.MessageState `protogen:"open.v1"`
 	TripId               *string                `protobuf:"bytes,1,opt,name=trip_id,json=tripId" json:"trip_id,omitempty"`
 	RouteId              *string                `protobuf:"bytes,2,opt,name=route_id,json=routeId" json:"route_id,omitempty"`
 	DirectionId          *int32                 `protobuf:"varint,3,opt,name=direction_id,json=directionId" json:"direction_id,omitempty"`
 	ScheduleRelationship *int32                 `protobuf:"varint,4,opt,name=schedule_relationship,json=scheduleRelationship" json:"schedule_relationship,omitempty"`
+	StartDate            *string                `protobuf:"bytes,5,opt,name=start_date,json=startDate" json:"start_date,omitempty"`
 	unknownFields        protoimpl.UnknownFields
 	sizeCache            protoimpl.SizeCache
 }
 
 func (x *TripDescriptorProto) Reset() {
 	*x = TripDescriptorProto{}
-	mi := &file_transit_proto_msgTypes[13]
+	mi := &file_transit_proto_msgTypes[27]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -1041,7 +2146,7 @@ func (x *TripDescriptorProto) String() string {
 func (*TripDescriptorProto) ProtoMessage() {}
 
 func (x *TripDescriptorProto) ProtoReflect() protoreflect.Message {
-	mi := &file_transit_proto_msgTypes[13]
+	mi := &file_transit_proto_msgTypes[27]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1054,7 +2159,7 @@ func (x *TripDescriptorProto) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use TripDescriptorProto.ProtoReflect.Descriptor instead.
 func (*TripDescriptorProto) Descriptor() ([]byte, []int) {
-	return file_transit_proto_rawDescGZIP(), []int{13}
+	return file_transit_proto_rawDescGZIP(), []int{27}
 }
 
 func (x *TripDescriptorProto) GetTripId() string {
@@ -1085,6 +2190,13 @@ func (x *TripDescriptorProto) GetScheduleRelationship() int32 {
 	return 0
 }
 
+func (x *TripDescriptorProto) GetStartDate() string {
+	if x != nil && x.StartDate != nil {
+		return *x.StartDate
+	}
+	return ""
+}
+
 type VehicleDescriptorProto struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Id            *string                `protobuf:"bytes,1,opt,name=id" json:"id,omitempty"`
@@ -1095,7 +2207,7 @@ type VehicleDescriptorProto struct {
 
 func (x *VehicleDescriptorProto) Reset() {
 	*x = VehicleDescriptorProto{}
-	mi := &file_transit_proto_msgTypes[14]
+	mi := &file_transit_proto_msgTypes[28]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -1107,7 +2219,7 @@ func (x *VehicleDescriptorProto) String() string {
 func (*VehicleDescriptorProto) ProtoMessage() {}
 
 func (x *VehicleDescriptorProto) ProtoReflect() protoreflect.Message {
-	mi := &file_transit_proto_msgTypes[14]
+	mi := &file_transit_proto_msgTypes[28]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1120,7 +2232,7 @@ func (x *VehicleDescriptorProto) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use VehicleDescriptorProto.ProtoReflect.Descriptor instead.
 func (*VehicleDescriptorProto) Descriptor() ([]byte, []int) {
-	return file_transit_proto_rawDescGZIP(), []int{14}
+	return file_transit_proto_rawDescGZIP(), []int{28}
 }
 
 func (x *VehicleDescriptorProto) GetId() string {
@@ -1150,7 +2262,7 @@ type StopTimeUpdateProto struct {
 
 func (x *StopTimeUpdateProto) Reset() {
 	*x = StopTimeUpdateProto{}
-	mi := &file_transit_proto_msgTypes[15]
+	mi := &file_transit_proto_msgTypes[29]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -1162,7 +2274,7 @@ func (x *StopTimeUpdateProto) String() string {
 func (*StopTimeUpdateProto) ProtoMessage() {}
 
 func (x *StopTimeUpdateProto) ProtoReflect() protoreflect.Message {
-	mi := &file_transit_proto_msgTypes[15]
+	mi := &file_transit_proto_msgTypes[29]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1175,7 +2287,7 @@ func (x *StopTimeUpdateProto) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use StopTimeUpdateProto.ProtoReflect.Descriptor instead.
 func (*StopTimeUpdateProto) Descriptor() ([]byte, []int) {
-	return file_transit_proto_rawDescGZIP(), []int{15}
+	return file_transit_proto_rawDescGZIP(), []int{29}
 }
 
 func (x *StopTimeUpdateProto) GetStopSequence() int32 {
@@ -1216,13 +2328,15 @@ func (x *StopTimeUpdateProto) GetScheduleRelationship() int32 {
 type StopTimeEventProto struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Time          *int64                 `protobuf:"varint,1,opt,name=time" json:"time,omitempty"`
+	Delay         *int32                 `protobuf:"varint,2,opt,name=delay" json:"delay,omitempty"`
+	Uncertainty   *int32                 `protobuf:"varint,3,opt,name=uncertainty" json:"uncertainty,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
 func (x *StopTimeEventProto) Reset() {
 	*x = StopTimeEventProto{}
-	mi := &file_transit_proto_msgTypes[16]
+	mi := &file_transit_proto_msgTypes[30]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -1234,7 +2348,7 @@ func (x *StopTimeEventProto) String() string {
 func (*StopTimeEventProto) ProtoMessage() {}
 
 func (x *StopTimeEventProto) ProtoReflect() protoreflect.Message {
-	mi := &file_transit_proto_msgTypes[16]
+	mi := &file_transit_proto_msgTypes[30]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1247,7 +2361,7 @@ func (x *StopTimeEventProto) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use StopTimeEventProto.ProtoReflect.Descriptor instead.
 func (*StopTimeEventProto) Descriptor() ([]byte, []int) {
-	return file_transit_proto_rawDescGZIP(), []int{16}
+	return file_transit_proto_rawDescGZIP(), []int{30}
 }
 
 func (x *StopTimeEventProto) GetTime() int64 {
@@ -1257,6 +2371,20 @@ func (x *StopTimeEventProto) GetTime() int64 {
 	return 0
 }
 
+func (x *StopTimeEventProto) GetDelay() int32 {
+	if x != nil && x.Delay != nil {
+		return *x.Delay
+	}
+	return 0
+}
+
+func (x *StopTimeEventProto) GetUncertainty() int32 {
+	if x != nil && x.Uncertainty != nil {
+		return *x.Uncertainty
+	}
+	return 0
+}
+
 type VehiclePositionEntityProto struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Id            *string                `protobuf:"bytes,1,opt,name=id" json:"id,omitempty"`
@@ -1267,7 +2395,7 @@ type VehiclePositionEntityProto struct {
 
 func (x *VehiclePositionEntityProto) Reset() {
 	*x = VehiclePositionEntityProto{}
-	mi := &file_transit_proto_msgTypes[17]
+	mi := &file_transit_proto_msgTypes[31]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -1279,7 +2407,7 @@ func (x *VehiclePositionEntityProto) String() string {
 func (*VehiclePositionEntityProto) ProtoMessage() {}
 
 func (x *VehiclePositionEntityProto) ProtoReflect() protoreflect.Message {
-	mi := &file_transit_proto_msgTypes[17]
+	mi := &file_transit_proto_msgTypes[31]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1292,7 +2420,7 @@ func (x *VehiclePositionEntityProto) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use VehiclePositionEntityProto.ProtoReflect.Descriptor instead.
 func (*VehiclePositionEntityProto) Descriptor() ([]byte, []int) {
-	return file_transit_proto_rawDescGZIP(), []int{17}
+	return file_transit_proto_rawDescGZIP(), []int{31}
 }
 
 func (x *VehiclePositionEntityProto) GetId() string {
@@ -1324,7 +2452,7 @@ type VehiclePositionProto struct {
 
 func (x *VehiclePositionProto) Reset() {
 	*x = VehiclePositionProto{}
-	mi := &file_transit_proto_msgTypes[18]
+	mi := &file_transit_proto_msgTypes[32]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -1336,7 +2464,7 @@ func (x *VehiclePositionProto) String() string {
 func (*VehiclePositionProto) ProtoMessage() {}
 
 func (x *VehiclePositionProto) ProtoReflect() protoreflect.Message {
-	mi := &file_transit_proto_msgTypes[18]
+	mi := &file_transit_proto_msgTypes[32]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1349,7 +2477,7 @@ func (x *VehiclePositionProto) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use VehiclePositionProto.ProtoReflect.Descriptor instead.
 func (*VehiclePositionProto) Descriptor() ([]byte, []int) {
-	return file_transit_proto_rawDescGZIP(), []int{18}
+	return file_transit_proto_rawDescGZIP(), []int{32}
 }
 
 func (x *VehiclePositionProto) GetTrip() *TripDescriptorProto {
@@ -1412,7 +2540,7 @@ type GeoPositionProto struct {
 
 func (x *GeoPositionProto) Reset() {
 	*x = GeoPositionProto{}
-	mi := &file_transit_proto_msgTypes[19]
+	mi := &file_transit_proto_msgTypes[33]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -1424,7 +2552,7 @@ func (x *GeoPositionProto) String() string {
 func (*GeoPositionProto) ProtoMessage() {}
 
 func (x *GeoPositionProto) ProtoReflect() protoreflect.Message {
-	mi := &file_transit_proto_msgTypes[19]
+	mi := &file_transit_proto_msgTypes[33]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1437,7 +2565,7 @@ func (x *GeoPositionProto) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use GeoPositionProto.ProtoReflect.Descriptor instead.
 func (*GeoPositionProto) Descriptor() ([]byte, []int) {
-	return file_transit_proto_rawDescGZIP(), []int{19}
+	return file_transit_proto_rawDescGZIP(), []int{33}
 }
 
 func (x *GeoPositionProto) GetLatitude() float64 {
@@ -1471,7 +2599,7 @@ type VehiclePositionCollection struct {
 
 func (x *VehiclePositionCollection) Reset() {
 	*x = VehiclePositionCollection{}
-	mi := &file_transit_proto_msgTypes[20]
+	mi := &file_transit_proto_msgTypes[34]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -1483,7 +2611,7 @@ func (x *VehiclePositionCollection) String() string {
 func (*VehiclePositionCollection) ProtoMessage() {}
 
 func (x *VehiclePositionCollection) ProtoReflect() protoreflect.Message {
-	mi := &file_transit_proto_msgTypes[20]
+	mi := &file_transit_proto_msgTypes[34]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1496,7 +2624,7 @@ func (x *VehiclePositionCollection) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use VehiclePositionCollection.ProtoReflect.Descriptor instead.
 func (*VehiclePositionCollection) Descriptor() ([]byte, []int) {
-	return file_transit_proto_rawDescGZIP(), []int{20}
+	return file_transit_proto_rawDescGZIP(), []int{34}
 }
 
 func (x *VehiclePositionCollection) GetEntities() []*VehiclePositionEntityProto {
@@ -1523,7 +2651,7 @@ type AlertCollection struct {
 
 func (x *AlertCollection) Reset() {
 	*x = AlertCollection{}
-	mi := &file_transit_proto_msgTypes[21]
+	mi := &file_transit_proto_msgTypes[35]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -1535,7 +2663,7 @@ func (x *AlertCollection) String() string {
 func (*AlertCollection) ProtoMessage() {}
 
 func (x *AlertCollection) ProtoReflect() protoreflect.Message {
-	mi := &file_transit_proto_msgTypes[21]
+	mi := &file_transit_proto_msgTypes[35]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1548,7 +2676,7 @@ func (x *AlertCollection) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use AlertCollection.ProtoReflect.Descriptor instead.
 func (*AlertCollection) Descriptor() ([]byte, []int) {
-	return file_transit_proto_rawDescGZIP(), []int{21}
+	return file_transit_proto_rawDescGZIP(), []int{35}
 }
 
 func (x *AlertCollection) GetEntities() []*AlertEntityProto {
@@ -1575,7 +2703,7 @@ type TripUpdateCollection struct {
 
 func (x *TripUpdateCollection) Reset() {
 	*x = TripUpdateCollection{}
-	mi := &file_transit_proto_msgTypes[22]
+	mi := &file_transit_proto_msgTypes[36]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -1587,7 +2715,7 @@ func (x *TripUpdateCollection) String() string {
 func (*TripUpdateCollection) ProtoMessage() {}
 
 func (x *TripUpdateCollection) ProtoReflect() protoreflect.Message {
-	mi := &file_transit_proto_msgTypes[22]
+	mi := &file_transit_proto_msgTypes[36]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1600,7 +2728,7 @@ func (x *TripUpdateCollection) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use TripUpdateCollection.ProtoReflect.Descriptor instead.
 func (*TripUpdateCollection) Descriptor() ([]byte, []int) {
-	return file_transit_proto_rawDescGZIP(), []int{22}
+	return file_transit_proto_rawDescGZIP(), []int{36}
 }
 
 func (x *TripUpdateCollection) GetEntities() []*TripUpdateEntityProto {
@@ -1631,7 +2759,7 @@ const file_transit_proto_rawDesc = "" +
 	"\rtrip_headsign\x18\x04 \x01(\tR\ftripHeadsign\x12!\n" +
 	"\fdirection_id\x18\x05 \x01(\x05R\vdirectionId\x12\x19\n" +
 	"\bblock_id\x18\x06 \x01(\tR\ablockId\x12\x19\n" +
-	"\bshape_id\x18\a \x01(\tR\ashapeId\"\xbc\x02\n" +
+	"\bshape_id\x18\a \x01(\tR\ashapeId\"\x85\x03\n" +
 	"\n" +
 	"RouteProto\x12\x19\n" +
 	"\broute_id\x18\x01 \x01(\tR\arouteId\x12\x1b\n" +
@@ -1645,7 +2773,11 @@ const file_transit_proto_rawDesc = "" +
 	"\troute_url\x18\a \x01(\tR\brouteUrl\x12\x1f\n" +
 	"\vroute_color\x18\b \x01(\tR\n" +
 	"routeColor\x12(\n" +
-	"\x10route_text_color\x18\t \x01(\tR\x0erouteTextColor\"\xc7\x01\n" +
+	"\x10route_text_color\x18\t \x01(\tR\x0erouteTextColor\x12\x1d\n" +
+	"\n" +
+	"network_id\x18\n" +
+	" \x01(\tR\tnetworkId\x12(\n" +
+	"\x10route_sort_order\x18\v \x01(\x05R\x0erouteSortOrder\"\xc7\x01\n" +
 	"\n" +
 	"ShapeProto\x12\x19\n" +
 	"\bshape_id\x18\x01 \x01(\tR\ashapeId\x12 \n" +
@@ -1654,7 +2786,7 @@ const file_transit_proto_rawDesc = "" +
 	"\fshape_pt_lon\x18\x03 \x01(\x01R\n" +
 	"shapePtLon\x12*\n" +
 	"\x11shape_pt_sequence\x18\x04 \x01(\x05R\x0fshapePtSequence\x12.\n" +
-	"\x13shape_dist_traveled\x18\x05 \x01(\x01R\x11shapeDistTraveled\"\x87\x03\n" +
+	"\x13shape_dist_traveled\x18\x05 \x01(\x01R\x11shapeDistTraveled\"\xa2\x03\n" +
 	"\tStopProto\x12\x17\n" +
 	"\astop_id\x18\x01 \x01(\tR\x06stopId\x12\x1b\n" +
 	"\tstop_code\x18\x02 \x01(\tR\bstopCode\x12\x1b\n" +
@@ -1668,7 +2800,27 @@ const file_transit_proto_rawDesc = "" +
 	"\x0eparent_station\x18\n" +
 	" \x01(\tR\rparentStation\x12#\n" +
 	"\rstop_timezone\x18\v \x01(\tR\fstopTimezone\x12/\n" +
-	"\x13wheelchair_boarding\x18\f \x01(\x05R\x12wheelchairBoarding\"\xe8\x02\n" +
+	"\x13wheelchair_boarding\x18\f \x01(\x05R\x12wheelchairBoarding\x12\x19\n" +
+	"\blevel_id\x18\r \x01(\tR\alevelId\"\xa0\x02\n" +
+	"\rCalendarProto\x12\x1d\n" +
+	"\n" +
+	"service_id\x18\x01 \x01(\tR\tserviceId\x12\x16\n" +
+	"\x06monday\x18\x02 \x01(\x05R\x06monday\x12\x18\n" +
+	"\atuesday\x18\x03 \x01(\x05R\atuesday\x12\x1c\n" +
+	"\twednesday\x18\x04 \x01(\x05R\twednesday\x12\x1a\n" +
+	"\bthursday\x18\x05 \x01(\x05R\bthursday\x12\x16\n" +
+	"\x06friday\x18\x06 \x01(\x05R\x06friday\x12\x1a\n" +
+	"\bsaturday\x18\a \x01(\x05R\bsaturday\x12\x16\n" +
+	"\x06sunday\x18\b \x01(\x05R\x06sunday\x12\x1d\n" +
+	"\n" +
+	"start_date\x18\t \x01(\tR\tstartDate\x12\x19\n" +
+	"\bend_date\x18\n" +
+	" \x01(\tR\aendDate\"m\n" +
+	"\x11CalendarDateProto\x12\x1d\n" +
+	"\n" +
+	"service_id\x18\x01 \x01(\tR\tserviceId\x12\x12\n" +
+	"\x04date\x18\x02 \x01(\tR\x04date\x12%\n" +
+	"\x0eexception_type\x18\x03 \x01(\x05R\rexceptionType\"\xe8\x02\n" +
 	"\rStopTimeProto\x12\x17\n" +
 	"\atrip_id\x18\x01 \x01(\tR\x06tripId\x12!\n" +
 	"\farrival_time\x18\x02 \x01(\tR\varrivalTime\x12%\n" +
@@ -1681,7 +2833,97 @@ const file_transit_proto_rawDesc = "" +
 	"\rdrop_off_type\x18\b \x01(\x05R\vdropOffType\x12.\n" +
 	"\x13shape_dist_traveled\x18\t \x01(\x01R\x11shapeDistTraveled\x12\x1c\n" +
 	"\ttimepoint\x18\n" +
-	" \x01(\x05R\ttimepoint\"P\n" +
+	" \x01(\x05R\ttimepoint\"\x84\x01\n" +
+	"\x0eFareMediaProto\x12\"\n" +
+	"\rfare_media_id\x18\x01 \x01(\tR\vfareMediaId\x12&\n" +
+	"\x0ffare_media_name\x18\x02 \x01(\tR\rfareMediaName\x12&\n" +
+	"\x0ffare_media_type\x18\x03 \x01(\x05R\rfareMediaType\"\xbe\x01\n" +
+	"\x10FareProductProto\x12&\n" +
+	"\x0ffare_product_id\x18\x01 \x01(\tR\rfareProductId\x12*\n" +
+	"\x11fare_product_name\x18\x02 \x01(\tR\x0ffareProductName\x12\"\n" +
+	"\rfare_media_id\x18\x03 \x01(\tR\vfareMediaId\x12\x16\n" +
+	"\x06amount\x18\x04 \x01(\x01R\x06amount\x12\x1a\n" +
+	"\bcurrency\x18\x05 \x01(\tR\bcurrency\"\xa5\x02\n" +
+	"\x10FareLegRuleProto\x12 \n" +
+	"\fleg_group_id\x18\x01 \x01(\tR\n" +
+	"legGroupId\x12\x1d\n" +
+	"\n" +
+	"network_id\x18\x02 \x01(\tR\tnetworkId\x12 \n" +
+	"\ffrom_area_id\x18\x03 \x01(\tR\n" +
+	"fromAreaId\x12\x1c\n" +
+	"\n" +
+	"to_area_id\x18\x04 \x01(\tR\btoAreaId\x125\n" +
+	"\x17from_timeframe_group_id\x18\x05 \x01(\tR\x14fromTimeframeGroupId\x121\n" +
+	"\x15to_timeframe_group_id\x18\x06 \x01(\tR\x12toTimeframeGroupId\x12&\n" +
+	"\x0ffare_product_id\x18\a \x01(\tR\rfareProductId\"\xbd\x02\n" +
+	"\x15FareTransferRuleProto\x12)\n" +
+	"\x11from_leg_group_id\x18\x01 \x01(\tR\x0efromLegGroupId\x12%\n" +
+	"\x0fto_leg_group_id\x18\x02 \x01(\tR\ftoLegGroupId\x12%\n" +
+	"\x0etransfer_count\x18\x03 \x01(\x05R\rtransferCount\x12%\n" +
+	"\x0eduration_limit\x18\x04 \x01(\x05R\rdurationLimit\x12.\n" +
+	"\x13duration_limit_type\x18\x05 \x01(\x05R\x11durationLimitType\x12,\n" +
+	"\x12fare_transfer_type\x18\x06 \x01(\x05R\x10fareTransferType\x12&\n" +
+	"\x0ffare_product_id\x18\a \x01(\tR\rfareProductId\"P\n" +
+	"\fNetworkProto\x12\x1d\n" +
+	"\n" +
+	"network_id\x18\x01 \x01(\tR\tnetworkId\x12!\n" +
+	"\fnetwork_name\x18\x02 \x01(\tR\vnetworkName\"M\n" +
+	"\x11RouteNetworkProto\x12\x19\n" +
+	"\broute_id\x18\x01 \x01(\tR\arouteId\x12\x1d\n" +
+	"\n" +
+	"network_id\x18\x02 \x01(\tR\tnetworkId\"A\n" +
+	"\tAreaProto\x12\x17\n" +
+	"\aarea_id\x18\x01 \x01(\tR\x06areaId\x12\x1b\n" +
+	"\tarea_name\x18\x02 \x01(\tR\bareaName\"A\n" +
+	"\rStopAreaProto\x12\x17\n" +
+	"\aarea_id\x18\x01 \x01(\tR\x06areaId\x12\x17\n" +
+	"\astop_id\x18\x02 \x01(\tR\x06stopId\"g\n" +
+	"\n" +
+	"LevelProto\x12\x19\n" +
+	"\blevel_id\x18\x01 \x01(\tR\alevelId\x12\x1f\n" +
+	"\vlevel_index\x18\x02 \x01(\x01R\n" +
+	"levelIndex\x12\x1d\n" +
+	"\n" +
+	"level_name\x18\x03 \x01(\tR\tlevelName\"\xb0\x03\n" +
+	"\fPathwayProto\x12\x1d\n" +
+	"\n" +
+	"pathway_id\x18\x01 \x01(\tR\tpathwayId\x12 \n" +
+	"\ffrom_stop_id\x18\x02 \x01(\tR\n" +
+	"fromStopId\x12\x1c\n" +
+	"\n" +
+	"to_stop_id\x18\x03 \x01(\tR\btoStopId\x12!\n" +
+	"\fpathway_mode\x18\x04 \x01(\x05R\vpathwayMode\x12)\n" +
+	"\x10is_bidirectional\x18\x05 \x01(\x05R\x0fisBidirectional\x12\x16\n" +
+	"\x06length\x18\x06 \x01(\x01R\x06length\x12%\n" +
+	"\x0etraversal_time\x18\a \x01(\x05R\rtraversalTime\x12\x1f\n" +
+	"\vstair_count\x18\b \x01(\x05R\n" +
+	"stairCount\x12\x1b\n" +
+	"\tmax_slope\x18\t \x01(\x01R\bmaxSlope\x12\x1b\n" +
+	"\tmin_width\x18\n" +
+	" \x01(\x01R\bminWidth\x12#\n" +
+	"\rsignposted_as\x18\v \x01(\tR\fsignpostedAs\x124\n" +
+	"\x16reversed_signposted_as\x18\f \x01(\tR\x14reversedSignpostedAs\"\xa4\x02\n" +
+	"\rTransferProto\x12 \n" +
+	"\ffrom_stop_id\x18\x01 \x01(\tR\n" +
+	"fromStopId\x12\x1c\n" +
+	"\n" +
+	"to_stop_id\x18\x02 \x01(\tR\btoStopId\x12\"\n" +
+	"\rfrom_route_id\x18\x03 \x01(\tR\vfromRouteId\x12\x1e\n" +
+	"\vto_route_id\x18\x04 \x01(\tR\ttoRouteId\x12 \n" +
+	"\ffrom_trip_id\x18\x05 \x01(\tR\n" +
+	"fromTripId\x12\x1c\n" +
+	"\n" +
+	"to_trip_id\x18\x06 \x01(\tR\btoTripId\x12#\n" +
+	"\rtransfer_type\x18\a \x01(\x05R\ftransferType\x12*\n" +
+	"\x11min_transfer_time\x18\b \x01(\x05R\x0fminTransferTime\"\xa7\x01\n" +
+	"\x0eFrequencyProto\x12\x17\n" +
+	"\atrip_id\x18\x01 \x01(\tR\x06tripId\x12\x1d\n" +
+	"\n" +
+	"start_time\x18\x02 \x01(\tR\tstartTime\x12\x19\n" +
+	"\bend_time\x18\x03 \x01(\tR\aendTime\x12!\n" +
+	"\fheadway_secs\x18\x04 \x01(\x05R\vheadwaySecs\x12\x1f\n" +
+	"\vexact_times\x18\x05 \x01(\x05R\n" +
+	"exactTimes\"P\n" +
 	"\x10AlertEntityProto\x12\x0e\n" +
 	"\x02id\x18\x01 \x01(\tR\x02id\x12,\n" +
 	"\x05alert\x18\x02 \x01(\v2\x16.transit.v1.AlertProtoR\x05alert\"\xda\x02\n" +
@@ -1711,17 +2953,20 @@ const file_transit_proto_rawDesc = "" +
 	"\x15TripUpdateEntityProto\x12\x0e\n" +
 	"\x02id\x18\x01 \x01(\tR\x02id\x12<\n" +
 	"\vtrip_update\x18\x02 \x01(\v2\x1b.transit.v1.TripUpdateProtoR\n" +
-	"tripUpdate\"\xed\x01\n" +
+	"tripUpdate\"\x85\x02\n" +
 	"\x0fTripUpdateProto\x123\n" +
 	"\x04trip\x18\x01 \x01(\v2\x1f.transit.v1.TripDescriptorProtoR\x04trip\x12<\n" +
 	"\avehicle\x18\x02 \x01(\v2\".transit.v1.VehicleDescriptorProtoR\avehicle\x12I\n" +
 	"\x10stop_time_update\x18\x03 \x03(\v2\x1f.transit.v1.StopTimeUpdateProtoR\x0estopTimeUpdate\x12\x1c\n" +
-	"\ttimestamp\x18\x04 \x01(\x03R\ttimestamp\"\xa1\x01\n" +
+	"\ttimestamp\x18\x04 \x01(\x03R\ttimestamp\x12\x16\n" +
+	"\x06source\x18\x05 \x01(\x05R\x06source\"\xc0\x01\n" +
 	"\x13TripDescriptorProto\x12\x17\n" +
 	"\atrip_id\x18\x01 \x01(\tR\x06tripId\x12\x19\n" +
 	"\broute_id\x18\x02 \x01(\tR\arouteId\x12!\n" +
 	"\fdirection_id\x18\x03 \x01(\x05R\vdirectionId\x123\n" +
-	"\x15schedule_relationship\x18\x04 \x01(\x05R\x14scheduleRelationship\">\n" +
+	"\x15schedule_relationship\x18\x04 \x01(\x05R\x14scheduleRelationship\x12\x1d\n" +
+	"\n" +
+	"start_date\x18\x05 \x01(\tR\tstartDate\">\n" +
 	"\x16VehicleDescriptorProto\x12\x0e\n" +
 	"\x02id\x18\x01 \x01(\tR\x02id\x12\x14\n" +
 	"\x05label\x18\x02 \x01(\tR\x05label\"\x80\x02\n" +
@@ -1730,9 +2975,11 @@ const file_transit_proto_rawDesc = "" +
 	"\astop_id\x18\x02 \x01(\tR\x06stopId\x128\n" +
 	"\aarrival\x18\x03 \x01(\v2\x1e.transit.v1.StopTimeEventProtoR\aarrival\x12<\n" +
 	"\tdeparture\x18\x04 \x01(\v2\x1e.transit.v1.StopTimeEventProtoR\tdeparture\x123\n" +
-	"\x15schedule_relationship\x18\x05 \x01(\x05R\x14scheduleRelationship\"(\n" +
+	"\x15schedule_relationship\x18\x05 \x01(\x05R\x14scheduleRelationship\"`\n" +
 	"\x12StopTimeEventProto\x12\x12\n" +
-	"\x04time\x18\x01 \x01(\x03R\x04time\"h\n" +
+	"\x04time\x18\x01 \x01(\x03R\x04time\x12\x14\n" +
+	"\x05delay\x18\x02 \x01(\x05R\x05delay\x12 \n" +
+	"\vuncertainty\x18\x03 \x01(\x05R\vuncertainty\"h\n" +
 	"\x1aVehiclePositionEntityProto\x12\x0e\n" +
 	"\x02id\x18\x01 \x01(\tR\x02id\x12:\n" +
 	"\avehicle\x18\x02 \x01(\v2 .transit.v1.VehiclePositionProtoR\avehicle\"\xcc\x02\n" +
@@ -1770,52 +3017,66 @@ func file_transit_proto_rawDescGZIP() []byte {
 	return file_transit_proto_rawDescData
 }
 
-var file_transit_proto_msgTypes = make([]protoimpl.MessageInfo, 23)
+var file_transit_proto_msgTypes = make([]protoimpl.MessageInfo, 37)
 var file_transit_proto_goTypes = []any{
 	(*TripProto)(nil),                  // 0: transit.v1.TripProto
 	(*RouteProto)(nil),                 // 1: transit.v1.RouteProto
 	(*ShapeProto)(nil),                 // 2: transit.v1.ShapeProto
 	(*StopProto)(nil),                  // 3: transit.v1.StopProto
-	(*StopTimeProto)(nil),              // 4: transit.v1.StopTimeProto
-	(*AlertEntityProto)(nil),           // 5: transit.v1.AlertEntityProto
-	(*AlertProto)(nil),                 // 6: transit.v1.AlertProto
-	(*ActivePeriodProto)(nil),          // 7: transit.v1.ActivePeriodProto
-	(*InformedEntityProto)(nil),        // 8: transit.v1.InformedEntityProto
-	(*TranslatedStringProto)(nil),      // 9: transit.v1.TranslatedStringProto
-	(*TranslationProto)(nil),           // 10: transit.v1.TranslationProto
-	(*TripUpdateEntityProto)(nil),      // 11: transit.v1.TripUpdateEntityProto
-	(*TripUpdateProto)(nil),            // 12: transit.v1.TripUpdateProto
-	(*TripDescriptorProto)(nil),        // 13: transit.v1.TripDescriptorProto
-	(*VehicleDescriptorProto)(nil),     // 14: transit.v1.VehicleDescriptorProto
-	(*StopTimeUpdateProto)(nil),        // 15: transit.v1.StopTimeUpdateProto
-	(*StopTimeEventProto)(nil),         // 16: transit.v1.StopTimeEventProto
-	(*VehiclePositionEntityProto)(nil), // 17: transit.v1.VehiclePositionEntityProto
-	(*VehiclePositionProto)(nil),       // 18: transit.v1.VehiclePositionProto
-	(*GeoPositionProto)(nil),           // 19: transit.v1.GeoPositionProto
-	(*VehiclePositionCollection)(nil),  // 20: transit.v1.VehiclePositionCollection
-	(*AlertCollection)(nil),            // 21: transit.v1.AlertCollection
-	(*TripUpdateCollection)(nil),       // 22: transit.v1.TripUpdateCollection
+	(*CalendarProto)(nil),              // 4: transit.v1.CalendarProto
+	(*CalendarDateProto)(nil),          // 5: transit.v1.CalendarDateProto
+	(*StopTimeProto)(nil),              // 6: transit.v1.StopTimeProto
+	(*FareMediaProto)(nil),             // 7: transit.v1.FareMediaProto
+	(*FareProductProto)(nil),           // 8: transit.v1.FareProductProto
+	(*FareLegRuleProto)(nil),           // 9: transit.v1.FareLegRuleProto
+	(*FareTransferRuleProto)(nil),      // 10: transit.v1.FareTransferRuleProto
+	(*NetworkProto)(nil),               // 11: transit.v1.NetworkProto
+	(*RouteNetworkProto)(nil),          // 12: transit.v1.RouteNetworkProto
+	(*AreaProto)(nil),                  // 13: transit.v1.AreaProto
+	(*StopAreaProto)(nil),              // 14: transit.v1.StopAreaProto
+	(*LevelProto)(nil),                 // 15: transit.v1.LevelProto
+	(*PathwayProto)(nil),               // 16: transit.v1.PathwayProto
+	(*TransferProto)(nil),              // 17: transit.v1.TransferProto
+	(*FrequencyProto)(nil),             // 18: transit.v1.FrequencyProto
+	(*AlertEntityProto)(nil),           // 19: transit.v1.AlertEntityProto
+	(*AlertProto)(nil),                 // 20: transit.v1.AlertProto
+	(*ActivePeriodProto)(nil),          // 21: transit.v1.ActivePeriodProto
+	(*InformedEntityProto)(nil),        // 22: transit.v1.InformedEntityProto
+	(*TranslatedStringProto)(nil),      // 23: transit.v1.TranslatedStringProto
+	(*TranslationProto)(nil),           // 24: transit.v1.TranslationProto
+	(*TripUpdateEntityProto)(nil),      // 25: transit.v1.TripUpdateEntityProto
+	(*TripUpdateProto)(nil),            // 26: transit.v1.TripUpdateProto
+	(*TripDescriptorProto)(nil),        // 27: transit.v1.TripDescriptorProto
+	(*VehicleDescriptorProto)(nil),     // 28: transit.v1.VehicleDescriptorProto
+	(*StopTimeUpdateProto)(nil),        // 29: transit.v1.StopTimeUpdateProto
+	(*StopTimeEventProto)(nil),         // 30: transit.v1.StopTimeEventProto
+	(*VehiclePositionEntityProto)(nil), // 31: transit.v1.VehiclePositionEntityProto
+	(*VehiclePositionProto)(nil),       // 32: transit.v1.VehiclePositionProto
+	(*GeoPositionProto)(nil),           // 33: transit.v1.GeoPositionProto
+	(*VehiclePositionCollection)(nil),  // 34: transit.v1.VehiclePositionCollection
+	(*AlertCollection)(nil),            // 35: transit.v1.AlertCollection
+	(*TripUpdateCollection)(nil),       // 36: transit.v1.TripUpdateCollection
 }
 var file_transit_proto_depIdxs = []int32{
-	6,  // 0: transit.v1.AlertEntityProto.alert:type_name -> transit.v1.AlertProto
-	7,  // 1: transit.v1.AlertProto.active_period:type_name -> transit.v1.ActivePeriodProto
-	8,  // 2: transit.v1.AlertProto.informed_entity:type_name -> transit.v1.InformedEntityProto
-	9,  // 3: transit.v1.AlertProto.header_text:type_name -> transit.v1.TranslatedStringProto
-	9,  // 4: transit.v1.AlertProto.description_text:type_name -> transit.v1.TranslatedStringProto
-	10, // 5: transit.v1.TranslatedStringProto.translation:type_name -> transit.v1.TranslationProto
-	12, // 6: transit.v1.TripUpdateEntityProto.trip_update:type_name -> transit.v1.TripUpdateProto
-	13, // 7: transit.v1.TripUpdateProto.trip:type_name -> transit.v1.TripDescriptorProto
-	14, // 8: transit.v1.TripUpdateProto.vehicle:type_name -> transit.v1.VehicleDescriptorProto
-	15, // 9: transit.v1.TripUpdateProto.stop_time_update:type_name -> transit.v1.StopTimeUpdateProto
-	16, // 10: transit.v1.StopTimeUpdateProto.arrival:type_name -> transit.v1.StopTimeEventProto
-	16, // 11: transit.v1.StopTimeUpdateProto.departure:type_name -> transit.v1.StopTimeEventProto
-	18, // 12: transit.v1.VehiclePositionEntityProto.vehicle:type_name -> transit.v1.VehiclePositionProto
-	13, // 13: transit.v1.VehiclePositionProto.trip:type_name -> transit.v1.TripDescriptorProto
-	14, // 14: transit.v1.VehiclePositionProto.vehicle:type_name -> transit.v1.VehicleDescriptorProto
-	19, // 15: transit.v1.VehiclePositionProto.position:type_name -> transit.v1.GeoPositionProto
-	17, // 16: transit.v1.VehiclePositionCollection.entities:type_name -> transit.v1.VehiclePositionEntityProto
-	5,  // 17: transit.v1.AlertCollection.entities:type_name -> transit.v1.AlertEntityProto
-	11, // 18: transit.v1.TripUpdateCollection.entities:type_name -> transit.v1.TripUpdateEntityProto
+	20, // 0: transit.v1.AlertEntityProto.alert:type_name -> transit.v1.AlertProto
+	21, // 1: transit.v1.AlertProto.active_period:type_name -> transit.v1.ActivePeriodProto
+	22, // 2: transit.v1.AlertProto.informed_entity:type_name -> transit.v1.InformedEntityProto
+	23, // 3: transit.v1.AlertProto.header_text:type_name -> transit.v1.TranslatedStringProto
+	23, // 4: transit.v1.AlertProto.description_text:type_name -> transit.v1.TranslatedStringProto
+	24, // 5: transit.v1.TranslatedStringProto.translation:type_name -> transit.v1.TranslationProto
+	26, // 6: transit.v1.TripUpdateEntityProto.trip_update:type_name -> transit.v1.TripUpdateProto
+	27, // 7: transit.v1.TripUpdateProto.trip:type_name -> transit.v1.TripDescriptorProto
+	28, // 8: transit.v1.TripUpdateProto.vehicle:type_name -> transit.v1.VehicleDescriptorProto
+	29, // 9: transit.v1.TripUpdateProto.stop_time_update:type_name -> transit.v1.StopTimeUpdateProto
+	30, // 10: transit.v1.StopTimeUpdateProto.arrival:type_name -> transit.v1.StopTimeEventProto
+	30, // 11: transit.v1.StopTimeUpdateProto.departure:type_name -> transit.v1.StopTimeEventProto
+	32, // 12: transit.v1.VehiclePositionEntityProto.vehicle:type_name -> transit.v1.VehiclePositionProto
+	27, // 13: transit.v1.VehiclePositionProto.trip:type_name -> transit.v1.TripDescriptorProto
+	28, // 14: transit.v1.VehiclePositionProto.vehicle:type_name -> transit.v1.VehicleDescriptorProto
+	33, // 15: transit.v1.VehiclePositionProto.position:type_name -> transit.v1.GeoPositionProto
+	31, // 16: transit.v1.VehiclePositionCollection.entities:type_name -> transit.v1.VehiclePositionEntityProto
+	19, // 17: transit.v1.AlertCollection.entities:type_name -> transit.v1.AlertEntityProto
+	25, // 18: transit.v1.TripUpdateCollection.entities:type_name -> transit.v1.TripUpdateEntityProto
 	19, // [19:19] is the sub-list for method output_type
 	19, // [19:19] is the sub-list for method input_type
 	19, // [19:19] is the sub-list for extension type_name
@@ -1834,7 +3095,7 @@ func file_transit_proto_init() {
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_transit_proto_rawDesc), len(file_transit_proto_rawDesc)),
 			NumEnums:      0,
-			NumMessages:   23,
+			NumMessages:   37,
 			NumExtensions: 0,
 			NumServices:   0,
 		},
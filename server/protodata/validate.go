@@ -0,0 +1,668 @@
+package protodata
+
+import (
+	"cmp"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"math"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	NoticeError   = "error"
+	NoticeWarning = "warning"
+	NoticeInfo    = "info"
+)
+
+const (
+	// maxNoticesPerCode caps the notices listed per code; Counts still
+	// covers every occurrence
+	maxNoticesPerCode = 50
+	// stops further than this from their trip's shape are probably misplaced
+	maxStopShapeDistanceMeters = 100.0
+)
+
+const validationReportFile = "validation_report.json"
+
+// ValidationNotice is one problem found in the static feed. Line is the
+// line of the input file, counting the header as line 1.
+type ValidationNotice struct {
+	Severity string `json:"severity"`
+	Code     string `json:"code"`
+	File     string `json:"file"`
+	Line     int    `json:"line,omitempty"`
+	Field    string `json:"field,omitempty"`
+	Value    string `json:"value,omitempty"`
+	Message  string `json:"message"`
+}
+
+type NoticeCount struct {
+	Code     string `json:"code"`
+	Severity string `json:"severity"`
+	Count    int    `json:"count"`
+}
+
+type StaticValidationReport struct {
+	ValidatedAt time.Time          `json:"validated_at"`
+	Errors      int                `json:"errors"`
+	Warnings    int                `json:"warnings"`
+	Infos       int                `json:"infos"`
+	Counts      []NoticeCount      `json:"counts"`
+	Notices     []ValidationNotice `json:"notices"`
+}
+
+type feedValidator struct {
+	report StaticValidationReport
+	counts map[string]*NoticeCount
+	today  string
+}
+
+func (v *feedValidator) add(n ValidationNotice) {
+	count, found := v.counts[n.Code]
+	if !found {
+		count = &NoticeCount{Code: n.Code, Severity: n.Severity}
+		v.counts[n.Code] = count
+	}
+	count.Count++
+	switch n.Severity {
+	case NoticeError:
+		v.report.Errors++
+	case NoticeWarning:
+		v.report.Warnings++
+	default:
+		v.report.Infos++
+	}
+	if count.Count <= maxNoticesPerCode {
+		v.report.Notices = append(v.report.Notices, n)
+	}
+}
+
+// rowNotice reports a problem with one field of a row.
+func (v *feedValidator) rowNotice(severity, code string, t *csvTable, i int, field, message string) {
+	v.add(ValidationNotice{
+		Severity: severity,
+		Code:     code,
+		File:     t.file,
+		Line:     t.lines[i],
+		Field:    field,
+		Value:    t.value(i, field),
+		Message:  message,
+	})
+}
+
+// readTable reads an input file, reporting the rows the generators would
+// silently skip. It returns nil when the file isn't in the feed.
+func (v *feedValidator) readTable(fileName string) *csvTable {
+	file, err := os.Open(inputUrl + fileName)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		v.add(ValidationNotice{Severity: NoticeError, Code: "unreadable_file", File: fileName, Message: err.Error()})
+		return nil
+	}
+	defer file.Close()
+
+	t, err := readCSVTable(fileName, file, func(line int, err error) {
+		v.add(ValidationNotice{Severity: NoticeError, Code: "malformed_row", File: fileName, Line: line, Message: err.Error()})
+	})
+	if err != nil {
+		v.add(ValidationNotice{Severity: NoticeError, Code: "missing_header", File: fileName, Line: 1, Message: "file has no header row"})
+		return nil
+	}
+	return t
+}
+
+// required reports rows leaving any of the columns empty.
+func (v *feedValidator) required(t *csvTable, columns ...string) {
+	if t == nil {
+		return
+	}
+	for _, column := range columns {
+		if _, found := t.columns[column]; !found {
+			v.add(ValidationNotice{Severity: NoticeError, Code: "missing_required_column", File: t.file, Line: 1, Field: column, Message: column + " is required"})
+			continue
+		}
+		for i := range t.rows {
+			if t.value(i, column) == "" {
+				v.rowNotice(NoticeError, "missing_required_field", t, i, column, column+" is empty")
+			}
+		}
+	}
+}
+
+// uniqueIds reports rows whose key repeats an earlier row's and returns the
+// row each key was first seen on. Rows without a key are left to the
+// required field checks.
+func (v *feedValidator) uniqueIds(t *csvTable, keyColumns ...string) map[string]int {
+	ids := make(map[string]int)
+	if t == nil {
+		return ids
+	}
+	for i := range t.rows {
+		parts := make([]string, len(keyColumns))
+		for k, c := range keyColumns {
+			parts[k] = t.value(i, c)
+		}
+		key := strings.Join(parts, "|")
+		if parts[0] == "" {
+			continue
+		}
+		if first, found := ids[key]; found {
+			v.rowNotice(NoticeError, "duplicate_key", t, i, keyColumns[len(keyColumns)-1],
+				fmt.Sprintf("%s %s already appears on line %d", strings.Join(keyColumns, ","), key, t.lines[first]))
+			continue
+		}
+		ids[key] = i
+	}
+	return ids
+}
+
+// references reports values of a column that the file they refer to does
+// not have. Empty values are left to the required field checks.
+func (v *feedValidator) references(t *csvTable, column, target string, ids map[string]int) {
+	if t == nil {
+		return
+	}
+	for i := range t.rows {
+		value := t.value(i, column)
+		if value == "" {
+			continue
+		}
+		if _, found := ids[value]; !found {
+			v.rowNotice(NoticeError, "foreign_key_violation", t, i, column, fmt.Sprintf("%s %s is not in %s", column, value, target))
+		}
+	}
+}
+
+// numbers reports values of a column that aren't numbers in [lo, hi].
+func (v *feedValidator) numbers(t *csvTable, column string, lo, hi float64, required bool) {
+	if t == nil {
+		return
+	}
+	for i := range t.rows {
+		s := t.value(i, column)
+		if s == "" {
+			if required {
+				v.rowNotice(NoticeError, "missing_required_field", t, i, column, column+" is empty")
+			}
+			continue
+		}
+		f, err := strconv.ParseFloat(s, 64)
+		if err != nil {
+			v.rowNotice(NoticeError, "invalid_number", t, i, column, column+" is not a number")
+		} else if f < lo || f > hi {
+			v.rowNotice(NoticeError, "number_out_of_range", t, i, column, fmt.Sprintf("%s must be between %g and %g", column, lo, hi))
+		}
+	}
+}
+
+// dates reports values of a column that aren't YYYYMMDD dates.
+func (v *feedValidator) dates(t *csvTable, column string, required bool) {
+	if t == nil {
+		return
+	}
+	for i := range t.rows {
+		s := t.value(i, column)
+		if s == "" {
+			if required {
+				v.rowNotice(NoticeError, "missing_required_field", t, i, column, column+" is empty")
+			}
+			continue
+		}
+		if _, err := time.Parse("20060102", s); err != nil {
+			v.rowNotice(NoticeError, "invalid_date", t, i, column, column+" is not a YYYYMMDD date")
+		}
+	}
+}
+
+// gtfsTimeSeconds reads an H:MM:SS time, which may run past 24:00:00.
+func gtfsTimeSeconds(s string) (int, bool) {
+	parts := strings.Split(s, ":")
+	if len(parts) != 3 || len(parts[1]) != 2 || len(parts[2]) != 2 {
+		return 0, false
+	}
+	var hms [3]int
+	for i, p := range parts {
+		n, err := strconv.Atoi(p)
+		if err != nil || n < 0 {
+			return 0, false
+		}
+		hms[i] = n
+	}
+	if hms[1] > 59 || hms[2] > 59 {
+		return 0, false
+	}
+	return hms[0]*3600 + hms[1]*60 + hms[2], true
+}
+
+type shapePoint struct {
+	lat, lon float64
+}
+
+// metersToPolyline is the distance from a point to the nearest segment of a
+// polyline, on a flat projection around the point; shapes are local enough
+// for that to be accurate.
+func metersToPolyline(lat, lon float64, points []shapePoint) float64 {
+	const metersPerDegree = 111320.0
+	kx := metersPerDegree * math.Cos(lat*math.Pi/180)
+	best := math.Inf(1)
+	for i := range points {
+		ax, ay := (points[i].lon-lon)*kx, (points[i].lat-lat)*metersPerDegree
+		if i == 0 {
+			best = math.Hypot(ax, ay)
+			continue
+		}
+		bx, by := (points[i-1].lon-lon)*kx, (points[i-1].lat-lat)*metersPerDegree
+		dx, dy := ax-bx, ay-by
+		t := 0.0
+		if l := dx*dx + dy*dy; l > 0 {
+			t = math.Max(0, math.Min(1, -(bx*dx+by*dy)/l))
+		}
+		best = math.Min(best, math.Hypot(bx+t*dx, by+t*dy))
+	}
+	return best
+}
+
+// ValidateFeed checks the input files before they are generated: that the
+// files reference each other consistently, that every value the generators
+// parse is parseable, and that the feed is internally plausible.
+func ValidateFeed() *StaticValidationReport {
+	v := &feedValidator{
+		report: StaticValidationReport{ValidatedAt: time.Now(), Notices: make([]ValidationNotice, 0)},
+		counts: make(map[string]*NoticeCount),
+		today:  time.Now().Format("20060102"),
+	}
+
+	agency := v.readTable("agency.txt")
+	routes := v.readTable("routes.txt")
+	trips := v.readTable("trips.txt")
+	stops := v.readTable("stops.txt")
+	stopTimes := v.readTable("stop_times.txt")
+	shapes := v.readTable("shapes.txt")
+	calendar := v.readTable("calendar.txt")
+	calendarDates := v.readTable("calendar_dates.txt")
+	feedInfo := v.readTable("feed_info.txt")
+
+	for file, t := range map[string]*csvTable{"agency.txt": agency, "routes.txt": routes, "trips.txt": trips, "stops.txt": stops, "stop_times.txt": stopTimes} {
+		if t == nil {
+			v.add(ValidationNotice{Severity: NoticeError, Code: "missing_required_file", File: file, Message: file + " is required"})
+		}
+	}
+	if calendar == nil && calendarDates == nil {
+		v.add(ValidationNotice{Severity: NoticeError, Code: "missing_required_file", File: "calendar.txt", Message: "calendar.txt or calendar_dates.txt is required"})
+	}
+
+	v.required(agency, "agency_name", "agency_url", "agency_timezone")
+	v.required(routes, "route_id")
+	v.required(trips, "route_id", "service_id", "trip_id")
+	v.required(stops, "stop_id")
+	v.required(stopTimes, "trip_id", "stop_id")
+	v.required(shapes, "shape_id")
+	v.required(calendar, "service_id")
+	v.required(calendarDates, "service_id")
+
+	agencyIds := v.uniqueIds(agency, "agency_id")
+	routeIds := v.uniqueIds(routes, "route_id")
+	tripIds := v.uniqueIds(trips, "trip_id")
+	stopIds := v.uniqueIds(stops, "stop_id")
+	v.uniqueIds(stopTimes, "trip_id", "stop_sequence")
+	v.uniqueIds(shapes, "shape_id", "shape_pt_sequence")
+	serviceIds := v.uniqueIds(calendar, "service_id")
+	v.uniqueIds(calendarDates, "service_id", "date")
+	if calendarDates != nil {
+		for i := range calendarDates.rows {
+			if id := calendarDates.value(i, "service_id"); id != "" {
+				if _, found := serviceIds[id]; !found {
+					serviceIds[id] = -1
+				}
+			}
+		}
+	}
+	shapeIds := make(map[string]int)
+	if shapes != nil {
+		for i := range shapes.rows {
+			shapeIds[shapes.value(i, "shape_id")] = i
+		}
+	}
+
+	if len(agencyIds) > 0 {
+		v.references(routes, "agency_id", "agency.txt", agencyIds)
+	}
+	v.references(trips, "route_id", "routes.txt", routeIds)
+	v.references(trips, "service_id", "calendar.txt or calendar_dates.txt", serviceIds)
+	if shapes != nil {
+		v.references(trips, "shape_id", "shapes.txt", shapeIds)
+	}
+	v.references(stopTimes, "trip_id", "trips.txt", tripIds)
+	v.references(stopTimes, "stop_id", "stops.txt", stopIds)
+	v.references(stops, "parent_station", "stops.txt", stopIds)
+
+	v.numbers(routes, "route_type", 0, 12, true)
+	v.numbers(trips, "direction_id", 0, 1, false)
+	v.numbers(stops, "stop_lat", -90, 90, false)
+	v.numbers(stops, "stop_lon", -180, 180, false)
+	v.numbers(stops, "location_type", 0, 4, false)
+	v.numbers(stopTimes, "stop_sequence", 0, math.MaxInt32, true)
+	v.numbers(stopTimes, "shape_dist_traveled", 0, math.Inf(1), false)
+	v.numbers(shapes, "shape_pt_lat", -90, 90, true)
+	v.numbers(shapes, "shape_pt_lon", -180, 180, true)
+	v.numbers(shapes, "shape_pt_sequence", 0, math.MaxInt32, true)
+	v.numbers(shapes, "shape_dist_traveled", 0, math.Inf(1), false)
+	for _, day := range []string{"monday", "tuesday", "wednesday", "thursday", "friday", "saturday", "sunday"} {
+		v.numbers(calendar, day, 0, 1, true)
+	}
+	v.numbers(calendarDates, "exception_type", 1, 2, true)
+	v.dates(calendar, "start_date", true)
+	v.dates(calendar, "end_date", true)
+	v.dates(calendarDates, "date", true)
+	v.dates(feedInfo, "feed_start_date", false)
+	v.dates(feedInfo, "feed_end_date", false)
+
+	polylines := v.checkShapes(shapes)
+	byTrip := v.checkStopTimes(stopTimes)
+	v.checkStopsNearShapes(trips, stops, stopTimes, byTrip, polylines)
+	v.checkUnused(routes, trips, stops, stopTimes, shapes, calendar, byTrip)
+	v.checkDateRanges(feedInfo, calendar, calendarDates)
+
+	for _, c := range v.counts {
+		v.report.Counts = append(v.report.Counts, *c)
+	}
+	severity := map[string]int{NoticeError: 0, NoticeWarning: 1, NoticeInfo: 2}
+	slices.SortFunc(v.report.Counts, func(a, b NoticeCount) int {
+		if c := cmp.Compare(severity[a.Severity], severity[b.Severity]); c != 0 {
+			return c
+		}
+		return cmp.Compare(a.Code, b.Code)
+	})
+	if v.report.Counts == nil {
+		v.report.Counts = make([]NoticeCount, 0)
+	}
+	slices.SortStableFunc(v.report.Notices, func(a, b ValidationNotice) int {
+		if c := cmp.Compare(severity[a.Severity], severity[b.Severity]); c != 0 {
+			return c
+		}
+		if c := cmp.Compare(a.File, b.File); c != 0 {
+			return c
+		}
+		return cmp.Compare(a.Line, b.Line)
+	})
+	return &v.report
+}
+
+// checkShapes reports shape_dist_traveled going backwards along a shape and
+// returns each shape's points in order.
+func (v *feedValidator) checkShapes(shapes *csvTable) map[string][]shapePoint {
+	polylines := make(map[string][]shapePoint)
+	if shapes == nil {
+		return polylines
+	}
+	rows := make(map[string][]int)
+	for i := range shapes.rows {
+		id := shapes.value(i, "shape_id")
+		rows[id] = append(rows[id], i)
+	}
+	for id, idx := range rows {
+		seq := func(i int) int {
+			n, _ := strconv.Atoi(shapes.value(i, "shape_pt_sequence"))
+			return n
+		}
+		slices.SortFunc(idx, func(a, b int) int { return cmp.Compare(seq(a), seq(b)) })
+
+		prevDist := -1.0
+		points := make([]shapePoint, 0, len(idx))
+		for _, i := range idx {
+			lat, latErr := strconv.ParseFloat(shapes.value(i, "shape_pt_lat"), 64)
+			lon, lonErr := strconv.ParseFloat(shapes.value(i, "shape_pt_lon"), 64)
+			if latErr == nil && lonErr == nil {
+				points = append(points, shapePoint{lat, lon})
+			}
+			dist, err := strconv.ParseFloat(shapes.value(i, "shape_dist_traveled"), 64)
+			if err != nil {
+				continue
+			}
+			if dist < prevDist {
+				v.rowNotice(NoticeError, "decreasing_shape_distance", shapes, i, "shape_dist_traveled",
+					fmt.Sprintf("shape %s goes back from %g to %g", id, prevDist, dist))
+			}
+			prevDist = max(prevDist, dist)
+		}
+		polylines[id] = points
+	}
+	return polylines
+}
+
+// checkStopTimes reports unparseable times, times running backwards and
+// shape_dist_traveled going backwards along a trip, and returns each trip's
+// rows in stop_sequence order.
+func (v *feedValidator) checkStopTimes(stopTimes *csvTable) map[string][]int {
+	byTrip := make(map[string][]int)
+	if stopTimes == nil {
+		return byTrip
+	}
+	seqs := make([]int, len(stopTimes.rows))
+	for i := range stopTimes.rows {
+		seqs[i], _ = strconv.Atoi(stopTimes.value(i, "stop_sequence"))
+		id := stopTimes.value(i, "trip_id")
+		byTrip[id] = append(byTrip[id], i)
+	}
+	for _, idx := range byTrip {
+		slices.SortFunc(idx, func(a, b int) int { return cmp.Compare(seqs[a], seqs[b]) })
+
+		prevTime, prevDist := -1, -1.0
+		for k, i := range idx {
+			for _, field := range []string{"arrival_time", "departure_time"} {
+				s := stopTimes.value(i, field)
+				if s == "" {
+					// times may only be left out between timepoints
+					if k == 0 || k == len(idx)-1 {
+						v.rowNotice(NoticeError, "missing_required_field", stopTimes, i, field, "the first and last stop of a trip need times")
+					}
+					continue
+				}
+				t, ok := gtfsTimeSeconds(s)
+				if !ok {
+					v.rowNotice(NoticeError, "invalid_time", stopTimes, i, field, field+" is not an H:MM:SS time")
+					continue
+				}
+				if t < prevTime {
+					v.rowNotice(NoticeError, "decreasing_time", stopTimes, i, field, field+" is before the previous stop's time")
+				}
+				prevTime = max(prevTime, t)
+			}
+
+			dist, err := strconv.ParseFloat(stopTimes.value(i, "shape_dist_traveled"), 64)
+			if err != nil {
+				continue
+			}
+			if dist < prevDist {
+				v.rowNotice(NoticeError, "decreasing_shape_distance", stopTimes, i, "shape_dist_traveled",
+					fmt.Sprintf("goes back from %g to %g", prevDist, dist))
+			}
+			prevDist = max(prevDist, dist)
+		}
+	}
+	return byTrip
+}
+
+// checkStopsNearShapes reports stops further than
+// maxStopShapeDistanceMeters from the shape of a trip serving them, once per
+// stop and shape.
+func (v *feedValidator) checkStopsNearShapes(trips, stops, stopTimes *csvTable, byTrip map[string][]int, polylines map[string][]shapePoint) {
+	if trips == nil || stops == nil || stopTimes == nil || len(polylines) == 0 {
+		return
+	}
+	stopRows := make(map[string]int, len(stops.rows))
+	for i := range stops.rows {
+		stopRows[stops.value(i, "stop_id")] = i
+	}
+	checked := make(map[[2]string]bool)
+	for t := range trips.rows {
+		shapeId := trips.value(t, "shape_id")
+		points := polylines[shapeId]
+		if len(points) == 0 {
+			continue
+		}
+		for _, i := range byTrip[trips.value(t, "trip_id")] {
+			stopId := stopTimes.value(i, "stop_id")
+			key := [2]string{shapeId, stopId}
+			if checked[key] {
+				continue
+			}
+			checked[key] = true
+			s, found := stopRows[stopId]
+			if !found {
+				continue
+			}
+			lat, latErr := strconv.ParseFloat(stops.value(s, "stop_lat"), 64)
+			lon, lonErr := strconv.ParseFloat(stops.value(s, "stop_lon"), 64)
+			if latErr != nil || lonErr != nil {
+				continue
+			}
+			if d := metersToPolyline(lat, lon, points); d > maxStopShapeDistanceMeters {
+				v.rowNotice(NoticeWarning, "stop_too_far_from_shape", stopTimes, i, "stop_id",
+					fmt.Sprintf("stop %s is %.0fm from shape %s", stopId, d, shapeId))
+			}
+		}
+	}
+}
+
+// checkUnused reports entities nothing refers to.
+func (v *feedValidator) checkUnused(routes, trips, stops, stopTimes, shapes, calendar *csvTable, byTrip map[string][]int) {
+	if trips == nil {
+		return
+	}
+	usedRoutes, usedShapes, usedServices := make(map[string]bool), make(map[string]bool), make(map[string]bool)
+	for i := range trips.rows {
+		usedRoutes[trips.value(i, "route_id")] = true
+		usedShapes[trips.value(i, "shape_id")] = true
+		usedServices[trips.value(i, "service_id")] = true
+		if stopTimes != nil && len(byTrip[trips.value(i, "trip_id")]) == 0 {
+			v.rowNotice(NoticeWarning, "trip_without_stop_times", trips, i, "trip_id", "trip has no stop times")
+		}
+	}
+	if routes != nil {
+		for i := range routes.rows {
+			if !usedRoutes[routes.value(i, "route_id")] {
+				v.rowNotice(NoticeWarning, "unused_route", routes, i, "route_id", "no trip runs on this route")
+			}
+		}
+	}
+	if shapes != nil {
+		seen := make(map[string]bool)
+		for i := range shapes.rows {
+			id := shapes.value(i, "shape_id")
+			if !usedShapes[id] && !seen[id] {
+				v.rowNotice(NoticeWarning, "unused_shape", shapes, i, "shape_id", "no trip follows this shape")
+			}
+			seen[id] = true
+		}
+	}
+	if calendar != nil {
+		for i := range calendar.rows {
+			if !usedServices[calendar.value(i, "service_id")] {
+				v.rowNotice(NoticeInfo, "unused_service", calendar, i, "service_id", "no trip runs on this service")
+			}
+		}
+	}
+	if stops != nil && stopTimes != nil {
+		served, parents := make(map[string]bool), make(map[string]bool)
+		for i := range stopTimes.rows {
+			served[stopTimes.value(i, "stop_id")] = true
+		}
+		for i := range stops.rows {
+			parents[stops.value(i, "parent_station")] = true
+		}
+		for i := range stops.rows {
+			id := stops.value(i, "stop_id")
+			switch stops.value(i, "location_type") {
+			case "", "0":
+				if !served[id] {
+					v.rowNotice(NoticeInfo, "unused_stop", stops, i, "stop_id", "no trip serves this stop")
+				}
+			case "1":
+				if !parents[id] {
+					v.rowNotice(NoticeWarning, "unused_station", stops, i, "stop_id", "no stop belongs to this station")
+				}
+			}
+		}
+	}
+}
+
+// checkDateRanges compares the service calendar with the dates feed_info
+// says the feed is valid for, and with today.
+func (v *feedValidator) checkDateRanges(feedInfo, calendar, calendarDates *csvTable) {
+	if calendar != nil {
+		for i := range calendar.rows {
+			if start, end := calendar.value(i, "start_date"), calendar.value(i, "end_date"); start > end {
+				v.rowNotice(NoticeError, "start_after_end", calendar, i, "end_date", fmt.Sprintf("service ends %s before it starts %s", end, start))
+			}
+		}
+	}
+	if feedInfo == nil || len(feedInfo.rows) == 0 {
+		v.add(ValidationNotice{Severity: NoticeInfo, Code: "missing_feed_info", File: "feed_info.txt", Message: "feed_info.txt would say which dates the feed covers"})
+		return
+	}
+
+	start, end := feedInfo.value(0, "feed_start_date"), feedInfo.value(0, "feed_end_date")
+	if start != "" && end != "" && start > end {
+		v.rowNotice(NoticeError, "start_after_end", feedInfo, 0, "feed_end_date", fmt.Sprintf("feed ends %s before it starts %s", end, start))
+	}
+	if end != "" && end < v.today {
+		v.rowNotice(NoticeWarning, "feed_expired", feedInfo, 0, "feed_end_date", "the feed's end date has passed")
+	}
+	if start != "" && start > v.today {
+		v.rowNotice(NoticeInfo, "feed_not_yet_valid", feedInfo, 0, "feed_start_date", "the feed's start date is in the future")
+	}
+
+	outside := func(date string) bool {
+		return start != "" && date < start || end != "" && date > end
+	}
+	if calendar != nil {
+		for i := range calendar.rows {
+			for _, field := range []string{"start_date", "end_date"} {
+				if date := calendar.value(i, field); date != "" && outside(date) {
+					v.rowNotice(NoticeWarning, "service_outside_feed_dates", calendar, i, field,
+						fmt.Sprintf("%s %s is outside the feed's %s-%s", field, date, start, end))
+				}
+			}
+		}
+	}
+	if calendarDates != nil {
+		for i := range calendarDates.rows {
+			if date := calendarDates.value(i, "date"); date != "" && outside(date) {
+				v.rowNotice(NoticeWarning, "service_outside_feed_dates", calendarDates, i, "date",
+					fmt.Sprintf("date %s is outside the feed's %s-%s", date, start, end))
+			}
+		}
+	}
+}
+
+// WriteValidationReport saves a report next to the generated data, so the
+// server can serve the report for the data it was built with.
+func WriteValidationReport(report *StaticValidationReport) error {
+	data, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(outputUrl+validationReportFile, data, 0o644)
+}
+
+// ReadValidationReport loads the report saved with the generated data.
+func ReadValidationReport() (*StaticValidationReport, error) {
+	data, err := os.ReadFile(outputUrl + validationReportFile)
+	if err != nil {
+		return nil, err
+	}
+	var report StaticValidationReport
+	if err := json.Unmarshal(data, &report); err != nil {
+		return nil, err
+	}
+	return &report, nil
+}
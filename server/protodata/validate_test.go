@@ -0,0 +1,138 @@
+package protodata
+
+import (
+	"maps"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// validFeed is the smallest feed the validator finds no errors in: one
+// route with one trip between two stops.
+var validFeed = map[string]string{
+	"agency.txt": "agency_id,agency_name,agency_url,agency_timezone\n" +
+		"A,Agency,https://example.com,America/Denver\n",
+	"routes.txt": "route_id,agency_id,route_type\n" +
+		"R1,A,3\n",
+	"trips.txt": "route_id,service_id,trip_id\n" +
+		"R1,WK,T1\n",
+	"stops.txt": "stop_id,stop_lat,stop_lon\n" +
+		"S1,39.74,-104.99\n" +
+		"S2,39.75,-104.99\n",
+	"stop_times.txt": "trip_id,arrival_time,departure_time,stop_id,stop_sequence\n" +
+		"T1,08:00:00,08:00:00,S1,1\n" +
+		"T1,08:10:00,08:10:00,S2,2\n",
+	"calendar.txt": "service_id,monday,tuesday,wednesday,thursday,friday,saturday,sunday,start_date,end_date\n" +
+		"WK,1,1,1,1,1,0,0,20260101,20261231\n",
+}
+
+// writeFeed writes files to a temporary directory and returns it. An empty
+// file content leaves the file out.
+func writeFeed(t *testing.T, files map[string]string) string {
+	t.Helper()
+	dir := t.TempDir()
+	for name, content := range files {
+		if content == "" {
+			continue
+		}
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+// useFeed reads the input from files for the length of a test.
+func useFeed(t *testing.T, files map[string]string) {
+	t.Helper()
+	dir := writeFeed(t, files)
+	prev := inputUrl
+	t.Cleanup(func() { inputUrl = prev })
+	if _, err := UseInput(dir); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestValidateFeedNotices(t *testing.T) {
+	type notice struct {
+		code, file string
+		line       int
+	}
+	tests := []struct {
+		name    string
+		changes map[string]string
+		// notices that must be reported; none means no errors at all
+		want []notice
+	}{
+		{"valid feed", nil, nil},
+		{
+			"missing required file",
+			map[string]string{"stops.txt": ""},
+			[]notice{{"missing_required_file", "stops.txt", 0}},
+		},
+		{
+			"malformed row",
+			map[string]string{"stops.txt": "stop_id,stop_lat,stop_lon\nS1,39.74,-104.99\nS2,39.75,-104.99\nS3,39.76\n"},
+			[]notice{{"malformed_row", "stops.txt", 4}},
+		},
+		{
+			"no header row",
+			map[string]string{"routes.txt": "\n"},
+			[]notice{{"missing_header", "routes.txt", 1}, {"foreign_key_violation", "trips.txt", 2}},
+		},
+		{
+			"missing required column",
+			map[string]string{"trips.txt": "route_id,trip_id\nR1,T1\n"},
+			[]notice{{"missing_required_column", "trips.txt", 1}},
+		},
+		{
+			"missing required field",
+			map[string]string{"trips.txt": "route_id,service_id,trip_id\nR1,,T1\n"},
+			[]notice{{"missing_required_field", "trips.txt", 2}},
+		},
+		{
+			"duplicate key",
+			map[string]string{"stops.txt": "stop_id,stop_lat,stop_lon\nS1,39.74,-104.99\nS1,39.75,-104.99\n"},
+			[]notice{{"duplicate_key", "stops.txt", 3}, {"foreign_key_violation", "stop_times.txt", 3}},
+		},
+		{
+			"invalid number",
+			map[string]string{"routes.txt": "route_id,agency_id,route_type\nR1,A,bus\n"},
+			[]notice{{"invalid_number", "routes.txt", 2}},
+		},
+		{
+			"times running backwards",
+			map[string]string{"stop_times.txt": "trip_id,arrival_time,departure_time,stop_id,stop_sequence\nT1,08:00:00,08:00:00,S1,1\nT1,07:50:00,07:50:00,S2,2\n"},
+			[]notice{{"decreasing_time", "stop_times.txt", 3}},
+		},
+		{
+			"service ending before it starts",
+			map[string]string{"calendar.txt": "service_id,monday,tuesday,wednesday,thursday,friday,saturday,sunday,start_date,end_date\nWK,1,1,1,1,1,0,0,20261231,20260101\n"},
+			[]notice{{"start_after_end", "calendar.txt", 2}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			files := maps.Clone(validFeed)
+			maps.Copy(files, tt.changes)
+			useFeed(t, files)
+
+			report := ValidateFeed()
+			if tt.want == nil {
+				if report.Errors != 0 {
+					t.Errorf("got %d errors, want none: %+v", report.Errors, report.Notices)
+				}
+				return
+			}
+			got := make(map[notice]bool)
+			for _, n := range report.Notices {
+				got[notice{n.Code, n.File, n.Line}] = true
+			}
+			for _, want := range tt.want {
+				if !got[want] {
+					t.Errorf("no %s notice for %s line %d in %+v", want.code, want.file, want.line, report.Notices)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,96 @@
+package server
+
+import (
+	"slices"
+	"studious-waffle/server/protodata"
+)
+
+// StopDetail is a stop with the routes that call at it.
+type StopDetail struct {
+	Stop      *protodata.StopProto `json:"stop"`
+	Station   *protodata.StopProto `json:"station,omitempty"`
+	Routes    []string             `json:"routes"`
+	StopTimes int                  `json:"stop_times"`
+}
+
+// RouteDetail is a route with how much service the dataset has for it.
+type RouteDetail struct {
+	Route     *protodata.RouteProto `json:"route"`
+	Trips     int                   `json:"trips"`
+	Headsigns []string              `json:"headsigns"`
+	Shapes    []string              `json:"shapes"`
+}
+
+// TripDetail is a trip with its route and stop times.
+type TripDetail struct {
+	Trip      *protodata.TripProto       `json:"trip"`
+	Route     *protodata.RouteProto      `json:"route,omitempty"`
+	StopTimes []*protodata.StopTimeProto `json:"stop_times"`
+	StopNames map[string]string          `json:"stop_names"`
+}
+
+// LookupStop finds a stop in the compiled data.
+func LookupStop(stopId string) (*StopDetail, bool) {
+	stop, found := findStopById(stopId)
+	if !found {
+		return nil, false
+	}
+	detail := &StopDetail{Stop: stop, Routes: make([]string, 0)}
+	if station := stationForStop(stop); station != stop {
+		detail.Station = station
+	}
+	stopTimes, _ := findStopTimesByStopID(stopId)
+	detail.StopTimes = len(stopTimes)
+	for _, st := range stopTimes {
+		if trip, found := findTripByID(st.GetTripId()); found && !slices.Contains(detail.Routes, trip.GetRouteId()) {
+			detail.Routes = append(detail.Routes, trip.GetRouteId())
+		}
+	}
+	slices.SortFunc(detail.Routes, naturalCompare)
+	return detail, true
+}
+
+// LookupRoute finds a route in the compiled data.
+func LookupRoute(routeId string) (*RouteDetail, bool) {
+	route, found := findRouteByID(routeId)
+	if !found {
+		return nil, false
+	}
+	detail := &RouteDetail{Route: routeWithNetwork(route), Headsigns: make([]string, 0), Shapes: make([]string, 0)}
+	for _, t := range Trips {
+		if t.GetRouteId() != routeId {
+			continue
+		}
+		detail.Trips++
+		if h := t.GetTripHeadsign(); h != "" && !slices.Contains(detail.Headsigns, h) {
+			detail.Headsigns = append(detail.Headsigns, h)
+		}
+		if s := t.GetShapeId(); s != "" && !slices.Contains(detail.Shapes, s) {
+			detail.Shapes = append(detail.Shapes, s)
+		}
+	}
+	slices.Sort(detail.Headsigns)
+	slices.SortFunc(detail.Shapes, naturalCompare)
+	return detail, true
+}
+
+// LookupTrip finds a trip in the compiled data.
+func LookupTrip(tripId string) (*TripDetail, bool) {
+	trip, found := findTripByID(tripId)
+	if !found {
+		return nil, false
+	}
+	detail := &TripDetail{Trip: trip, StopTimes: make([]*protodata.StopTimeProto, 0), StopNames: make(map[string]string)}
+	if route, found := findRouteByID(trip.GetRouteId()); found {
+		detail.Route = route
+	}
+	if stopTimes, found := findStopTimesByTripID(tripId); found {
+		detail.StopTimes = stopTimes
+	}
+	for _, st := range detail.StopTimes {
+		if stop, found := findStopById(st.GetStopId()); found {
+			detail.StopNames[stop.GetStopId()] = stop.GetStopName()
+		}
+	}
+	return detail, true
+}
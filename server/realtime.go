@@ -0,0 +1,127 @@
+package server
+
+import (
+	"context"
+	"log"
+	"studious-waffle/server/protodata"
+	"sync"
+	"time"
+)
+
+// RealtimeSnapshot is one poll of the realtime feeds. AlertsUnavailable is
+// set when the alerts feed has failed since startup, leaving Alerts empty
+// rather than known to have none.
+type RealtimeSnapshot struct {
+	Vehicles          []*protodata.VehiclePositionEntityProto
+	TripUpdates       []*protodata.TripUpdateEntityProto
+	Alerts            []*protodata.AlertEntityProto
+	AlertsUnavailable bool
+	FetchedAt         time.Time
+}
+
+// GTFS-realtime schedule_relationship values, as the realtime protos carry
+// them.
+const (
+	TripAdded       = 1
+	TripUnscheduled = 2
+	TripCanceled    = 3
+	TripDeleted     = 7
+
+	StopTimeSkipped = 1
+	StopTimeNoData  = 2
+)
+
+// realtimeCache keeps the latest snapshot so handlers don't hit RTD on every
+// request, and lets other components react to each new poll.
+type realtimeCache struct {
+	mu        sync.RWMutex
+	latest    *RealtimeSnapshot
+	listeners []func(*RealtimeSnapshot)
+}
+
+var realtime = &realtimeCache{}
+
+// OnRealtimeUpdate registers fn to run after every successful poll. Listeners
+// run in registration order on the polling goroutine.
+func OnRealtimeUpdate(fn func(*RealtimeSnapshot)) {
+	realtime.mu.Lock()
+	defer realtime.mu.Unlock()
+	realtime.listeners = append(realtime.listeners, fn)
+}
+
+func fetchRealtimeSnapshot() (*RealtimeSnapshot, error) {
+	vehicles, err := FetchVehiclePositions()
+	if err != nil {
+		return nil, err
+	}
+	updates, err := FetchTripUpdates()
+	if err != nil {
+		return nil, err
+	}
+	snap := &RealtimeSnapshot{Vehicles: vehicles, TripUpdates: updates, FetchedAt: time.Now()}
+	snap.Alerts, err = FetchAlerts()
+	if err != nil {
+		// alerts alone failing shouldn't cost the vehicles and trip updates
+		log.Println("Error fetching alerts, keeping the previous ones:", err)
+		realtime.mu.RLock()
+		if realtime.latest != nil {
+			snap.Alerts, snap.AlertsUnavailable = realtime.latest.Alerts, realtime.latest.AlertsUnavailable
+		} else {
+			snap.AlertsUnavailable = true
+		}
+		realtime.mu.RUnlock()
+	}
+	return snap, nil
+}
+
+// PollRealtime refreshes the cache every interval until ctx is cancelled.
+func PollRealtime(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		snap, err := fetchRealtimeSnapshot()
+		if err != nil {
+			log.Println("Error polling realtime feeds:", err)
+		} else {
+			realtime.mu.Lock()
+			realtime.latest = snap
+			listeners := realtime.listeners
+			realtime.mu.Unlock()
+
+			for _, fn := range listeners {
+				fn(snap)
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// currentRealtime returns the cached snapshot while it is fresh and fetches
+// the feeds directly when polling is off or has fallen behind.
+func currentRealtime() (*RealtimeSnapshot, error) {
+	realtime.mu.RLock()
+	snap := realtime.latest
+	realtime.mu.RUnlock()
+
+	if snap != nil && time.Since(snap.FetchedAt) < config.realtimeMaxAge() {
+		return snap, nil
+	}
+	return fetchRealtimeSnapshot()
+}
+
+// startRealtime starts polling in the background, every configured poll
+// interval, and wires up the components that learn from each poll.
+func startRealtime() {
+	OnRealtimeUpdate(predictor.Observe)
+	OnRealtimeUpdate(plannerCache.Observe)
+	startOTP()
+	startSpacing()
+	startGhosts()
+	go PollRealtime(context.Background(), config.Feeds.PollInterval)
+}
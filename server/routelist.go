@@ -0,0 +1,217 @@
+package server
+
+import (
+	"fmt"
+	"math"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"studious-waffle/server/protodata"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	defaultRouteLimit = 50
+	maxRouteLimit     = 500
+)
+
+// routeTypeNames are the names accepted for ?route_type= besides the GTFS
+// numbers themselves.
+var routeTypeNames = map[string]int32{
+	"tram":          0,
+	"light_rail":    0,
+	"subway":        1,
+	"metro":         1,
+	"rail":          2,
+	"commuter_rail": 2,
+	"bus":           3,
+	"ferry":         4,
+}
+
+// RouteQuery is a parsed GET /routes request. Empty fields match everything.
+type RouteQuery struct {
+	RouteTypes []int32
+	AgencyId   string
+	NetworkId  string
+	Text       string
+	Sort       string
+	Limit      int
+	Offset     int
+}
+
+type RouteListResponse struct {
+	Total  int                     `json:"total"`
+	Offset int                     `json:"offset"`
+	Limit  int                     `json:"limit"`
+	Routes []*protodata.RouteProto `json:"routes"`
+}
+
+func parseRouteTypes(s string) ([]int32, error) {
+	var types []int32
+	for _, part := range strings.Split(s, ",") {
+		part = strings.ToLower(strings.TrimSpace(part))
+		if t, ok := routeTypeNames[part]; ok {
+			types = append(types, t)
+			continue
+		}
+		n, err := strconv.Atoi(part)
+		if err != nil {
+			return nil, fmt.Errorf("unknown route_type %q", part)
+		}
+		types = append(types, int32(n))
+	}
+	return types, nil
+}
+
+func queryInt(c *gin.Context, name string, def, min, max int) (int, error) {
+	s := c.Query(name)
+	if s == "" {
+		return def, nil
+	}
+	n, err := strconv.Atoi(s)
+	if err != nil || n < min || n > max {
+		return 0, fmt.Errorf("%s must be an integer between %d and %d", name, min, max)
+	}
+	return n, nil
+}
+
+func routeQueryFromQuery(c *gin.Context) (RouteQuery, error) {
+	q := RouteQuery{
+		AgencyId:  c.Query("agency_id"),
+		NetworkId: c.Query("network_id"),
+		Text:      strings.ToLower(strings.TrimSpace(c.Query("q"))),
+		Sort:      c.DefaultQuery("sort", "sort_order"),
+	}
+	if q.Sort != "sort_order" && q.Sort != "short_name" {
+		return q, fmt.Errorf("sort must be sort_order or short_name")
+	}
+	if s := c.Query("route_type"); s != "" {
+		types, err := parseRouteTypes(s)
+		if err != nil {
+			return q, err
+		}
+		q.RouteTypes = types
+	}
+
+	var err error
+	if q.Limit, err = queryInt(c, "limit", defaultRouteLimit, 1, maxRouteLimit); err != nil {
+		return q, err
+	}
+	if q.Offset, err = queryInt(c, "offset", 0, 0, math.MaxInt32); err != nil {
+		return q, err
+	}
+	return q, nil
+}
+
+func (q RouteQuery) Match(r *protodata.RouteProto) bool {
+	if len(q.RouteTypes) > 0 && !slices.Contains(q.RouteTypes, r.GetRouteType()) {
+		return false
+	}
+	if q.AgencyId != "" && r.GetAgencyId() != q.AgencyId {
+		return false
+	}
+	if q.NetworkId != "" && r.GetNetworkId() != q.NetworkId {
+		return false
+	}
+	if q.Text != "" {
+		text := strings.ToLower(r.GetRouteShortName() + "\n" + r.GetRouteLongName() + "\n" + r.GetRouteDesc())
+		if !strings.Contains(text, q.Text) {
+			return false
+		}
+	}
+	return true
+}
+
+// naturalCompare orders strings with embedded numbers the way riders read
+// them: "0", "0B", "1", "10" rather than "0", "1", "10", "0B".
+func naturalCompare(a, b string) int {
+	for a != "" && b != "" {
+		ra, rb := leadingRun(a), leadingRun(b)
+		na, errA := strconv.Atoi(ra)
+		nb, errB := strconv.Atoi(rb)
+		switch {
+		case errA == nil && errB == nil:
+			if na != nb {
+				return na - nb
+			}
+		case errA == nil:
+			return -1
+		case errB == nil:
+			return 1
+		default:
+			if c := strings.Compare(strings.ToLower(ra), strings.ToLower(rb)); c != 0 {
+				return c
+			}
+		}
+		a, b = a[len(ra):], b[len(rb):]
+	}
+	return len(a) - len(b)
+}
+
+// leadingRun returns the leading run of digits, or of non-digits, of s.
+func leadingRun(s string) string {
+	isDigit := func(c byte) bool { return c >= '0' && c <= '9' }
+	d := isDigit(s[0])
+	i := 1
+	for i < len(s) && isDigit(s[i]) == d {
+		i++
+	}
+	return s[:i]
+}
+
+func compareRouteNames(a, b *protodata.RouteProto) int {
+	if c := naturalCompare(a.GetRouteShortName(), b.GetRouteShortName()); c != 0 {
+		return c
+	}
+	return naturalCompare(a.GetRouteId(), b.GetRouteId())
+}
+
+// compareRouteSortOrder follows route_sort_order, putting routes without one
+// after those with one, and falls back to natural short-name order.
+func compareRouteSortOrder(a, b *protodata.RouteProto) int {
+	switch {
+	case a.RouteSortOrder != nil && b.RouteSortOrder != nil:
+		if c := int(a.GetRouteSortOrder()) - int(b.GetRouteSortOrder()); c != 0 {
+			return c
+		}
+	case a.RouteSortOrder != nil:
+		return -1
+	case b.RouteSortOrder != nil:
+		return 1
+	}
+	return compareRouteNames(a, b)
+}
+
+// GET /routes?route_type=&agency_id=&network_id=&q=&sort=&limit=&offset=
+func HandleRoutes(c *gin.Context) {
+	q, err := routeQueryFromQuery(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	matched := make([]*protodata.RouteProto, 0)
+	for _, r := range Routes {
+		r = routeWithNetwork(r)
+		if q.Match(r) {
+			matched = append(matched, r)
+		}
+	}
+
+	if q.Sort == "short_name" {
+		slices.SortStableFunc(matched, compareRouteNames)
+	} else {
+		slices.SortStableFunc(matched, compareRouteSortOrder)
+	}
+
+	page := matched[min(q.Offset, len(matched)):]
+	page = page[:min(q.Limit, len(page))]
+	c.JSON(http.StatusOK, RouteListResponse{
+		Total:  len(matched),
+		Offset: q.Offset,
+		Limit:  q.Limit,
+		Routes: page,
+	})
+}
@@ -8,13 +8,40 @@ func AddGTFSRoutes(r *gin.Engine) {
 		gtfsGroup.GET("/alerts", HandleAlert)
 		gtfsGroup.GET("/tripupdates", HandleTripUpdate)
 		gtfsGroup.GET("/vehiclepositions", HandleVehiclePosition)
+		gtfsGroup.GET("/predictions", HandlePredictions)
+		gtfsGroup.GET("/predictions/trip/:trip_id", HandlePredictionsByTripId)
+		gtfsGroup.GET("/rt/tripupdates", HandleRTTripUpdates)
+		gtfsGroup.GET("/rt/vehiclepositions", HandleRTVehiclePositions)
+		gtfsGroup.GET("/rt/alerts", HandleRTAlerts)
+		gtfsGroup.GET("/vehicles", HandleVehicles)
+		gtfsGroup.GET("/vehicles/:id", HandleVehicleById)
+		gtfsGroup.GET("/vehicles/:id/progress", HandleVehicleProgress)
+		gtfsGroup.GET("/spacing", HandleSpacing)
+		gtfsGroup.GET("/spacing/events", HandleSpacingEvents)
+		gtfsGroup.GET("/ghosts", HandleGhosts)
+		gtfsGroup.GET("/validation/realtime", HandleRealtimeValidation)
+		gtfsGroup.GET("/validation/static", HandleStaticValidation)
+		gtfsGroup.GET("/routes", HandleRoutes)
 		gtfsGroup.GET("/routes/:id", HandleRoutesById)
+		gtfsGroup.GET("/routes/:id/timetable", HandleRouteTimetable)
+		gtfsGroup.GET("/stops/search", HandleStopSearch)
+		gtfsGroup.GET("/stations/:id", HandleStationById)
+		gtfsGroup.GET("/stations/:id/path", HandleStationPath)
 		gtfsGroup.GET("/stops/:id", HandleStopsById)
+		gtfsGroup.GET("/stops/:id/transfers", HandleStopTransfers)
 		gtfsGroup.GET("/trips/:id", HandleTripsById)
 		gtfsGroup.GET("/shapes/:id", HandleShapesById)
 		gtfsGroup.GET("/routes/lat/:lat/lon/:lon/radius/:radius", HandleNearRoutes)
 		gtfsGroup.GET("/stops/near/:lat/:lon", HandleNearStops)
 		gtfsGroup.GET("/stoptimes/trip/:trip_id", HandleStopTimesByTripId)
 		gtfsGroup.GET("/stoptimes/trip/:trip_id/stop/:stop_id", HandleStopTimesByIds)
+		gtfsGroup.GET("/frequencies/trip/:trip_id", HandleFrequenciesByTripId)
+		gtfsGroup.POST("/fares/quote", HandleFareQuote)
+		gtfsGroup.GET("/plan", HandlePlan)
+		gtfsGroup.GET("/isochrone", HandleIsochrone)
+		gtfsGroup.GET("/networks", HandleNetworks)
+		gtfsGroup.GET("/networks/:id/routes", HandleNetworkRoutes)
+		gtfsGroup.GET("/analytics/routes/:id/headways", HandleRouteHeadways)
+		gtfsGroup.GET("/analytics/otp", HandleOTP)
 	}
 }
@@ -0,0 +1,252 @@
+package server
+
+import (
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/MobilityData/gtfs-realtime-bindings/golang/gtfs"
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	// feeds should be regenerated at least this often to be worth serving
+	maxRealtimeFeedAge = 65 * time.Second
+	// clocks drift; a header this far ahead of ours is wrong rather than early
+	maxRealtimeClockSkew = 60 * time.Second
+	// vehicles may stray this far outside the stops' bounding box, in
+	// degrees, before they are outside the service area
+	serviceAreaMargin = 0.05
+)
+
+// realtimeFeedName names a feed by the configured URL it was fetched from.
+func realtimeFeedName(url string) string {
+	switch url {
+	case config.Feeds.AlertsURL:
+		return "alerts"
+	case config.Feeds.TripUpdatesURL:
+		return "trip_updates"
+	case config.Feeds.VehiclePositionsURL:
+		return "vehicle_positions"
+	}
+	return url
+}
+
+// serviceArea is the bounding box of every stop, widened by
+// serviceAreaMargin.
+var serviceArea = sync.OnceValue(func() *BBox {
+	if len(Stops) == 0 {
+		return nil
+	}
+	b := &BBox{MinLon: 180, MinLat: 90, MaxLon: -180, MaxLat: -90}
+	for _, s := range Stops {
+		if s.GetStopLat() == 0 && s.GetStopLon() == 0 {
+			continue
+		}
+		b.MinLat, b.MaxLat = min(b.MinLat, s.GetStopLat()), max(b.MaxLat, s.GetStopLat())
+		b.MinLon, b.MaxLon = min(b.MinLon, s.GetStopLon()), max(b.MaxLon, s.GetStopLon())
+	}
+	b.MinLat, b.MinLon = b.MinLat-serviceAreaMargin, b.MinLon-serviceAreaMargin
+	b.MaxLat, b.MaxLon = b.MaxLat+serviceAreaMargin, b.MaxLon+serviceAreaMargin
+	return b
+})
+
+// RealtimeValidation is the result of checking one fetch of a feed.
+type RealtimeValidation struct {
+	Feed            string            `json:"feed"`
+	URL             string            `json:"url"`
+	ValidatedAt     time.Time         `json:"validated_at"`
+	HeaderTimestamp int64             `json:"header_timestamp"`
+	AgeSeconds      int64             `json:"age_seconds"`
+	Entities        int               `json:"entities"`
+	Errors          int               `json:"errors"`
+	Warnings        int               `json:"warnings"`
+	Issues          []ValidationIssue `json:"issues"`
+}
+
+// RealtimeValidator checks every realtime feed fetched and keeps the latest
+// result for each.
+type RealtimeValidator struct {
+	mu     sync.Mutex
+	latest map[string]RealtimeValidation
+}
+
+var realtimeValidator = &RealtimeValidator{latest: make(map[string]RealtimeValidation)}
+
+func (v *RealtimeValidator) Validate(url string, feed *gtfs.FeedMessage, at time.Time) {
+	result := validateFeedMessage(url, feed, at)
+	v.mu.Lock()
+	v.latest[result.Feed] = result
+	v.mu.Unlock()
+}
+
+// validateFeedMessage runs the GTFS-realtime best-practice checks that
+// matter to riders against one feed message.
+func validateFeedMessage(url string, feed *gtfs.FeedMessage, at time.Time) RealtimeValidation {
+	result := RealtimeValidation{Feed: realtimeFeedName(url), URL: url, ValidatedAt: at, Entities: len(feed.GetEntity())}
+	var issues validationIssues
+
+	header := feed.GetHeader()
+	if header.GetGtfsRealtimeVersion() == "" {
+		issues.add("missing_gtfs_realtime_version", SeverityError, "header has no gtfs_realtime_version")
+	}
+	if ts := int64(header.GetTimestamp()); ts == 0 {
+		issues.add("missing_header_timestamp", SeverityError, "header has no timestamp")
+	} else {
+		result.HeaderTimestamp = ts
+		age := at.Sub(time.Unix(ts, 0))
+		result.AgeSeconds = int64(age.Seconds())
+		switch {
+		case age > maxRealtimeFeedAge:
+			issues.add("stale_feed", SeverityWarning, fmt.Sprintf("header timestamp is %s old", age.Round(time.Second)))
+		case -age > maxRealtimeClockSkew:
+			issues.add("header_timestamp_in_future", SeverityError, fmt.Sprintf("header timestamp is %s ahead", (-age).Round(time.Second)))
+		}
+	}
+
+	ids := make(map[string]bool)
+	for _, e := range feed.GetEntity() {
+		id := e.GetId()
+		if id == "" {
+			issues.add("missing_entity_id", SeverityError, "entity without an id")
+		} else if ids[id] {
+			issues.add("duplicate_entity_id", SeverityError, fmt.Sprintf("entity %s appears more than once", id))
+		}
+		ids[id] = true
+
+		if tu := e.GetTripUpdate(); tu != nil {
+			validateTripDescriptor(&issues, id, tu.GetTrip())
+			validateStopTimeUpdates(&issues, id, tu.GetStopTimeUpdate())
+		}
+		if v := e.GetVehicle(); v != nil {
+			if v.GetTrip() != nil {
+				validateTripDescriptor(&issues, id, v.GetTrip())
+			}
+			validateStopId(&issues, id, v.GetStopId())
+			validatePosition(&issues, id, v.GetPosition())
+		}
+		if a := e.GetAlert(); a != nil {
+			for _, ie := range a.GetInformedEntity() {
+				if ie.GetTrip() != nil {
+					validateTripDescriptor(&issues, id, ie.GetTrip())
+				}
+				validateRouteId(&issues, id, ie.GetRouteId())
+				validateStopId(&issues, id, ie.GetStopId())
+			}
+		}
+	}
+
+	result.Issues, result.Errors, result.Warnings = issues.list()
+	return result
+}
+
+// validateTripDescriptor checks a trip's IDs against the static feed. Added
+// and unscheduled trips aren't in it by definition.
+func validateTripDescriptor(issues *validationIssues, entityId string, td *gtfs.TripDescriptor) {
+	switch td.GetScheduleRelationship() {
+	case gtfs.TripDescriptor_ADDED, gtfs.TripDescriptor_UNSCHEDULED:
+	default:
+		if tripId := td.GetTripId(); tripId == "" {
+			if td.GetRouteId() == "" {
+				issues.add("missing_trip_id", SeverityError, fmt.Sprintf("entity %s: trip has neither trip_id nor route_id", entityId))
+			}
+		} else if _, found := findTripByID(tripId); !found {
+			issues.add("unknown_trip_id", SeverityError, fmt.Sprintf("entity %s: trip_id %s is not in trips.txt", entityId, tripId))
+		}
+	}
+	validateRouteId(issues, entityId, td.GetRouteId())
+}
+
+func validateRouteId(issues *validationIssues, entityId, routeId string) {
+	if routeId == "" {
+		return
+	}
+	if _, found := findRouteByID(routeId); !found {
+		issues.add("unknown_route_id", SeverityError, fmt.Sprintf("entity %s: route_id %s is not in routes.txt", entityId, routeId))
+	}
+}
+
+func validateStopId(issues *validationIssues, entityId, stopId string) {
+	if stopId == "" {
+		return
+	}
+	if _, found := findStopById(stopId); !found {
+		issues.add("unknown_stop_id", SeverityError, fmt.Sprintf("entity %s: stop_id %s is not in stops.txt", entityId, stopId))
+	}
+}
+
+// validateStopTimeUpdates checks that a trip's updates run in stop order and
+// that the predicted times never go backwards. Skipped stops and stops
+// without data carry no times.
+func validateStopTimeUpdates(issues *validationIssues, entityId string, updates []*gtfs.TripUpdate_StopTimeUpdate) {
+	var prevSeq uint32
+	var prevTime int64
+	hasSeq := false
+	for _, stu := range updates {
+		validateStopId(issues, entityId, stu.GetStopId())
+		if stu.StopSequence != nil {
+			seq := stu.GetStopSequence()
+			if hasSeq && seq <= prevSeq {
+				issues.add("stop_sequence_not_increasing", SeverityError, fmt.Sprintf("entity %s: stop_sequence %d follows %d", entityId, seq, prevSeq))
+			}
+			prevSeq, hasSeq = seq, true
+		}
+
+		switch stu.GetScheduleRelationship() {
+		case gtfs.TripUpdate_StopTimeUpdate_SKIPPED, gtfs.TripUpdate_StopTimeUpdate_NO_DATA:
+			continue
+		}
+		arr, dep := stu.GetArrival().GetTime(), stu.GetDeparture().GetTime()
+		if arr != 0 && dep != 0 && dep < arr {
+			issues.add("departure_before_arrival", SeverityError, fmt.Sprintf("entity %s: stop %s departs %ds before it arrives", entityId, stu.GetStopId(), arr-dep))
+		}
+		t := arr
+		if t == 0 {
+			t = dep
+		}
+		if t == 0 {
+			continue
+		}
+		if t < prevTime {
+			issues.add("times_decreasing", SeverityError, fmt.Sprintf("entity %s: stop %s is predicted %ds before the stop ahead of it", entityId, stu.GetStopId(), prevTime-t))
+		}
+		prevTime = max(prevTime, arr, dep)
+	}
+}
+
+func validatePosition(issues *validationIssues, entityId string, p *gtfs.Position) {
+	if p == nil {
+		issues.add("missing_position", SeverityWarning, fmt.Sprintf("entity %s: vehicle has no position", entityId))
+		return
+	}
+	lat, lon := float64(p.GetLatitude()), float64(p.GetLongitude())
+	switch {
+	case lat < -90 || lat > 90 || lon < -180 || lon > 180 || lat == 0 && lon == 0:
+		issues.add("invalid_coordinates", SeverityError, fmt.Sprintf("entity %s: position %.5f,%.5f", entityId, lat, lon))
+	case serviceArea() != nil && !serviceArea().Contains(lat, lon):
+		issues.add("outside_service_area", SeverityWarning, fmt.Sprintf("entity %s: position %.5f,%.5f", entityId, lat, lon))
+	}
+}
+
+// GET /validation/realtime?feed=trip_updates
+func HandleRealtimeValidation(c *gin.Context) {
+	feed := c.Query("feed")
+	realtimeValidator.mu.Lock()
+	defer realtimeValidator.mu.Unlock()
+
+	results := make([]RealtimeValidation, 0, len(realtimeValidator.latest))
+	for _, name := range []string{"trip_updates", "vehicle_positions", "alerts"} {
+		if feed != "" && feed != name {
+			continue
+		}
+		if r, found := realtimeValidator.latest[name]; found {
+			results = append(results, r)
+		}
+	}
+	if feed != "" && len(results) == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "No validation results for feed " + feed})
+		return
+	}
+	c.JSON(http.StatusOK, results)
+}
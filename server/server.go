@@ -3,97 +3,204 @@ package server
 import (
 	"fmt"
 	"log"
-	"os"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
 	"studious-waffle/server/protodata"
 	"sync"
 
 	"github.com/gin-contrib/cors"
-	"github.com/gin-contrib/logger"
 	"github.com/gin-gonic/gin"
 )
 
 var baseUrl = "http://localhost:"
 
-func ServeGin() {
-
-	port := os.Getenv("PORT")
-	if port == "" {
-		port = "8080"
+// Seed validates the static feed and, when it has no errors, regenerates
+// the compiled data from it, failing if any generator does. The server has
+// to be rebuilt to serve it.
+func Seed() error {
+	fmt.Println("Validating static GTFS...")
+	report := protodata.ValidateFeed()
+	if err := protodata.WriteValidationReport(report); err != nil {
+		log.Println("Error saving validation report:", err)
 	}
-
-	seed := os.Getenv("SEED_DATA")
-	if seed == "" {
-		log.Println("WARNING: SEED_DATA is not set.")
+	staticValidation = report
+	if report.Errors > 0 {
+		for _, n := range report.Notices {
+			if n.Severity == protodata.NoticeError {
+				log.Printf("%s:%d %s: %s\n", n.File, n.Line, n.Code, n.Message)
+			}
+		}
+		return fmt.Errorf("static GTFS has %d validation error(s), not seeding", report.Errors)
+	}
+	fmt.Printf("Static GTFS validated with %d warning(s).\n", report.Warnings)
+
+	// generators report failure by returning false
+	var mu sync.Mutex
+	var failed []string
+	fail := func(name string) {
+		mu.Lock()
+		failed = append(failed, name)
+		mu.Unlock()
 	}
 
-	if seed == "true" {
-		var wg sync.WaitGroup
-		wg.Add(5)
+	var wg sync.WaitGroup
+	wg.Add(9)
+
+	go func() {
+		fmt.Println("Generating Routes...")
+		haveData := protodata.GenerateRouteData()
+		if haveData {
+			fmt.Println("Finished generating Routes data.")
+		} else {
+			fail("Routes")
+		}
+		wg.Done()
+	}()
+
+	go func() {
+		fmt.Println("Generating Trips...")
+		haveData := protodata.GenerateTripData()
+		if haveData {
+			fmt.Println("Finished generating Trips data.")
+		} else {
+			fail("Trips")
+		}
+		wg.Done()
+	}()
+
+	go func() {
+		fmt.Println("Generating Stops...")
+		haveData := protodata.GenerateStopData()
+		if haveData {
+			fmt.Println("Finished generating Stops data.")
+		} else {
+			fail("Stops")
+		}
+		wg.Done()
+	}()
+
+	go func() {
+		fmt.Println("Generating Stop Times...")
+		haveData := protodata.GenerateStopTimeData()
+		if haveData {
+			fmt.Println("Finished generating Stop Times data.")
+		} else {
+			fail("Stop Times")
+		}
+		wg.Done()
+	}()
+
+	go func() {
+		fmt.Println("Generating Shapes...")
+		haveData := protodata.GenerateShapeData()
+		if haveData {
+			fmt.Println("Finished generating Shapes data.")
+		} else {
+			fail("Shapes")
+		}
+		wg.Done()
+	}()
+
+	go func() {
+		fmt.Println("Generating Fares...")
+		haveData := protodata.GenerateFareData()
+		if haveData {
+			fmt.Println("Finished generating Fares data.")
+		} else {
+			fail("Fares")
+		}
+		wg.Done()
+	}()
+
+	go func() {
+		fmt.Println("Generating Pathways...")
+		haveData := protodata.GeneratePathwayData()
+		if haveData {
+			fmt.Println("Finished generating Pathways data.")
+		} else {
+			fail("Pathways")
+		}
+		wg.Done()
+	}()
+
+	go func() {
+		fmt.Println("Generating Transfers...")
+		haveData := protodata.GenerateTransferData()
+		if haveData {
+			fmt.Println("Finished generating Transfers data.")
+		} else {
+			fail("Transfers")
+		}
+		wg.Done()
+	}()
+
+	go func() {
+		fmt.Println("Generating Calendar...")
+		haveData := protodata.GenerateCalendarData()
+		if haveData {
+			fmt.Println("Finished generating Calendar data.")
+		} else {
+			fail("Calendar")
+		}
+		wg.Done()
+	}()
+
+	wg.Wait()
+	if len(failed) > 0 {
+		slices.Sort(failed)
+		return fmt.Errorf("generating %s data failed", strings.Join(failed, ", "))
+	}
+	fmt.Println("Server seeded with data.")
+	return nil
+}
 
-		go func() {
-			fmt.Println("Generating Routes...")
-			haveData := protodata.GenerateRouteData()
-			if haveData {
-				fmt.Println("Finished generating Routes data.")
-			}
-			wg.Done()
-		}()
-
-		go func() {
-			fmt.Println("Generating Trips...")
-			haveData := protodata.GenerateTripData()
-			if haveData {
-				fmt.Println("Finished generating Trips data.")
-			}
-			wg.Done()
-		}()
-
-		go func() {
-			fmt.Println("Generating Stops...")
-			haveData := protodata.GenerateStopData()
-			if haveData {
-				fmt.Println("Finished generating Stops data.")
-			}
-			wg.Done()
-		}()
-
-		go func() {
-			fmt.Println("Generating Stop Times...")
-			haveData := protodata.GenerateStopTimeData()
-			if haveData {
-				fmt.Println("Finished generating Stop Times data.")
-			}
-			wg.Done()
-		}()
-
-		go func() {
-			fmt.Println("Generating Shapes...")
-			haveData := protodata.GenerateShapeData()
-			if haveData {
-				fmt.Println("Finished generating Shapes data.")
-			}
-			wg.Done()
-		}()
+// Serve starts the realtime components and serves the API with the
+// configured settings until the server stops.
+func Serve() error {
+	if staticValidation == nil {
+		if report, err := protodata.ReadValidationReport(); err == nil {
+			staticValidation = report
+		}
+	}
 
-		wg.Wait()
-		fmt.Println("Server seeded with data.")
+	// webhooks first, so components started with realtime can publish
+	if len(config.Webhooks.URLs) > 0 {
+		startAlertWebhooks()
 	}
 
+	startRealtime()
+
 	gin.SetMode(gin.ReleaseMode)
-	r := gin.Default()
+	r := gin.New()
+	r.Use(gin.Recovery())
 	r.SetTrustedProxies(nil)
 
-	r.Use(logger.SetLogger())
+	if config.Logging.AccessLog {
+		r.Use(AccessLogger())
+	}
 	r.Use(RateLimiter())
-	config := cors.DefaultConfig()
-	config.AllowOrigins = []string{"http://localhost:8080"}
-	r.Use(cors.New(config))
+	corsConfig := cors.DefaultConfig()
+	if slices.Contains(config.CORS.AllowOrigins, "*") {
+		corsConfig.AllowAllOrigins = true
+	} else {
+		corsConfig.AllowOrigins = config.CORS.AllowOrigins
+	}
+	r.Use(cors.New(corsConfig))
 
 	addBaseRoutes(r)
 	AddGTFSRoutes(r)
 
+	port := strconv.Itoa(config.Server.Port)
+	srv := &http.Server{
+		Addr:         ":" + port,
+		Handler:      r,
+		ReadTimeout:  config.Server.ReadTimeout,
+		WriteTimeout: config.Server.WriteTimeout,
+	}
 	log.Printf("Serving Gin at %s\n", baseUrl+port)
-	r.Run()
+	return srv.ListenAndServe()
 }
 
 func addBaseRoutes(r *gin.Engine) {
@@ -102,4 +209,8 @@ func addBaseRoutes(r *gin.Engine) {
 			"message": "Hello World!",
 		})
 	})
+
+	admin := r.Group("/admin", AdminOnly())
+	admin.GET("/config", HandleAdminConfig)
+	admin.POST("/feeds/diff", HandleFeedDiff)
 }
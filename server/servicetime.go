@@ -0,0 +1,107 @@
+package server
+
+import (
+	"fmt"
+	"log"
+	"studious-waffle/server/protodata"
+	"time"
+	_ "time/tzdata"
+)
+
+// agencyTimezone is agency_timezone from agency.txt. Schedule times are
+// relative to service days in this zone.
+const agencyTimezone = "America/Denver"
+
+var agencyLocation = loadAgencyLocation()
+
+func loadAgencyLocation() *time.Location {
+	loc, err := time.LoadLocation(agencyTimezone)
+	if err != nil {
+		log.Println("Error loading agency timezone, falling back to UTC:", err)
+		return time.UTC
+	}
+	return loc
+}
+
+// parseGTFSTime reads an "HH:MM:SS" schedule time as seconds since the start
+// of the service day. Hours may exceed 23 for trips past midnight.
+func parseGTFSTime(s string) (int, bool) {
+	var h, m, sec int
+	if _, err := fmt.Sscanf(s, "%d:%d:%d", &h, &m, &sec); err != nil {
+		return 0, false
+	}
+	if h < 0 || m < 0 || m > 59 || sec < 0 || sec > 59 {
+		return 0, false
+	}
+	return h*3600 + m*60 + sec, true
+}
+
+// formatGTFSTime is the inverse of parseGTFSTime.
+func formatGTFSTime(secs int) string {
+	return fmt.Sprintf("%02d:%02d:%02d", secs/3600, secs/60%60, secs%60)
+}
+
+// serviceDayStart is the reference point schedule times count from: noon
+// minus twelve hours, which differs from midnight on DST change days.
+func serviceDayStart(day time.Time) time.Time {
+	y, m, d := day.In(agencyLocation).Date()
+	return time.Date(y, m, d, 12, 0, 0, 0, agencyLocation).Add(-12 * time.Hour)
+}
+
+// stopTimeSeconds returns the scheduled arrival and departure of a stop time,
+// each falling back to the other when only one is published.
+func stopTimeSeconds(st *protodata.StopTimeProto) (int, int, bool) {
+	arr, okA := parseGTFSTime(st.GetArrivalTime())
+	dep, okD := parseGTFSTime(st.GetDepartureTime())
+	switch {
+	case okA && okD:
+		return arr, dep, true
+	case okA:
+		return arr, arr, true
+	case okD:
+		return dep, dep, true
+	}
+	return 0, 0, false
+}
+
+// resolveServiceDay picks the service day a trip observed at "at" belongs
+// to. A trip scheduled 23:30-25:10 seen at 00:40 belongs to yesterday, so
+// yesterday, today and tomorrow are tried and the one whose scheduled span
+// is closest to the observation wins.
+func resolveServiceDay(stopTimes []*protodata.StopTimeProto, at time.Time) time.Time {
+	today := serviceDayStart(at)
+	if len(stopTimes) == 0 {
+		return today
+	}
+	_, first, ok1 := stopTimeSeconds(stopTimes[0])
+	last, _, ok2 := stopTimeSeconds(stopTimes[len(stopTimes)-1])
+	if !ok1 || !ok2 {
+		return today
+	}
+
+	best, bestGap := today, time.Duration(1<<62)
+	for _, offset := range []int{-1, 0, 1} {
+		day := serviceDayStart(today.AddDate(0, 0, offset).Add(12 * time.Hour))
+		start := day.Add(time.Duration(first) * time.Second)
+		end := day.Add(time.Duration(last) * time.Second)
+
+		var gap time.Duration
+		switch {
+		case at.Before(start):
+			gap = start.Sub(at)
+		case at.After(end):
+			gap = at.Sub(end)
+		}
+		if gap < bestGap {
+			best, bestGap = day, gap
+		}
+	}
+	return best
+}
+
+// serviceDate formats a service day start as a GTFS date (YYYYMMDD). Noon is
+// used rather than the start itself, which falls on the previous calendar
+// day when clocks spring forward.
+func serviceDate(dayStart time.Time) string {
+	return dayStart.Add(12 * time.Hour).In(agencyLocation).Format("20060102")
+}
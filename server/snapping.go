@@ -0,0 +1,364 @@
+package server
+
+import (
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"studious-waffle/server/protodata"
+	"sync"
+
+	"github.com/gin-gonic/gin"
+)
+
+// offRouteThresholdMeters is how far a vehicle may sit from its trip's shape
+// before it is flagged as off route.
+const offRouteThresholdMeters = 100.0
+
+// shapeLine is a shape polyline with the cumulative distance in meters at
+// each point, plus the shape_dist_traveled values from the feed so stop
+// distances published in feed units can be placed on it.
+type shapeLine struct {
+	shapeId  string
+	lats     []float64
+	lons     []float64
+	cum      []float64
+	feedDist []float64
+	useFeed  bool
+}
+
+func newShapeLine(points []*protodata.ShapeProto) *shapeLine {
+	l := &shapeLine{
+		lats:     make([]float64, len(points)),
+		lons:     make([]float64, len(points)),
+		cum:      make([]float64, len(points)),
+		feedDist: make([]float64, len(points)),
+		useFeed:  len(points) > 1,
+	}
+	for i, p := range points {
+		l.shapeId = p.GetShapeId()
+		l.lats[i] = p.GetShapePtLat()
+		l.lons[i] = p.GetShapePtLon()
+		l.feedDist[i] = p.GetShapeDistTraveled()
+		if i > 0 {
+			l.cum[i] = l.cum[i-1] + haversineMeters(l.lats[i-1], l.lons[i-1], l.lats[i], l.lons[i])
+			if l.feedDist[i] < l.feedDist[i-1] {
+				l.useFeed = false
+			}
+		}
+	}
+	if n := len(points); n == 0 || l.feedDist[n-1] <= 0 {
+		l.useFeed = false
+	}
+	return l
+}
+
+func (l *shapeLine) Length() float64 {
+	if len(l.cum) == 0 {
+		return 0
+	}
+	return l.cum[len(l.cum)-1]
+}
+
+// project snaps a coordinate onto the part of the line between from and to
+// meters. It returns the distance along the line, the perpendicular offset
+// and the snapped coordinate.
+func (l *shapeLine) project(lat, lon, from, to float64) (along, offset, snapLat, snapLon float64) {
+	plane := newLocalPlane(lat, lon)
+	offset = math.Inf(1)
+	for i := 0; i+1 < len(l.lats); i++ {
+		if l.cum[i+1] < from || l.cum[i] > to {
+			continue
+		}
+		ax, ay := plane.xy(l.lats[i], l.lons[i])
+		bx, by := plane.xy(l.lats[i+1], l.lons[i+1])
+		t, d := projectOnSegment(0, 0, ax, ay, bx, by)
+		if d < offset {
+			offset = d
+			along = l.cum[i] + t*(l.cum[i+1]-l.cum[i])
+			snapLat, snapLon = plane.latLon(ax+t*(bx-ax), ay+t*(by-ay))
+		}
+	}
+	if len(l.lats) == 1 {
+		offset = haversineMeters(lat, lon, l.lats[0], l.lons[0])
+		snapLat, snapLon = l.lats[0], l.lons[0]
+	}
+	return along, offset, snapLat, snapLon
+}
+
+// feedToMeters converts a shape_dist_traveled value in feed units to meters
+// along the line by interpolating between the shape points around it.
+func (l *shapeLine) feedToMeters(v float64) float64 {
+	n := len(l.feedDist)
+	if v <= l.feedDist[0] {
+		return 0
+	}
+	for i := 1; i < n; i++ {
+		if v <= l.feedDist[i] {
+			span := l.feedDist[i] - l.feedDist[i-1]
+			if span <= 0 {
+				return l.cum[i]
+			}
+			return l.cum[i-1] + (v-l.feedDist[i-1])/span*(l.cum[i]-l.cum[i-1])
+		}
+	}
+	return l.cum[n-1]
+}
+
+// tripStop is a scheduled stop placed on its trip's shape.
+type tripStop struct {
+	StopTime *protodata.StopTimeProto
+	Along    float64
+}
+
+// tripGeometry is everything needed to reason about a vehicle's position on
+// a trip. It depends only on static data, so it is built once per trip.
+type tripGeometry struct {
+	trip  *protodata.TripProto
+	line  *shapeLine
+	stops []tripStop
+}
+
+var (
+	geometryMu     sync.RWMutex
+	shapeLines     = make(map[string]*shapeLine)
+	tripGeometries = make(map[string]*tripGeometry)
+)
+
+func getShapeLine(shapeId string) (*shapeLine, bool) {
+	geometryMu.RLock()
+	l, ok := shapeLines[shapeId]
+	geometryMu.RUnlock()
+	if ok {
+		return l, true
+	}
+
+	points, found := findShapeById(shapeId)
+	if !found {
+		return nil, false
+	}
+	l = newShapeLine(points)
+
+	geometryMu.Lock()
+	shapeLines[shapeId] = l
+	geometryMu.Unlock()
+	return l, true
+}
+
+func getTripGeometry(tripId string) (*tripGeometry, error) {
+	geometryMu.RLock()
+	g, ok := tripGeometries[tripId]
+	geometryMu.RUnlock()
+	if ok {
+		return g, nil
+	}
+
+	trip, found := findTripByID(tripId)
+	if !found {
+		return nil, fmt.Errorf("trip %s not found", tripId)
+	}
+	line, found := getShapeLine(trip.GetShapeId())
+	if !found {
+		return nil, fmt.Errorf("shape %s for trip %s not found", trip.GetShapeId(), tripId)
+	}
+	stopTimes, found := findStopTimesByTripID(tripId)
+	if !found {
+		return nil, fmt.Errorf("no stop times for trip %s", tripId)
+	}
+
+	g = &tripGeometry{trip: trip, line: line, stops: placeStops(line, stopTimes)}
+
+	geometryMu.Lock()
+	tripGeometries[tripId] = g
+	geometryMu.Unlock()
+	return g, nil
+}
+
+// placeStops positions each stop along the shape. Published
+// shape_dist_traveled values are used when the trip and shape both have
+// them; otherwise stops are projected onto the line in sequence so a loop
+// route cannot place a later stop behind an earlier one.
+func placeStops(line *shapeLine, stopTimes []*protodata.StopTimeProto) []tripStop {
+	useFeed := line.useFeed
+	for _, st := range stopTimes[1:] {
+		if st.GetShapeDistTraveled() <= 0 {
+			useFeed = false
+			break
+		}
+	}
+
+	stops := make([]tripStop, len(stopTimes))
+	prev := 0.0
+	for i, st := range stopTimes {
+		along := prev
+		if useFeed {
+			along = line.feedToMeters(st.GetShapeDistTraveled())
+		} else if stop, found := findStopById(st.GetStopId()); found {
+			along, _, _, _ = line.project(stop.GetStopLat(), stop.GetStopLon(), prev, line.Length())
+		}
+		along = math.Max(along, prev)
+		stops[i] = tripStop{StopTime: st, Along: along}
+		prev = along
+	}
+	return stops
+}
+
+// stopIndexAfter returns the index of the first stop strictly beyond along,
+// or len(stops) when the vehicle is past the last stop.
+func (g *tripGeometry) stopIndexAfter(along float64) int {
+	for i, s := range g.stops {
+		if s.Along > along {
+			return i
+		}
+	}
+	return len(g.stops)
+}
+
+type StopProgress struct {
+	StopId         string  `json:"stop_id"`
+	StopName       string  `json:"stop_name,omitempty"`
+	StopSequence   int32   `json:"stop_sequence"`
+	DistanceAlong  float64 `json:"distance_along_m"`
+	DistanceToStop float64 `json:"distance_to_stop_m"`
+}
+
+// VehicleProgress describes where a vehicle is on its trip's shape.
+type VehicleProgress struct {
+	VehicleId        string        `json:"vehicle_id"`
+	TripId           string        `json:"trip_id"`
+	ShapeId          string        `json:"shape_id"`
+	SnappedLat       float64       `json:"snapped_lat"`
+	SnappedLon       float64       `json:"snapped_lon"`
+	DistanceTraveled float64       `json:"distance_traveled_m"`
+	ShapeLength      float64       `json:"shape_length_m"`
+	PercentComplete  float64       `json:"percent_complete"`
+	OffRouteDistance float64       `json:"off_route_distance_m"`
+	OffRoute         bool          `json:"off_route"`
+	PreviousStop     *StopProgress `json:"previous_stop,omitempty"`
+	NextStop         *StopProgress `json:"next_stop,omitempty"`
+	StopsRemaining   int           `json:"stops_remaining"`
+	Timestamp        int64         `json:"timestamp"`
+
+	geometry  *tripGeometry
+	nextIndex int
+}
+
+// SnapVehicle projects a vehicle onto its trip's shape. When the vehicle
+// reports a stop on its trip the search starts at the stop before it, which
+// keeps vehicles on loop and out-and-back shapes on the right leg.
+func SnapVehicle(v *protodata.VehiclePositionProto, offRouteMeters float64) (*VehicleProgress, error) {
+	tripId := v.GetTrip().GetTripId()
+	if tripId == "" {
+		return nil, fmt.Errorf("vehicle %s is not assigned to a trip", v.GetVehicle().GetId())
+	}
+	g, err := getTripGeometry(tripId)
+	if err != nil {
+		return nil, err
+	}
+
+	lat, lon := v.GetPosition().GetLatitude(), v.GetPosition().GetLongitude()
+	from, to := 0.0, g.line.Length()
+	for i, s := range g.stops {
+		if s.StopTime.GetStopId() == v.GetStopId() {
+			if i > 0 {
+				from = g.stops[i-1].Along
+			}
+			to = s.Along
+			break
+		}
+	}
+
+	along, offset, sLat, sLon := g.line.project(lat, lon, from, to)
+	if offset > offRouteMeters && (from > 0 || to < g.line.Length()) {
+		// the reported stop may be stale; try the whole shape
+		if a, o, la, lo := g.line.project(lat, lon, 0, g.line.Length()); o < offset {
+			along, offset, sLat, sLon = a, o, la, lo
+		}
+	}
+
+	p := &VehicleProgress{
+		VehicleId:        v.GetVehicle().GetId(),
+		TripId:           tripId,
+		ShapeId:          g.line.shapeId,
+		SnappedLat:       sLat,
+		SnappedLon:       sLon,
+		DistanceTraveled: along,
+		ShapeLength:      g.line.Length(),
+		OffRouteDistance: offset,
+		OffRoute:         offset > offRouteMeters,
+		Timestamp:        v.GetTimestamp(),
+		geometry:         g,
+	}
+
+	if n := len(g.stops); n > 0 {
+		first, last := g.stops[0].Along, g.stops[n-1].Along
+		if last > first {
+			p.PercentComplete = math.Max(0, math.Min(100, (along-first)/(last-first)*100))
+		}
+
+		next := g.stopIndexAfter(along)
+		p.nextIndex = next
+		p.StopsRemaining = n - next
+		if next > 0 {
+			p.PreviousStop = newStopProgress(g.stops[next-1], along)
+		}
+		if next < n {
+			p.NextStop = newStopProgress(g.stops[next], along)
+		}
+	}
+	return p, nil
+}
+
+func newStopProgress(s tripStop, along float64) *StopProgress {
+	sp := &StopProgress{
+		StopId:         s.StopTime.GetStopId(),
+		StopSequence:   s.StopTime.GetStopSequence(),
+		DistanceAlong:  s.Along,
+		DistanceToStop: math.Abs(s.Along - along),
+	}
+	if stop, found := findStopById(sp.StopId); found {
+		sp.StopName = stop.GetStopName()
+	}
+	return sp
+}
+
+func offRouteFromQuery(c *gin.Context) (float64, error) {
+	s := c.Query("off_route_m")
+	if s == "" {
+		return offRouteThresholdMeters, nil
+	}
+	v, err := strconv.ParseFloat(s, 64)
+	if err != nil || v <= 0 {
+		return 0, fmt.Errorf("off_route_m must be a positive number of meters")
+	}
+	return v, nil
+}
+
+// GET /vehicles/:id/progress?off_route_m=
+func HandleVehicleProgress(c *gin.Context) {
+	threshold, err := offRouteFromQuery(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	snap, err := currentRealtime()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch vehicle positions"})
+		return
+	}
+	positions := snap.Vehicles
+
+	p, found := findVehicle(positions, c.Param("id"))
+	if !found {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Vehicle not found"})
+		return
+	}
+
+	progress, err := SnapVehicle(p.GetVehicle(), threshold)
+	if err != nil {
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, progress)
+}
@@ -0,0 +1,420 @@
+package server
+
+import (
+	"cmp"
+	"math"
+	"net/http"
+	"slices"
+	"strconv"
+	"studious-waffle/server/protodata"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	SpacingBunching = "spacing.bunching"
+	SpacingGap      = "spacing.gap"
+	SpacingCleared  = "spacing.cleared"
+)
+
+// Statuses of a pair of vehicles.
+const (
+	SpacingNormal  = "normal"
+	SpacingBunched = "bunching"
+	SpacingGapped  = "gap"
+	SpacingUnknown = "unknown"
+)
+
+const (
+	// maxRecentSpacingEvents is how many events GET /spacing/events keeps
+	maxRecentSpacingEvents = 500
+	// trips starting within scheduledHeadwayWindow seconds of the leader's
+	// set the headway a pair is held to
+	scheduledHeadwayWindow = 3600
+)
+
+// SpacingThresholds say when a pair of vehicles is reported, as fractions of
+// the scheduled headway.
+type SpacingThresholds struct {
+	// bunched when the follower is closer than this fraction of a headway
+	BunchingRatio float64 `json:"bunching_ratio"`
+	// a gap when it is further than this many headways behind
+	GapRatio float64 `json:"gap_ratio"`
+}
+
+var defaultSpacingThresholds = SpacingThresholds{BunchingRatio: 0.25, GapRatio: 2.0}
+
+// VehicleSpacing is how far a vehicle runs behind the one ahead of it on the
+// same route and direction. HeadwaySeconds is the scheduled running time
+// between the two positions: how long until the follower is where the
+// leader is now.
+type VehicleSpacing struct {
+	RouteId                 string            `json:"route_id"`
+	DirectionId             int32             `json:"direction_id"`
+	LeaderVehicleId         string            `json:"leader_vehicle_id"`
+	LeaderTripId            string            `json:"leader_trip_id"`
+	FollowerVehicleId       string            `json:"follower_vehicle_id"`
+	FollowerTripId          string            `json:"follower_trip_id"`
+	DistanceMeters          float64           `json:"distance_m"`
+	HeadwaySeconds          int               `json:"headway_seconds"`
+	ScheduledHeadwaySeconds int               `json:"scheduled_headway_seconds,omitempty"`
+	Ratio                   float64           `json:"ratio,omitempty"`
+	Status                  string            `json:"status"`
+	Thresholds              SpacingThresholds `json:"thresholds"`
+	ObservedAt              time.Time         `json:"observed_at"`
+}
+
+func (s VehicleSpacing) key() string {
+	return s.RouteId + "|" + strconv.Itoa(int(s.DirectionId)) + "|" + s.LeaderVehicleId + "|" + s.FollowerVehicleId
+}
+
+type routeDirection struct {
+	routeId     string
+	directionId int32
+}
+
+// spacedVehicle is a vehicle placed on the reference shape of its route and
+// direction.
+type spacedVehicle struct {
+	progress *VehicleProgress
+	date     string
+	// shift is the Shift of the run of its trip the vehicle is making
+	shift int
+	along float64
+}
+
+// SpacingDetector measures the spacing of consecutive vehicles on every
+// route and direction after each realtime poll, and reports pairs that
+// bunch up or leave a gap as they start and stop doing so.
+type SpacingDetector struct {
+	mu         sync.Mutex
+	thresholds map[string]SpacingThresholds
+	current    []VehicleSpacing
+	active     map[string]VehicleSpacing
+	recent     []Event
+	// starts holds the scheduled trip starts of each route and direction,
+	// for one service date at a time
+	startsDate string
+	starts     map[routeDirection][]int
+}
+
+func NewSpacingDetector(thresholds map[string]SpacingThresholds) *SpacingDetector {
+	return &SpacingDetector{thresholds: thresholds, active: make(map[string]VehicleSpacing)}
+}
+
+var spacingDetector = NewSpacingDetector(nil)
+
+// startSpacing has the detector measure every realtime poll, with the
+// configured per-route thresholds.
+func startSpacing() {
+	spacingDetector = NewSpacingDetector(config.Analytics.SpacingThresholds)
+	OnRealtimeUpdate(spacingDetector.Observe)
+}
+
+// thresholdsFor returns a route's thresholds, with defaults for whatever
+// the route's configuration leaves out.
+func (d *SpacingDetector) thresholdsFor(routeId string) SpacingThresholds {
+	t := d.thresholds[routeId]
+	if t.BunchingRatio <= 0 {
+		t.BunchingRatio = defaultSpacingThresholds.BunchingRatio
+	}
+	if t.GapRatio <= 0 {
+		t.GapRatio = defaultSpacingThresholds.GapRatio
+	}
+	return t
+}
+
+// scheduledHeadway is the median gap between the scheduled starts of a
+// route and direction's trips around the time the leader's trip started.
+func (d *SpacingDetector) scheduledHeadway(rd routeDirection, leaderStart int, date string) int {
+	if d.startsDate != date {
+		d.startsDate = date
+		d.starts = make(map[routeDirection][]int)
+		for _, trip := range Trips {
+			if !serviceRunsOn(trip.GetServiceId(), date) {
+				continue
+			}
+			stopTimes, found := findStopTimesByTripID(trip.GetTripId())
+			if !found {
+				continue
+			}
+			_, dep, ok := stopTimeSeconds(stopTimes[0])
+			if !ok {
+				continue
+			}
+			key := routeDirection{trip.GetRouteId(), trip.GetDirectionId()}
+			for _, run := range tripInstances(trip.GetTripId(), stopTimes) {
+				d.starts[key] = append(d.starts[key], dep+run.Shift)
+			}
+		}
+		for _, s := range d.starts {
+			slices.Sort(s)
+		}
+	}
+
+	starts := d.starts[rd]
+	var gaps []int
+	for i := 1; i < len(starts); i++ {
+		if starts[i] >= leaderStart-scheduledHeadwayWindow && starts[i-1] <= leaderStart+scheduledHeadwayWindow {
+			gaps = append(gaps, starts[i]-starts[i-1])
+		}
+	}
+	if len(gaps) == 0 {
+		return 0
+	}
+	slices.Sort(gaps)
+	return gaps[len(gaps)/2]
+}
+
+// scheduledTimeAt interpolates a trip's schedule at a distance along its
+// shape.
+func scheduledTimeAt(g *tripGeometry, along float64) float64 {
+	prevAlong, prevTime := 0.0, -1.0
+	for _, s := range g.stops {
+		arr, _, ok := stopTimeSeconds(s.StopTime)
+		if !ok {
+			continue
+		}
+		t := float64(arr)
+		if along <= s.Along {
+			if prevTime < 0 || s.Along <= prevAlong {
+				return t
+			}
+			return prevTime + (along-prevAlong)/(s.Along-prevAlong)*(t-prevTime)
+		}
+		prevAlong, prevTime = s.Along, t
+	}
+	return math.Max(prevTime, 0)
+}
+
+// runShift is the Shift of the run a vehicle is making of its trip. Updates
+// don't say which run of a frequency-based trip they mean, so it is the run
+// whose schedule has the vehicle where it was seen closest to when it was.
+func runShift(g *tripGeometry, along float64, stopTimes []*protodata.StopTimeProto, date string, at time.Time) int {
+	runs := tripInstances(g.trip.GetTripId(), stopTimes)
+	if len(runs) == 0 {
+		return 0
+	}
+	day, err := parseServiceDate(date)
+	if err != nil {
+		return runs[0].Shift
+	}
+	elapsed := at.Sub(serviceDayStart(day)).Seconds()
+	scheduled := scheduledTimeAt(g, along)
+	best := runs[0].Shift
+	for _, run := range runs[1:] {
+		if math.Abs(scheduled+float64(run.Shift)-elapsed) < math.Abs(scheduled+float64(best)-elapsed) {
+			best = run.Shift
+		}
+	}
+	return best
+}
+
+// measure orders the vehicles of one route and direction along a reference
+// shape, the one most of them follow, and measures each consecutive pair.
+// Vehicles on other shape variants are projected onto it; those too far
+// from it, on a branch, are left out.
+func (d *SpacingDetector) measure(rd routeDirection, vehicles []spacedVehicle, now time.Time) []VehicleSpacing {
+	shapes := make(map[string]int)
+	for _, v := range vehicles {
+		shapes[v.progress.ShapeId]++
+	}
+	var ref *tripGeometry
+	for _, v := range vehicles {
+		g := v.progress.geometry
+		if ref == nil || shapes[g.line.shapeId] > shapes[ref.line.shapeId] ||
+			shapes[g.line.shapeId] == shapes[ref.line.shapeId] && g.line.shapeId < ref.line.shapeId {
+			ref = g
+		}
+	}
+
+	placed := vehicles[:0]
+	for _, v := range vehicles {
+		if v.progress.ShapeId == ref.line.shapeId {
+			v.along = v.progress.DistanceTraveled
+		} else {
+			along, offset, _, _ := ref.line.project(v.progress.SnappedLat, v.progress.SnappedLon, 0, ref.line.Length())
+			if offset > offRouteThresholdMeters {
+				continue
+			}
+			v.along = along
+		}
+		placed = append(placed, v)
+	}
+	slices.SortFunc(placed, func(a, b spacedVehicle) int {
+		return cmp.Compare(b.along, a.along)
+	})
+
+	thresholds := d.thresholdsFor(rd.routeId)
+	var spacings []VehicleSpacing
+	for i := 1; i < len(placed); i++ {
+		leader, follower := placed[i-1], placed[i]
+		s := VehicleSpacing{
+			RouteId:           rd.routeId,
+			DirectionId:       rd.directionId,
+			LeaderVehicleId:   leader.progress.VehicleId,
+			LeaderTripId:      leader.progress.TripId,
+			FollowerVehicleId: follower.progress.VehicleId,
+			FollowerTripId:    follower.progress.TripId,
+			DistanceMeters:    math.Round(leader.along - follower.along),
+			HeadwaySeconds:    int(math.Round(scheduledTimeAt(ref, leader.along) - scheduledTimeAt(ref, follower.along))),
+			Status:            SpacingUnknown,
+			Thresholds:        thresholds,
+			ObservedAt:        now,
+		}
+
+		leaderStart := 0
+		if _, dep, ok := stopTimeSeconds(leader.progress.geometry.stops[0].StopTime); ok {
+			leaderStart = dep + leader.shift
+		}
+		if sched := d.scheduledHeadway(rd, leaderStart, leader.date); sched > 0 {
+			s.ScheduledHeadwaySeconds = sched
+			s.Ratio = math.Round(float64(s.HeadwaySeconds)/float64(sched)*100) / 100
+			switch {
+			case s.Ratio < thresholds.BunchingRatio:
+				s.Status = SpacingBunched
+			case s.Ratio > thresholds.GapRatio:
+				s.Status = SpacingGapped
+			default:
+				s.Status = SpacingNormal
+			}
+		}
+		spacings = append(spacings, s)
+	}
+	return spacings
+}
+
+// Observe measures the spacing in a poll and publishes an event for every
+// pair that starts bunching, starts leaving a gap, or recovers. Vehicles
+// still to start their trip or done with it are left out, since buses
+// waiting at a terminal are not bunched.
+func (d *SpacingDetector) Observe(snap *RealtimeSnapshot) {
+	groups := make(map[routeDirection][]spacedVehicle)
+	for _, e := range snap.Vehicles {
+		v := e.GetVehicle()
+		progress, err := SnapVehicle(v, offRouteThresholdMeters)
+		if err != nil || progress.OffRoute {
+			continue
+		}
+		if progress.PercentComplete <= 0 || progress.PercentComplete >= 100 {
+			continue
+		}
+		g := progress.geometry
+		stopTimes, _ := findStopTimesByTripID(progress.TripId)
+		rd := routeDirection{g.trip.GetRouteId(), g.trip.GetDirectionId()}
+		date := runDate(v.GetTrip().GetStartDate(), stopTimes, snap.FetchedAt)
+		seen := snap.FetchedAt
+		if v.GetTimestamp() > 0 {
+			seen = time.Unix(v.GetTimestamp(), 0)
+		}
+		groups[rd] = append(groups[rd], spacedVehicle{
+			progress: progress,
+			date:     date,
+			shift:    runShift(g, progress.DistanceTraveled, stopTimes, date, seen),
+		})
+	}
+
+	keys := make([]routeDirection, 0, len(groups))
+	for rd := range groups {
+		keys = append(keys, rd)
+	}
+	slices.SortFunc(keys, func(a, b routeDirection) int {
+		if c := naturalCompare(a.routeId, b.routeId); c != 0 {
+			return c
+		}
+		return cmp.Compare(a.directionId, b.directionId)
+	})
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	current := make([]VehicleSpacing, 0)
+	for _, rd := range keys {
+		current = append(current, d.measure(rd, groups[rd], snap.FetchedAt)...)
+	}
+
+	var events []Event
+	seen := make(map[string]bool)
+	for _, s := range current {
+		eventType := SpacingBunching
+		switch s.Status {
+		case SpacingBunched:
+		case SpacingGapped:
+			eventType = SpacingGap
+		default:
+			continue
+		}
+		key := s.key()
+		seen[key] = true
+		if prev, found := d.active[key]; !found || prev.Status != s.Status {
+			events = append(events, NewEvent(eventType, s))
+		}
+		d.active[key] = s
+	}
+	for key, prev := range d.active {
+		if !seen[key] {
+			events = append(events, NewEvent(SpacingCleared, prev))
+			delete(d.active, key)
+		}
+	}
+	slices.SortFunc(events, func(a, b Event) int {
+		return cmp.Compare(a.Data.(VehicleSpacing).key(), b.Data.(VehicleSpacing).key())
+	})
+
+	d.current = current
+	d.recent = append(d.recent, events...)
+	if n := len(d.recent); n > maxRecentSpacingEvents {
+		d.recent = slices.Clone(d.recent[n-maxRecentSpacingEvents:])
+	}
+	publishEvents(events)
+}
+
+// GET /spacing?route_id=&direction_id=&status=bunching
+func HandleSpacing(c *gin.Context) {
+	routeId, status := c.Query("route_id"), c.Query("status")
+	direction, err := queryInt(c, "direction_id", -1, 0, 1)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	spacingDetector.mu.Lock()
+	current := spacingDetector.current
+	spacingDetector.mu.Unlock()
+
+	results := make([]VehicleSpacing, 0)
+	for _, s := range current {
+		if routeId != "" && s.RouteId != routeId || direction >= 0 && int(s.DirectionId) != direction {
+			continue
+		}
+		if status != "" && s.Status != status {
+			continue
+		}
+		results = append(results, s)
+	}
+	c.JSON(http.StatusOK, results)
+}
+
+// GET /spacing/events?route_id=&limit=
+func HandleSpacingEvents(c *gin.Context) {
+	limit, err := queryInt(c, "limit", 100, 1, maxRecentSpacingEvents)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	routeId := c.Query("route_id")
+
+	spacingDetector.mu.Lock()
+	recent := spacingDetector.recent
+	spacingDetector.mu.Unlock()
+
+	events := make([]Event, 0)
+	for i := len(recent) - 1; i >= 0 && len(events) < limit; i-- {
+		if routeId == "" || recent[i].Data.(VehicleSpacing).RouteId == routeId {
+			events = append(events, recent[i])
+		}
+	}
+	c.JSON(http.StatusOK, events)
+}
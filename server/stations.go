@@ -0,0 +1,220 @@
+package server
+
+import (
+	"cmp"
+	"net/http"
+	"slices"
+	"studious-waffle/server/protodata"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GTFS location_type values.
+const (
+	LocationStop         = 0
+	LocationStation      = 1
+	LocationEntrance     = 2
+	LocationGenericNode  = 3
+	LocationBoardingArea = 4
+)
+
+const (
+	defaultStationDepartures = 20
+	maxStationDepartures     = 200
+)
+
+// stopChildren maps a parent_station to the stops that name it: platforms,
+// entrances and nodes under a station, boarding areas under a platform.
+var stopChildren = sync.OnceValue(func() map[string][]*protodata.StopProto {
+	children := make(map[string][]*protodata.StopProto)
+	for _, s := range Stops {
+		if parent := s.GetParentStation(); parent != "" {
+			children[parent] = append(children[parent], s)
+		}
+	}
+	return children
+})
+
+// stationForStop walks up parent_station links to the station a stop belongs
+// to. Stops outside any station are returned as they are.
+func stationForStop(stop *protodata.StopProto) *protodata.StopProto {
+	// boarding areas sit two levels down; the bound guards against cycles
+	for range 3 {
+		if stop.GetParentStation() == "" {
+			break
+		}
+		parent, found := findStopById(stop.GetParentStation())
+		if !found {
+			break
+		}
+		stop = parent
+	}
+	return stop
+}
+
+type StationView struct {
+	Station       *protodata.StopProto    `json:"station"`
+	Platforms     []*protodata.StopProto  `json:"platforms"`
+	Entrances     []*protodata.StopProto  `json:"entrances"`
+	GenericNodes  []*protodata.StopProto  `json:"generic_nodes"`
+	BoardingAreas []*protodata.StopProto  `json:"boarding_areas"`
+	Routes        []*protodata.RouteProto `json:"routes"`
+	Departures    []StationDeparture      `json:"departures"`
+}
+
+// StationDeparture is a scheduled departure from one of a station's stops.
+type StationDeparture struct {
+	StopId         string    `json:"stop_id"`
+	StopName       string    `json:"stop_name"`
+	TripId         string    `json:"trip_id"`
+	ServiceId      string    `json:"service_id"`
+	RouteId        string    `json:"route_id"`
+	RouteShortName string    `json:"route_short_name"`
+	TripHeadsign   string    `json:"trip_headsign"`
+	DepartureTime  string    `json:"departure_time"`
+	Departure      time.Time `json:"departure"`
+	// set for frequency-based trips without exact times, whose departures
+	// are approximate: the service runs every HeadwaySecs
+	HeadwaySecs int `json:"headway_secs,omitempty"`
+}
+
+// stationMembers is every stop under a station, boarding areas included.
+func stationMembers(stationId string) []*protodata.StopProto {
+	var members []*protodata.StopProto
+	for _, child := range stopChildren()[stationId] {
+		members = append(members, child)
+		members = append(members, stopChildren()[child.GetStopId()]...)
+	}
+	return members
+}
+
+func buildStationView(station *protodata.StopProto, now time.Time, limit int) StationView {
+	view := StationView{
+		Station:       station,
+		Platforms:     make([]*protodata.StopProto, 0),
+		Entrances:     make([]*protodata.StopProto, 0),
+		GenericNodes:  make([]*protodata.StopProto, 0),
+		BoardingAreas: make([]*protodata.StopProto, 0),
+		Routes:        make([]*protodata.RouteProto, 0),
+	}
+
+	// riders board at the station itself when it has no platforms
+	boarding := []*protodata.StopProto{station}
+	for _, s := range stationMembers(station.GetStopId()) {
+		switch s.GetLocationType() {
+		case LocationStop:
+			view.Platforms = append(view.Platforms, s)
+			boarding = append(boarding, s)
+		case LocationEntrance:
+			view.Entrances = append(view.Entrances, s)
+		case LocationGenericNode:
+			view.GenericNodes = append(view.GenericNodes, s)
+		case LocationBoardingArea:
+			view.BoardingAreas = append(view.BoardingAreas, s)
+		}
+	}
+
+	seenRoutes := make(map[string]bool)
+	for _, s := range boarding {
+		stopTimes, _ := findStopTimesByStopID(s.GetStopId())
+		for _, st := range stopTimes {
+			trip, found := findTripByID(st.GetTripId())
+			if !found || seenRoutes[trip.GetRouteId()] {
+				continue
+			}
+			seenRoutes[trip.GetRouteId()] = true
+			if route, found := findRouteByID(trip.GetRouteId()); found {
+				view.Routes = append(view.Routes, routeWithNetwork(route))
+			}
+		}
+	}
+	slices.SortFunc(view.Routes, compareRouteNames)
+
+	view.Departures = stationDepartures(boarding, now, limit)
+	return view
+}
+
+// stationDepartures lists the next departures from any of the given stops
+// on trips whose service runs that day. Trips from yesterday's service day
+// that run past midnight are included.
+func stationDepartures(stops []*protodata.StopProto, now time.Time, limit int) []StationDeparture {
+	today := serviceDayStart(now)
+	yesterday := serviceDayStart(today.Add(-12 * time.Hour))
+
+	departures := make([]StationDeparture, 0)
+	for _, s := range stops {
+		stopTimes, _ := findStopTimesByStopID(s.GetStopId())
+		for _, st := range stopTimes {
+			_, dep, ok := stopTimeSeconds(st)
+			if !ok {
+				continue
+			}
+			// a trip's last stop is an arrival only
+			tripStops, _ := findStopTimesByTripID(st.GetTripId())
+			if len(tripStops) > 0 && tripStops[len(tripStops)-1] == st {
+				continue
+			}
+			trip, found := findTripByID(st.GetTripId())
+			if !found {
+				continue
+			}
+
+			for _, run := range tripInstances(trip.GetTripId(), tripStops) {
+				for _, day := range []time.Time{yesterday, today} {
+					at := day.Add(time.Duration(dep+run.Shift) * time.Second)
+					if at.Before(now) || !serviceRunsOn(trip.GetServiceId(), serviceDate(day)) {
+						continue
+					}
+					d := StationDeparture{
+						StopId:        s.GetStopId(),
+						StopName:      s.GetStopName(),
+						TripId:        trip.GetTripId(),
+						ServiceId:     trip.GetServiceId(),
+						RouteId:       trip.GetRouteId(),
+						TripHeadsign:  trip.GetTripHeadsign(),
+						DepartureTime: formatGTFSTime(dep + run.Shift),
+						Departure:     at,
+						HeadwaySecs:   run.HeadwaySecs,
+					}
+					if route, found := findRouteByID(trip.GetRouteId()); found {
+						d.RouteShortName = route.GetRouteShortName()
+					}
+					departures = append(departures, d)
+				}
+			}
+		}
+	}
+
+	slices.SortFunc(departures, func(a, b StationDeparture) int {
+		if c := a.Departure.Compare(b.Departure); c != 0 {
+			return c
+		}
+		return cmp.Compare(a.TripId, b.TripId)
+	})
+	return departures[:min(limit, len(departures))]
+}
+
+// GET /stations/:id?limit=
+func HandleStationById(c *gin.Context) {
+	limit, err := queryInt(c, "limit", defaultStationDepartures, 0, maxStationDepartures)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	stop, found := findStopById(c.Param("id"))
+	if !found {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Station not found"})
+		return
+	}
+	// a platform, entrance or boarding area id finds its station
+	station := stationForStop(stop)
+	if station.GetLocationType() != LocationStation {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Stop is not part of a station"})
+		return
+	}
+
+	c.JSON(http.StatusOK, buildStationView(station, time.Now(), limit))
+}
@@ -0,0 +1,349 @@
+package server
+
+import (
+	"math"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"studious-waffle/server/protodata"
+	"sync"
+	"unicode"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	defaultStopSearchLimit = 10
+	maxStopSearchLimit     = 50
+)
+
+// stopAbbreviations maps the short forms found on signs and typed by riders
+// to the word they stand for, so "Colfax Ave & Broadway" and "colfax avenue
+// and broadway" index the same.
+var stopAbbreviations = map[string]string{
+	"&":    "and",
+	"@":    "at",
+	"st":   "street",
+	"ave":  "avenue",
+	"av":   "avenue",
+	"blvd": "boulevard",
+	"rd":   "road",
+	"dr":   "drive",
+	"ln":   "lane",
+	"pl":   "place",
+	"ct":   "court",
+	"cir":  "circle",
+	"pkwy": "parkway",
+	"hwy":  "highway",
+	"sta":  "station",
+	"stn":  "station",
+	"ctr":  "center",
+	"pnr":  "parkandride",
+	"n":    "north",
+	"s":    "south",
+	"e":    "east",
+	"w":    "west",
+	"nb":   "northbound",
+	"sb":   "southbound",
+	"eb":   "eastbound",
+	"wb":   "westbound",
+	"mt":   "mount",
+}
+
+// tokenizeStopText splits text into lowercase words and numbers, keeping "&"
+// and "@" as words of their own so they can be normalized.
+func tokenizeStopText(s string) []string {
+	var tokens []string
+	var cur strings.Builder
+	flush := func() {
+		if cur.Len() > 0 {
+			tokens = append(tokens, cur.String())
+			cur.Reset()
+		}
+	}
+	for _, r := range strings.ToLower(s) {
+		switch {
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+			cur.WriteRune(r)
+		case r == '\'':
+			// "King's" -> "kings"
+		case r == '&' || r == '@':
+			flush()
+			tokens = append(tokens, string(r))
+		default:
+			flush()
+		}
+	}
+	flush()
+	return tokens
+}
+
+func normalizeStopToken(t string) string {
+	if full, ok := stopAbbreviations[t]; ok {
+		return full
+	}
+	return t
+}
+
+// Field weights: a hit in the stop name or code counts fully, one in the
+// description ("Vehicles Travelling North") much less.
+const (
+	stopFieldName = 1.0
+	stopFieldCode = 1.0
+	stopFieldDesc = 0.3
+)
+
+// How well a query term matched an indexed token.
+const (
+	stopMatchExact  = 1.0
+	stopMatchPrefix = 0.75
+	stopMatchTypo   = 0.5
+)
+
+type stopPosting struct {
+	stop   int
+	weight float64
+}
+
+// stopIndex is an inverted index from normalized tokens to the stops whose
+// name, code or description contain them.
+type stopIndex struct {
+	postings map[string][]stopPosting
+	// vocab is every indexed token, sorted for prefix lookups
+	vocab []string
+	// boost is the per-stop static rank: stations and busier stops first
+	boost []float64
+}
+
+var stopSearchIndex = sync.OnceValue(func() *stopIndex {
+	return buildStopIndex(Stops)
+})
+
+func buildStopIndex(stops []*protodata.StopProto) *stopIndex {
+	idx := &stopIndex{postings: make(map[string][]stopPosting)}
+
+	add := func(stop int, text string, weight float64) {
+		for _, t := range tokenizeStopText(text) {
+			t = normalizeStopToken(t)
+			list := idx.postings[t]
+			if n := len(list); n > 0 && list[n-1].stop == stop {
+				list[n-1].weight = max(list[n-1].weight, weight)
+				continue
+			}
+			idx.postings[t] = append(list, stopPosting{stop: stop, weight: weight})
+		}
+	}
+	for i, s := range stops {
+		add(i, s.GetStopName(), stopFieldName)
+		add(i, s.GetStopCode(), stopFieldCode)
+		add(i, s.GetStopDesc(), stopFieldDesc)
+	}
+
+	idx.vocab = make([]string, 0, len(idx.postings))
+	for t := range idx.postings {
+		idx.vocab = append(idx.vocab, t)
+	}
+	slices.Sort(idx.vocab)
+
+	idx.boost = stopBoosts(stops)
+	return idx
+}
+
+// stopBoosts ranks stations above their platforms and busy stops above quiet
+// ones. Service is the number of scheduled stop times; a station is credited
+// with its platforms' service.
+func stopBoosts(stops []*protodata.StopProto) []float64 {
+	service := make(map[string]int)
+	for _, st := range StopTimesByStop {
+		service[st.GetStopId()]++
+	}
+	for _, s := range stops {
+		if parent := s.GetParentStation(); parent != "" {
+			service[parent] += service[s.GetStopId()]
+		}
+	}
+
+	boosts := make([]float64, len(stops))
+	for i, s := range stops {
+		b := 1 + math.Log10(1+float64(service[s.GetStopId()]))/4
+		if s.GetLocationType() == 1 {
+			b *= 1.5
+		}
+		boosts[i] = b
+	}
+	return boosts
+}
+
+// typoDistance is how many edits a query term may be from an indexed token:
+// none for short terms, where one edit changes the word entirely, or for
+// numbers, where it names a different stop code or street.
+func typoDistance(term string) int {
+	if strings.IndexFunc(term, func(r rune) bool { return !unicode.IsDigit(r) }) < 0 {
+		return 0
+	}
+	switch n := len(term); {
+	case n < 4:
+		return 0
+	case n < 8:
+		return 1
+	default:
+		return 2
+	}
+}
+
+// editDistance is the optimal string alignment distance (Levenshtein with
+// adjacent transpositions), giving up once it exceeds limit.
+func editDistance(a, b string, limit int) int {
+	if d := len(a) - len(b); d > limit || -d > limit {
+		return limit + 1
+	}
+	prev2 := make([]int, len(b)+1)
+	prev := make([]int, len(b)+1)
+	cur := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		cur[0] = i
+		rowMin := cur[0]
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+			if i > 1 && j > 1 && a[i-1] == b[j-2] && a[i-2] == b[j-1] {
+				cur[j] = min(cur[j], prev2[j-2]+1)
+			}
+			rowMin = min(rowMin, cur[j])
+		}
+		if rowMin > limit {
+			return limit + 1
+		}
+		prev2, prev, cur = prev, cur, prev2
+	}
+	return prev[len(b)]
+}
+
+// matchTerm scores every stop containing a query term. Each stop keeps its
+// best match: an exact token, a token the term is a prefix of, or a token
+// within typo distance.
+func (idx *stopIndex) matchTerm(term string) map[int]float64 {
+	scores := make(map[int]float64)
+	collect := func(token string, quality float64) {
+		for _, p := range idx.postings[token] {
+			scores[p.stop] = max(scores[p.stop], quality*p.weight)
+		}
+	}
+
+	// the raw term is tried as well so "sta" still prefixes "stapleton"
+	// after normalizing to "station"
+	for _, t := range slices.Compact([]string{normalizeStopToken(term), term}) {
+		collect(t, stopMatchExact)
+		for i, _ := slices.BinarySearch(idx.vocab, t); i < len(idx.vocab) && strings.HasPrefix(idx.vocab[i], t); i++ {
+			if idx.vocab[i] != t {
+				collect(idx.vocab[i], stopMatchPrefix)
+			}
+		}
+	}
+
+	if limit := typoDistance(term); limit > 0 {
+		for _, token := range idx.vocab {
+			if token != term && editDistance(term, token, limit) <= limit {
+				collect(token, stopMatchTypo)
+			}
+		}
+	}
+	return scores
+}
+
+type StopSearchResult struct {
+	Stop           *protodata.StopProto `json:"stop"`
+	Score          float64              `json:"score"`
+	DistanceMeters *float64             `json:"distance_meters,omitempty"`
+}
+
+// StopSearchBias favours stops near a point, e.g. the rider's location.
+type StopSearchBias struct {
+	Lat, Lon float64
+}
+
+// Search returns stops matching every word of the query, best first.
+func (idx *stopIndex) Search(stops []*protodata.StopProto, query string, bias *StopSearchBias, limit int) []StopSearchResult {
+	terms := tokenizeStopText(query)
+	if len(terms) == 0 {
+		return []StopSearchResult{}
+	}
+
+	var total map[int]float64
+	for _, term := range terms {
+		scores := idx.matchTerm(term)
+		if total == nil {
+			total = scores
+			continue
+		}
+		for stop, s := range total {
+			if m, ok := scores[stop]; ok {
+				total[stop] = s + m
+			} else {
+				delete(total, stop)
+			}
+		}
+	}
+
+	results := make([]StopSearchResult, 0, len(total))
+	for i, s := range total {
+		r := StopSearchResult{Stop: stops[i], Score: s / float64(len(terms)) * idx.boost[i]}
+		if bias != nil {
+			d := haversineMeters(bias.Lat, bias.Lon, stops[i].GetStopLat(), stops[i].GetStopLon())
+			r.DistanceMeters = &d
+			// up to double the score right next to the point, fading
+			// out over a few kilometres
+			r.Score *= 1 + 1/(1+d/1000)
+		}
+		results = append(results, r)
+	}
+
+	slices.SortFunc(results, func(a, b StopSearchResult) int {
+		if a.Score != b.Score {
+			if a.Score > b.Score {
+				return -1
+			}
+			return 1
+		}
+		if c := len(a.Stop.GetStopName()) - len(b.Stop.GetStopName()); c != 0 {
+			return c
+		}
+		return strings.Compare(a.Stop.GetStopId(), b.Stop.GetStopId())
+	})
+	return results[:min(limit, len(results))]
+}
+
+// GET /stops/search?q=&lat=&lon=&limit=
+func HandleStopSearch(c *gin.Context) {
+	q := strings.TrimSpace(c.Query("q"))
+	if q == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "q query param required"})
+		return
+	}
+
+	limit, err := queryInt(c, "limit", defaultStopSearchLimit, 1, maxStopSearchLimit)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var bias *StopSearchBias
+	if c.Query("lat") != "" || c.Query("lon") != "" {
+		lat, errLat := strconv.ParseFloat(c.Query("lat"), 64)
+		lon, errLon := strconv.ParseFloat(c.Query("lon"), 64)
+		if errLat != nil || errLon != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "lat and lon must both be numbers"})
+			return
+		}
+		bias = &StopSearchBias{Lat: lat, Lon: lon}
+	}
+
+	c.JSON(http.StatusOK, stopSearchIndex().Search(Stops, q, bias, limit))
+}
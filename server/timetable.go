@@ -0,0 +1,280 @@
+package server
+
+import (
+	"cmp"
+	"encoding/csv"
+	"fmt"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"studious-waffle/server/protodata"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// TimetableStop is a row of a timetable. A stop a trip visits twice, as on
+// loop routes, gets a row per visit.
+type TimetableStop struct {
+	StopId    string `json:"stop_id"`
+	StopName  string `json:"stop_name"`
+	Timepoint bool   `json:"timepoint"`
+}
+
+// TimetableTrip is a column of a timetable: a trip, or one run of a
+// frequency-based trip.
+type TimetableTrip struct {
+	TripId       string `json:"trip_id"`
+	TripHeadsign string `json:"trip_headsign"`
+	ServiceId    string `json:"service_id"`
+	// Pattern is the stop pattern the trip follows, 0 being the dominant one
+	Pattern     int `json:"pattern"`
+	HeadwaySecs int `json:"headway_secs,omitempty"`
+}
+
+type TimetableCell struct {
+	ArrivalTime   string `json:"arrival_time"`
+	DepartureTime string `json:"departure_time"`
+	Timepoint     bool   `json:"timepoint"`
+}
+
+type Timetable struct {
+	RouteId        string          `json:"route_id"`
+	RouteShortName string          `json:"route_short_name"`
+	DirectionId    int32           `json:"direction_id"`
+	Date           string          `json:"date"`
+	Warning        string          `json:"warning,omitempty"`
+	Stops          []TimetableStop `json:"stops"`
+	Trips          []TimetableTrip `json:"trips"`
+	// Cells[stop][trip], nil where the trip doesn't serve the stop
+	Cells [][]*TimetableCell `json:"cells"`
+}
+
+// patternKey names a row: a stop and which visit to it within the trip.
+type patternKey struct {
+	stopId string
+	visit  int
+}
+
+func tripPattern(stopTimes []*protodata.StopTimeProto) []patternKey {
+	visits := make(map[string]int)
+	pattern := make([]patternKey, len(stopTimes))
+	for i, st := range stopTimes {
+		pattern[i] = patternKey{stopId: st.GetStopId(), visit: visits[st.GetStopId()]}
+		visits[st.GetStopId()]++
+	}
+	return pattern
+}
+
+func patternId(pattern []patternKey) string {
+	var b strings.Builder
+	for _, k := range pattern {
+		fmt.Fprintf(&b, "%s#%d|", k.stopId, k.visit)
+	}
+	return b.String()
+}
+
+// mergePattern adds the stops of a pattern that the row order lacks, each
+// right after the nearest earlier stop of the pattern already in the order.
+// Short turns then slot into the dominant pattern and branches are placed
+// next to where they leave it.
+func mergePattern(order []patternKey, pattern []patternKey) []patternKey {
+	pos := make(map[patternKey]int, len(order))
+	for i, k := range order {
+		pos[k] = i
+	}
+
+	insertAt := 0
+	for _, k := range pattern {
+		if i, found := pos[k]; found {
+			insertAt = i + 1
+			continue
+		}
+		order = slices.Insert(order, insertAt, k)
+		for i := insertAt; i < len(order); i++ {
+			pos[order[i]] = i
+		}
+		insertAt++
+	}
+	return order
+}
+
+type timetableColumn struct {
+	trip      *protodata.TripProto
+	stopTimes []*protodata.StopTimeProto
+	pattern   []patternKey
+	run       tripInstance
+	first     int
+}
+
+// buildTimetable lays out the trips of a route and direction that run on a
+// service date. Rows follow the most common stop pattern, with stops from
+// other patterns merged in; columns are ordered by departure time.
+func buildTimetable(route *protodata.RouteProto, direction int32, date string) Timetable {
+	tt := Timetable{
+		RouteId:        route.GetRouteId(),
+		RouteShortName: route.GetRouteShortName(),
+		DirectionId:    direction,
+		Date:           date,
+		Stops:          make([]TimetableStop, 0),
+		Trips:          make([]TimetableTrip, 0),
+		Cells:          make([][]*TimetableCell, 0),
+	}
+	if !calendarCovers(date) {
+		tt.Warning = "date is outside the feed's service calendar"
+	}
+
+	var columns []timetableColumn
+	patternCounts := make(map[string]int)
+	patterns := make(map[string][]patternKey)
+	for _, trip := range Trips {
+		if trip.GetRouteId() != route.GetRouteId() || trip.GetDirectionId() != direction || !serviceRunsOn(trip.GetServiceId(), date) {
+			continue
+		}
+		stopTimes, found := findStopTimesByTripID(trip.GetTripId())
+		if !found {
+			continue
+		}
+		_, first, ok := stopTimeSeconds(stopTimes[0])
+		if !ok {
+			continue
+		}
+
+		pattern := tripPattern(stopTimes)
+		id := patternId(pattern)
+		patterns[id] = pattern
+		for _, run := range tripInstances(trip.GetTripId(), stopTimes) {
+			patternCounts[id]++
+			columns = append(columns, timetableColumn{trip: trip, stopTimes: stopTimes, pattern: pattern, run: run, first: first + run.Shift})
+		}
+	}
+
+	// most common pattern first, longer ones breaking ties
+	ids := make([]string, 0, len(patterns))
+	for id := range patterns {
+		ids = append(ids, id)
+	}
+	slices.SortFunc(ids, func(a, b string) int {
+		if c := cmp.Compare(patternCounts[b], patternCounts[a]); c != 0 {
+			return c
+		}
+		if c := cmp.Compare(len(patterns[b]), len(patterns[a])); c != 0 {
+			return c
+		}
+		return cmp.Compare(a, b)
+	})
+	patternIndex := make(map[string]int, len(ids))
+	var order []patternKey
+	for i, id := range ids {
+		patternIndex[id] = i
+		order = mergePattern(order, patterns[id])
+	}
+
+	rows := make(map[patternKey]int, len(order))
+	for i, k := range order {
+		rows[k] = i
+		row := TimetableStop{StopId: k.stopId}
+		if stop, found := findStopById(k.stopId); found {
+			row.StopName = stop.GetStopName()
+		}
+		tt.Stops = append(tt.Stops, row)
+		tt.Cells = append(tt.Cells, make([]*TimetableCell, 0, len(columns)))
+	}
+
+	slices.SortStableFunc(columns, func(a, b timetableColumn) int {
+		return cmp.Compare(a.first, b.first)
+	})
+	for j, col := range columns {
+		tt.Trips = append(tt.Trips, TimetableTrip{
+			TripId:       col.trip.GetTripId(),
+			TripHeadsign: col.trip.GetTripHeadsign(),
+			ServiceId:    col.trip.GetServiceId(),
+			Pattern:      patternIndex[patternId(col.pattern)],
+			HeadwaySecs:  col.run.HeadwaySecs,
+		})
+		for i := range tt.Cells {
+			tt.Cells[i] = append(tt.Cells[i], nil)
+		}
+		for k, st := range col.stopTimes {
+			arr, dep, ok := stopTimeSeconds(st)
+			if !ok {
+				// an untimed stop between timepoints
+				continue
+			}
+			i := rows[col.pattern[k]]
+			tt.Cells[i][j] = &TimetableCell{
+				ArrivalTime:   formatGTFSTime(arr + col.run.Shift),
+				DepartureTime: formatGTFSTime(dep + col.run.Shift),
+				Timepoint:     st.GetTimepoint() == 1,
+			}
+			if st.GetTimepoint() == 1 {
+				tt.Stops[i].Timepoint = true
+			}
+		}
+	}
+	return tt
+}
+
+// writeTimetableCSV writes one row per stop and one column per trip, with
+// departure times and empty cells for skipped stops.
+func writeTimetableCSV(c *gin.Context, tt Timetable) {
+	c.Header("Content-Type", "text/csv")
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", fmt.Sprintf("timetable_%s_%d_%s.csv", tt.RouteId, tt.DirectionId, tt.Date)))
+	c.Status(http.StatusOK)
+
+	w := csv.NewWriter(c.Writer)
+	header := []string{"stop_id", "stop_name", "timepoint"}
+	headsigns := []string{"", "", ""}
+	for _, t := range tt.Trips {
+		header = append(header, t.TripId)
+		headsigns = append(headsigns, t.TripHeadsign)
+	}
+	w.Write(header)
+	w.Write(headsigns)
+
+	for i, stop := range tt.Stops {
+		record := []string{stop.StopId, stop.StopName, strconv.FormatBool(stop.Timepoint)}
+		for _, cell := range tt.Cells[i] {
+			if cell == nil {
+				record = append(record, "")
+				continue
+			}
+			record = append(record, cell.DepartureTime)
+		}
+		w.Write(record)
+	}
+	w.Flush()
+}
+
+// GET /routes/:id/timetable?direction=0&date=2026-10-19&format=csv
+func HandleRouteTimetable(c *gin.Context) {
+	route, found := findRouteByID(c.Param("id"))
+	if !found {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Route not found"})
+		return
+	}
+
+	direction, err := queryInt(c, "direction", 0, 0, 1)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	day := time.Now()
+	if s := c.Query("date"); s != "" {
+		d, err := parseServiceDate(s)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		day = d
+	}
+
+	tt := buildTimetable(route, int32(direction), serviceDate(serviceDayStart(day)))
+	if c.Query("format") == "csv" {
+		writeTimetableCSV(c, tt)
+		return
+	}
+	c.JSON(http.StatusOK, tt)
+}
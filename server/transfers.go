@@ -0,0 +1,122 @@
+package server
+
+import (
+	"net/http"
+	"slices"
+	"sort"
+	"studious-waffle/server/protodata"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GTFS transfer_type values.
+const (
+	TransferRecommended      = 0
+	TransferTimed            = 1
+	TransferMinimumTime      = 2
+	TransferNotPossible      = 3
+	TransferInSeat           = 4
+	TransferInSeatNotAllowed = 5
+)
+
+var transferTypeNames = map[int32]string{
+	TransferRecommended:      "recommended",
+	TransferTimed:            "timed",
+	TransferMinimumTime:      "minimum_time",
+	TransferNotPossible:      "not_possible",
+	TransferInSeat:           "in_seat",
+	TransferInSeatNotAllowed: "in_seat_not_allowed",
+}
+
+// Transfers (Search by from StopID)
+func findTransfersFromStop(stopId string) []*protodata.TransferProto {
+	data := Transfers
+	n := len(data)
+
+	idx := sort.Search(n, func(i int) bool {
+		return data[i].GetFromStopId() >= stopId
+	})
+
+	end := idx
+	for end < n && data[end].GetFromStopId() == stopId {
+		end++
+	}
+	return data[idx:end]
+}
+
+// transferSpecificity ranks rules the way GTFS resolves overlaps: trip to
+// trip beats trip and route, then a single trip, route to route, a single
+// route, and finally stops alone.
+func transferSpecificity(t *protodata.TransferProto) int {
+	score := 0
+	for _, trip := range []string{t.GetFromTripId(), t.GetToTripId()} {
+		if trip != "" {
+			score += 4
+		}
+	}
+	for _, route := range []string{t.GetFromRouteId(), t.GetToRouteId()} {
+		if route != "" {
+			score++
+		}
+	}
+	return score
+}
+
+// TransferLeg is one side of a transfer: where the rider gets off or on.
+type TransferLeg struct {
+	StopId, RouteId, TripId string
+}
+
+func transferMatches(t *protodata.TransferProto, from, to TransferLeg) bool {
+	field := func(rule, value string) bool { return rule == "" || rule == value }
+	return field(t.GetToStopId(), to.StopId) &&
+		field(t.GetFromRouteId(), from.RouteId) && field(t.GetToRouteId(), to.RouteId) &&
+		field(t.GetFromTripId(), from.TripId) && field(t.GetToTripId(), to.TripId)
+}
+
+// transferRule finds the most specific transfers.txt rule between two legs.
+// In-seat rules may leave the stops out, so rules without a from stop are
+// considered too.
+func transferRule(from, to TransferLeg) (*protodata.TransferProto, bool) {
+	var best *protodata.TransferProto
+	candidates := slices.Concat(findTransfersFromStop(from.StopId), findTransfersFromStop(""))
+	for _, t := range candidates {
+		if transferMatches(t, from, to) && (best == nil || transferSpecificity(t) > transferSpecificity(best)) {
+			best = t
+		}
+	}
+	return best, best != nil
+}
+
+type TransferView struct {
+	Transfer         *protodata.TransferProto `json:"transfer"`
+	TransferTypeName string                   `json:"transfer_type_name"`
+	ToStopName       string                   `json:"to_stop_name,omitempty"`
+}
+
+// GET /stops/:id/transfers
+func HandleStopTransfers(c *gin.Context) {
+	stop, found := findStopById(c.Param("id"))
+	if !found {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Stop not found"})
+		return
+	}
+
+	// a station's transfers are those of its platforms
+	stops := []*protodata.StopProto{stop}
+	if stop.GetLocationType() == LocationStation {
+		stops = append(stops, stationMembers(stop.GetStopId())...)
+	}
+
+	results := make([]TransferView, 0)
+	for _, s := range stops {
+		for _, t := range findTransfersFromStop(s.GetStopId()) {
+			view := TransferView{Transfer: t, TransferTypeName: transferTypeNames[t.GetTransferType()]}
+			if to, found := findStopById(t.GetToStopId()); found {
+				view.ToStopName = to.GetStopName()
+			}
+			results = append(results, view)
+		}
+	}
+	c.JSON(http.StatusOK, results)
+}
@@ -0,0 +1,104 @@
+package server
+
+import (
+	"cmp"
+	"net/http"
+	"slices"
+	"studious-waffle/server/protodata"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	SeverityError   = protodata.NoticeError
+	SeverityWarning = protodata.NoticeWarning
+)
+
+// maxIssueExamples caps the examples kept per issue; the count still covers
+// every occurrence.
+const maxIssueExamples = 5
+
+// ValidationIssue is one kind of problem found in a feed, with how often it
+// occurred and a few occurrences to go and look at.
+type ValidationIssue struct {
+	Code     string   `json:"code"`
+	Severity string   `json:"severity"`
+	Count    int      `json:"count"`
+	Examples []string `json:"examples"`
+}
+
+// validationIssues collects issues by code as a feed is checked.
+type validationIssues struct {
+	issues []ValidationIssue
+	index  map[string]int
+}
+
+func (v *validationIssues) add(code, severity, example string) {
+	if v.index == nil {
+		v.index = make(map[string]int)
+	}
+	i, found := v.index[code]
+	if !found {
+		i = len(v.issues)
+		v.index[code] = i
+		v.issues = append(v.issues, ValidationIssue{Code: code, Severity: severity, Examples: make([]string, 0)})
+	}
+	v.issues[i].Count++
+	if len(v.issues[i].Examples) < maxIssueExamples {
+		v.issues[i].Examples = append(v.issues[i].Examples, example)
+	}
+}
+
+// list returns the issues, errors first, and how many errors and warnings
+// there were in all.
+func (v *validationIssues) list() (issues []ValidationIssue, errors, warnings int) {
+	issues = slices.Clone(v.issues)
+	if issues == nil {
+		issues = make([]ValidationIssue, 0)
+	}
+	slices.SortFunc(issues, func(a, b ValidationIssue) int {
+		if a.Severity != b.Severity {
+			if a.Severity == SeverityError {
+				return -1
+			}
+			return 1
+		}
+		return cmp.Compare(a.Code, b.Code)
+	})
+	for _, issue := range issues {
+		if issue.Severity == SeverityError {
+			errors += issue.Count
+		} else {
+			warnings += issue.Count
+		}
+	}
+	return issues, errors, warnings
+}
+
+// staticValidation is the report on the static feed the served data was
+// generated from, when there is one.
+var staticValidation *protodata.StaticValidationReport
+
+// GET /validation/static?severity=error&file=stop_times.txt&code=
+func HandleStaticValidation(c *gin.Context) {
+	if staticValidation == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "No static validation report, seed the data to produce one"})
+		return
+	}
+	severity, file, code := c.Query("severity"), c.Query("file"), c.Query("code")
+	switch severity {
+	case "", protodata.NoticeError, protodata.NoticeWarning, protodata.NoticeInfo:
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "severity must be error, warning or info"})
+		return
+	}
+
+	report := *staticValidation
+	report.Notices = make([]protodata.ValidationNotice, 0)
+	for _, n := range staticValidation.Notices {
+		if (severity == "" || n.Severity == severity) && (file == "" || n.File == file) && (code == "" || n.Code == code) {
+			report.Notices = append(report.Notices, n)
+		}
+	}
+	c.JSON(http.StatusOK, report)
+}
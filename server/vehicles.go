@@ -0,0 +1,218 @@
+package server
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"studious-waffle/server/protodata"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// VehicleView is a realtime vehicle position joined with the static data
+// riders actually read: the route's name and colour, the trip headsign and
+// the name of the stop the vehicle is at or approaching.
+type VehicleView struct {
+	EntityId       string                          `json:"entity_id"`
+	Vehicle        *protodata.VehiclePositionProto `json:"vehicle"`
+	RouteShortName string                          `json:"route_short_name,omitempty"`
+	RouteColor     string                          `json:"route_color,omitempty"`
+	RouteTextColor string                          `json:"route_text_color,omitempty"`
+	TripHeadsign   string                          `json:"trip_headsign,omitempty"`
+	StopName       string                          `json:"stop_name,omitempty"`
+	Progress       *VehicleProgress                `json:"progress,omitempty"`
+}
+
+func enrichVehicle(e *protodata.VehiclePositionEntityProto) VehicleView {
+	v := e.GetVehicle()
+	view := VehicleView{EntityId: e.GetId(), Vehicle: v}
+
+	routeId := v.GetTrip().GetRouteId()
+	if trip, found := findTripByID(v.GetTrip().GetTripId()); found {
+		view.TripHeadsign = trip.GetTripHeadsign()
+		if routeId == "" {
+			routeId = trip.GetRouteId()
+		}
+	}
+	if route, found := findRouteByID(routeId); found {
+		view.RouteShortName = route.GetRouteShortName()
+		view.RouteColor = route.GetRouteColor()
+		view.RouteTextColor = route.GetRouteTextColor()
+	}
+	if stop, found := findStopById(v.GetStopId()); found {
+		view.StopName = stop.GetStopName()
+	}
+	return view
+}
+
+// VehicleFilter narrows a vehicle listing. Zero values match everything.
+type VehicleFilter struct {
+	RouteId    string
+	TripId     string
+	NetworkId  string
+	BBox       *BBox
+	StaleAfter time.Duration
+}
+
+type BBox struct {
+	MinLon, MinLat, MaxLon, MaxLat float64
+}
+
+func (b *BBox) Contains(lat, lon float64) bool {
+	return lon >= b.MinLon && lon <= b.MaxLon && lat >= b.MinLat && lat <= b.MaxLat
+}
+
+// parseBBox reads "minLon,minLat,maxLon,maxLat".
+func parseBBox(s string) (*BBox, error) {
+	parts := strings.Split(s, ",")
+	if len(parts) != 4 {
+		return nil, fmt.Errorf("bbox must be minLon,minLat,maxLon,maxLat")
+	}
+	var v [4]float64
+	for i, p := range parts {
+		f, err := strconv.ParseFloat(strings.TrimSpace(p), 64)
+		if err != nil {
+			return nil, fmt.Errorf("bbox value %q is not a number", p)
+		}
+		v[i] = f
+	}
+	if v[0] > v[2] || v[1] > v[3] {
+		return nil, fmt.Errorf("bbox minimums must not exceed maximums")
+	}
+	return &BBox{MinLon: v[0], MinLat: v[1], MaxLon: v[2], MaxLat: v[3]}, nil
+}
+
+// parseStaleAfter accepts a Go duration ("90s", "5m") or a plain number of
+// seconds.
+func parseStaleAfter(s string) (time.Duration, error) {
+	if secs, err := strconv.Atoi(s); err == nil && secs >= 0 {
+		return time.Duration(secs) * time.Second, nil
+	}
+	d, err := time.ParseDuration(s)
+	if err != nil || d < 0 {
+		return 0, fmt.Errorf("stale_after must be a duration or a number of seconds")
+	}
+	return d, nil
+}
+
+func vehicleFilterFromQuery(c *gin.Context) (VehicleFilter, error) {
+	f := VehicleFilter{
+		RouteId:   c.Query("route_id"),
+		TripId:    c.Query("trip_id"),
+		NetworkId: c.Query("network_id"),
+	}
+	if s := c.Query("bbox"); s != "" {
+		b, err := parseBBox(s)
+		if err != nil {
+			return f, err
+		}
+		f.BBox = b
+	}
+	if s := c.Query("stale_after"); s != "" {
+		d, err := parseStaleAfter(s)
+		if err != nil {
+			return f, err
+		}
+		f.StaleAfter = d
+	}
+	return f, nil
+}
+
+// Match reports whether an enriched vehicle passes the filter. Vehicles
+// whose last report is older than StaleAfter are dropped.
+func (f VehicleFilter) Match(v VehicleView, now time.Time) bool {
+	vp := v.Vehicle
+	routeId := vp.GetTrip().GetRouteId()
+	if routeId == "" {
+		if trip, found := findTripByID(vp.GetTrip().GetTripId()); found {
+			routeId = trip.GetRouteId()
+		}
+	}
+	if f.RouteId != "" && routeId != f.RouteId {
+		return false
+	}
+	if f.NetworkId != "" && !routeInNetwork(routeId, f.NetworkId) {
+		return false
+	}
+	if f.TripId != "" && vp.GetTrip().GetTripId() != f.TripId {
+		return false
+	}
+	if f.BBox != nil && !f.BBox.Contains(vp.GetPosition().GetLatitude(), vp.GetPosition().GetLongitude()) {
+		return false
+	}
+	if f.StaleAfter > 0 && now.Sub(time.Unix(vp.GetTimestamp(), 0)) > f.StaleAfter {
+		return false
+	}
+	return true
+}
+
+// GET /vehicles?route_id=&trip_id=&network_id=&bbox=&stale_after=&progress=true
+func HandleVehicles(c *gin.Context) {
+	filter, err := vehicleFilterFromQuery(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	withProgress := c.Query("progress") == "true"
+	threshold, err := offRouteFromQuery(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	snap, err := currentRealtime()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch vehicle positions"})
+		return
+	}
+	positions := snap.Vehicles
+
+	now := time.Now()
+	results := make([]VehicleView, 0, len(positions))
+	for _, p := range positions {
+		view := enrichVehicle(p)
+		if !filter.Match(view, now) {
+			continue
+		}
+		if withProgress {
+			// vehicles without a usable trip or shape are listed without progress
+			view.Progress, _ = SnapVehicle(p.GetVehicle(), threshold)
+		}
+		results = append(results, view)
+	}
+	c.JSON(http.StatusOK, results)
+}
+
+// GET /vehicles/:id
+func HandleVehicleById(c *gin.Context) {
+	snap, err := currentRealtime()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch vehicle positions"})
+		return
+	}
+	positions := snap.Vehicles
+
+	if p, found := findVehicle(positions, c.Param("id")); found {
+		c.JSON(http.StatusOK, enrichVehicle(p))
+	} else {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Vehicle not found"})
+	}
+}
+
+// findVehicle matches the vehicle descriptor ID first and falls back to the
+// feed entity ID.
+func findVehicle(positions []*protodata.VehiclePositionEntityProto, id string) (*protodata.VehiclePositionEntityProto, bool) {
+	for _, p := range positions {
+		if p.GetVehicle().GetVehicle().GetId() == id {
+			return p, true
+		}
+	}
+	for _, p := range positions {
+		if p.GetId() == id {
+			return p, true
+		}
+	}
+	return nil, false
+}
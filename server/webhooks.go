@@ -0,0 +1,218 @@
+package server
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// Event is the envelope delivered to webhook subscribers.
+type Event struct {
+	Id        string `json:"id"`
+	Type      string `json:"type"`
+	CreatedAt int64  `json:"created_at"`
+	Data      any    `json:"data"`
+}
+
+func NewEvent(eventType string, data any) Event {
+	b := make([]byte, 16)
+	rand.Read(b)
+	return Event{
+		Id:        hex.EncodeToString(b),
+		Type:      eventType,
+		CreatedAt: time.Now().Unix(),
+		Data:      data,
+	}
+}
+
+// eventNotifier delivers the events components publish; nil when no webhook
+// receivers are configured.
+var eventNotifier *WebhookNotifier
+
+// publishEvents hands events to the webhook receivers, if there are any.
+func publishEvents(events []Event) {
+	if eventNotifier != nil && len(events) > 0 {
+		eventNotifier.Notify(events)
+	}
+}
+
+// WebhookNotifier POSTs events to a fixed set of URLs. Each request carries
+// an HMAC-SHA256 signature of "<timestamp>.<body>" so receivers can verify
+// the sender and reject replays. Failed deliveries are retried with
+// exponential backoff and, once attempts run out, appended to a dead-letter
+// log as one JSON object per line.
+type WebhookNotifier struct {
+	URLs           []string
+	Secret         []byte
+	Client         *http.Client
+	MaxAttempts    int
+	Backoff        time.Duration
+	DeadLetterPath string
+
+	deadMu sync.Mutex
+	queues map[string]chan Event
+	once   sync.Once
+}
+
+func NewWebhookNotifier(urls []string, secret, deadLetterPath string) *WebhookNotifier {
+	return &WebhookNotifier{
+		URLs:           urls,
+		Secret:         []byte(secret),
+		Client:         &http.Client{Timeout: 10 * time.Second},
+		MaxAttempts:    5,
+		Backoff:        time.Second,
+		DeadLetterPath: deadLetterPath,
+	}
+}
+
+// Notify queues events for every subscriber. Each URL has its own worker so a
+// slow receiver does not hold up the others, and events reach a receiver in
+// the order they were emitted.
+func (n *WebhookNotifier) Notify(events []Event) {
+	n.once.Do(func() {
+		n.queues = make(map[string]chan Event, len(n.URLs))
+		for _, url := range n.URLs {
+			q := make(chan Event, 256)
+			n.queues[url] = q
+			go n.worker(url, q)
+		}
+	})
+
+	for _, ev := range events {
+		for url, q := range n.queues {
+			select {
+			case q <- ev:
+			default:
+				n.deadLetter(url, ev, 0, fmt.Errorf("delivery queue full"))
+			}
+		}
+	}
+}
+
+func (n *WebhookNotifier) worker(url string, queue <-chan Event) {
+	for ev := range queue {
+		n.Deliver(url, ev)
+	}
+}
+
+// Deliver sends a single event to url, retrying until it succeeds or the
+// attempts are used up. It returns the last error, if any.
+func (n *WebhookNotifier) Deliver(url string, ev Event) error {
+	body, err := json.Marshal(ev)
+	if err != nil {
+		n.deadLetter(url, ev, 0, err)
+		return err
+	}
+
+	maxAttempts := max(n.MaxAttempts, 1)
+	attempts := 0
+	var lastErr error
+	for attempts < maxAttempts {
+		attempts++
+		retry, err := n.post(url, ev, body)
+		if err == nil {
+			return nil
+		}
+		lastErr = err
+		if !retry {
+			break
+		}
+		if attempts < maxAttempts {
+			time.Sleep(n.Backoff << (attempts - 1))
+		}
+	}
+
+	log.Printf("Webhook delivery to %s failed: %v\n", url, lastErr)
+	n.deadLetter(url, ev, attempts, lastErr)
+	return lastErr
+}
+
+// post makes one delivery attempt. The bool reports whether the failure is
+// worth retrying: network errors, 429 and 5xx are, other 4xx are not.
+func (n *WebhookNotifier) post(url string, ev Event, body []byte) (bool, error) {
+	req, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return false, err
+	}
+
+	ts := strconv.FormatInt(time.Now().Unix(), 10)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Webhook-Id", ev.Id)
+	req.Header.Set("X-Webhook-Event", ev.Type)
+	req.Header.Set("X-Webhook-Timestamp", ts)
+	req.Header.Set("X-Webhook-Signature", "sha256="+SignWebhook(n.Secret, ts, body))
+
+	client := n.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return true, err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, resp.Body)
+
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return false, nil
+	}
+	retry := resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500
+	return retry, fmt.Errorf("unexpected status %d", resp.StatusCode)
+}
+
+// SignWebhook returns the hex HMAC-SHA256 of "<timestamp>.<body>".
+func SignWebhook(secret []byte, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+type deadLetterEntry struct {
+	URL      string `json:"url"`
+	Event    Event  `json:"event"`
+	Attempts int    `json:"attempts"`
+	Error    string `json:"error"`
+	FailedAt int64  `json:"failed_at"`
+}
+
+func (n *WebhookNotifier) deadLetter(url string, ev Event, attempts int, cause error) {
+	if n.DeadLetterPath == "" {
+		return
+	}
+
+	line, err := json.Marshal(deadLetterEntry{
+		URL:      url,
+		Event:    ev,
+		Attempts: attempts,
+		Error:    cause.Error(),
+		FailedAt: time.Now().Unix(),
+	})
+	if err != nil {
+		log.Println("Error encoding dead letter:", err)
+		return
+	}
+
+	n.deadMu.Lock()
+	defer n.deadMu.Unlock()
+
+	f, err := os.OpenFile(n.DeadLetterPath, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		log.Println("Error opening dead letter log:", err)
+		return
+	}
+	defer f.Close()
+	f.Write(append(line, '\n'))
+}
@@ -0,0 +1,133 @@
+package server
+
+import (
+	"bufio"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func testNotifier(t *testing.T, url string) *WebhookNotifier {
+	t.Helper()
+	n := NewWebhookNotifier([]string{url}, "test-secret", filepath.Join(t.TempDir(), "dead_letter.log"))
+	n.MaxAttempts = 3
+	n.Backoff = time.Millisecond
+	return n
+}
+
+func readDeadLetters(t *testing.T, path string) []deadLetterEntry {
+	t.Helper()
+	f, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	var entries []deadLetterEntry
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		var e deadLetterEntry
+		if err := json.Unmarshal(scanner.Bytes(), &e); err != nil {
+			t.Fatalf("dead letter line %q is not JSON: %v", scanner.Text(), err)
+		}
+		entries = append(entries, e)
+	}
+	return entries
+}
+
+func TestWebhookSignature(t *testing.T) {
+	var header http.Header
+	var body []byte
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		header = r.Header.Clone()
+		body, _ = io.ReadAll(r.Body)
+	}))
+	defer srv.Close()
+
+	n := testNotifier(t, srv.URL)
+	ev := NewEvent(AlertCreated, AlertChange{AlertId: "a1"})
+	if err := n.Deliver(srv.URL, ev); err != nil {
+		t.Fatalf("Deliver: %v", err)
+	}
+
+	ts := header.Get("X-Webhook-Timestamp")
+	if ts == "" {
+		t.Fatal("no X-Webhook-Timestamp header")
+	}
+	mac := hmac.New(sha256.New, []byte("test-secret"))
+	mac.Write([]byte(ts + "." + string(body)))
+	want := "sha256=" + hex.EncodeToString(mac.Sum(nil))
+	if got := header.Get("X-Webhook-Signature"); got != want {
+		t.Errorf("X-Webhook-Signature = %q, want %q", got, want)
+	}
+	if got := header.Get("X-Webhook-Id"); got != ev.Id {
+		t.Errorf("X-Webhook-Id = %q, want %q", got, ev.Id)
+	}
+	if got := header.Get("X-Webhook-Event"); got != AlertCreated {
+		t.Errorf("X-Webhook-Event = %q, want %q", got, AlertCreated)
+	}
+}
+
+func TestWebhookDeliverRetries(t *testing.T) {
+	tests := []struct {
+		name         string
+		status       int
+		wantAttempts int32
+		wantDead     bool
+	}{
+		{"success", http.StatusNoContent, 1, false},
+		{"server error is retried", http.StatusServiceUnavailable, 3, true},
+		{"too many requests is retried", http.StatusTooManyRequests, 3, true},
+		{"client error is not retried", http.StatusBadRequest, 1, true},
+		{"not found is not retried", http.StatusNotFound, 1, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var attempts atomic.Int32
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				attempts.Add(1)
+				w.WriteHeader(tt.status)
+			}))
+			defer srv.Close()
+
+			n := testNotifier(t, srv.URL)
+			ev := NewEvent(AlertResolved, AlertChange{AlertId: "a1"})
+			err := n.Deliver(srv.URL, ev)
+			if (err != nil) != tt.wantDead {
+				t.Errorf("Deliver error = %v, want error %v", err, tt.wantDead)
+			}
+			if got := attempts.Load(); got != tt.wantAttempts {
+				t.Errorf("receiver saw %d attempt(s), want %d", got, tt.wantAttempts)
+			}
+
+			dead := readDeadLetters(t, n.DeadLetterPath)
+			if !tt.wantDead {
+				if len(dead) != 0 {
+					t.Errorf("dead letters = %+v, want none", dead)
+				}
+				return
+			}
+			if len(dead) != 1 {
+				t.Fatalf("got %d dead letter(s), want 1", len(dead))
+			}
+			if dead[0].URL != srv.URL || dead[0].Event.Id != ev.Id || dead[0].Event.Type != AlertResolved {
+				t.Errorf("dead letter = %+v, want event %s to %s", dead[0], ev.Id, srv.URL)
+			}
+			if dead[0].Attempts != int(tt.wantAttempts) || dead[0].Error == "" {
+				t.Errorf("dead letter attempts = %d, error = %q", dead[0].Attempts, dead[0].Error)
+			}
+		})
+	}
+}